@@ -0,0 +1,302 @@
+package flespi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is matched by the error returned while the circuit breaker rejects requests
+var ErrCircuitOpen = errors.New("flespi circuit breaker is open")
+
+// CircuitOpenError is returned without contacting flespi while the circuit breaker is open
+type CircuitOpenError struct {
+	// RetryAt is when the breaker lets a probe request through
+	RetryAt time.Time
+}
+
+// Error implements the error interface
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v until %s", ErrCircuitOpen, e.RetryAt.Format(time.RFC3339))
+}
+
+// Is lets errors.Is(err, ErrCircuitOpen) match a CircuitOpenError
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// IsCircuitOpenError checks if a request was rejected by the circuit breaker
+func IsCircuitOpenError(err error) bool {
+	return errors.Is(err, ErrCircuitOpen)
+}
+
+// CircuitState is the state of the circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through and counts failures
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects every request with a CircuitOpenError
+	CircuitOpen
+	// CircuitHalfOpen lets a few probe requests through to test whether flespi recovered
+	CircuitHalfOpen
+)
+
+// String returns a string representation of a circuit state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// CircuitBreakerConfig defines when the circuit breaker opens and how it recovers
+type CircuitBreakerConfig struct {
+	// FailureRatio opens the circuit once this share of attempts in a window failed (default: 0.5)
+	FailureRatio float64
+
+	// MinRequests is the number of attempts a window needs before FailureRatio applies (default: 10)
+	MinRequests int
+
+	// Window is the period over which attempts are counted (default: 30 seconds)
+	Window time.Duration
+
+	// OpenTimeout is how long the circuit stays open before probing (default: 30 seconds)
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests is the number of probes that must succeed to close the circuit (default: 1)
+	HalfOpenRequests int
+
+	// IsFailure decides which errors count against flespi. Default: 5xx responses and
+	// transport errors; 4xx responses, rate limiting and cancellations do not count.
+	// Attempts ended by the caller's context are never passed to it.
+	IsFailure func(err error) bool
+}
+
+// DefaultCircuitBreakerConfig returns a circuit breaker configuration with sensible defaults
+func DefaultCircuitBreakerConfig() *CircuitBreakerConfig {
+	return &CircuitBreakerConfig{
+		FailureRatio:     0.5,
+		MinRequests:      10,
+		Window:           30 * time.Second,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenRequests: 1,
+	}
+}
+
+// WithCircuitBreaker makes the client fail fast with a CircuitOpenError while
+// flespi is failing; nil uses DefaultCircuitBreakerConfig. The breaker is shared
+// with clients created by ForAccount, and state transitions are logged as warnings.
+func WithCircuitBreaker(config *CircuitBreakerConfig) ClientOption {
+	return func(c *Client) {
+		if config == nil {
+			config = DefaultCircuitBreakerConfig()
+		}
+		c.breaker = newCircuitBreaker(*config, func(from, to CircuitState) {
+			c.logWarn("Circuit breaker %s -> %s", from, to)
+		})
+	}
+}
+
+// CircuitState returns the state of the circuit breaker; CircuitClosed if none is configured
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.currentState(time.Now())
+}
+
+// defaultIsFailure counts server-side and transport failures
+func defaultIsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return errors.Is(apiErr, ErrServer)
+	}
+
+	return true
+}
+
+type circuitBreaker struct {
+	mu       sync.Mutex
+	config   CircuitBreakerConfig
+	onChange func(from, to CircuitState)
+
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int
+	successes   int
+
+	// changes are the transitions to report once mu is released
+	changes []stateChange
+}
+
+type stateChange struct {
+	from, to CircuitState
+}
+
+func newCircuitBreaker(config CircuitBreakerConfig, onChange func(from, to CircuitState)) *circuitBreaker {
+	defaults := DefaultCircuitBreakerConfig()
+	if config.FailureRatio <= 0 {
+		config.FailureRatio = defaults.FailureRatio
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = defaults.MinRequests
+	}
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaults.OpenTimeout
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = defaults.HalfOpenRequests
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = defaultIsFailure
+	}
+
+	return &circuitBreaker{config: config, onChange: onChange}
+}
+
+// allow reserves an attempt or returns a CircuitOpenError
+func (b *circuitBreaker) allow(now time.Time) error {
+	b.mu.Lock()
+	defer b.unlock()
+
+	switch b.refresh(now) {
+	case CircuitOpen:
+		return &CircuitOpenError{RetryAt: b.openedAt.Add(b.config.OpenTimeout)}
+	case CircuitHalfOpen:
+		if b.probes >= b.config.HalfOpenRequests {
+			return &CircuitOpenError{RetryAt: now.Add(b.config.OpenTimeout)}
+		}
+		b.probes++
+	}
+
+	return nil
+}
+
+// record accounts the outcome of an attempt admitted by allow
+func (b *circuitBreaker) record(err error, now time.Time) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	// a cancelled attempt says nothing about flespi
+	if errors.Is(err, context.Canceled) {
+		b.releaseProbe()
+		return
+	}
+
+	failed := b.config.IsFailure(err)
+
+	switch b.refresh(now) {
+	case CircuitClosed:
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.config.MinRequests && float64(b.failures)/float64(b.requests) >= b.config.FailureRatio {
+			b.transition(CircuitOpen, now)
+		}
+	case CircuitHalfOpen:
+		if failed {
+			b.transition(CircuitOpen, now)
+			return
+		}
+		b.successes++
+		if b.successes >= b.config.HalfOpenRequests {
+			b.transition(CircuitClosed, now)
+		}
+	}
+}
+
+// release gives back an attempt admitted by allow without accounting it, for
+// attempts that ended on the caller's side, e.g. with its context done
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.unlock()
+	b.releaseProbe()
+}
+
+// releaseProbe frees a half-open probe slot; the caller holds mu
+func (b *circuitBreaker) releaseProbe() {
+	if b.state == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+func (b *circuitBreaker) currentState(now time.Time) CircuitState {
+	b.mu.Lock()
+	defer b.unlock()
+	return b.refresh(now)
+}
+
+// unlock releases mu and only then reports the transitions made while it was
+// held, so a slow onChange does not stall requests and one calling back into
+// the client does not deadlock
+func (b *circuitBreaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+
+	if b.onChange == nil {
+		return
+	}
+	for _, change := range changes {
+		b.onChange(change.from, change.to)
+	}
+}
+
+// refresh applies time-based transitions; the caller holds mu
+func (b *circuitBreaker) refresh(now time.Time) CircuitState {
+	switch b.state {
+	case CircuitClosed:
+		if now.Sub(b.windowStart) >= b.config.Window {
+			b.windowStart = now
+			b.requests = 0
+			b.failures = 0
+		}
+	case CircuitOpen:
+		if now.Sub(b.openedAt) >= b.config.OpenTimeout {
+			b.transition(CircuitHalfOpen, now)
+		}
+	}
+
+	return b.state
+}
+
+// transition switches state and resets the counters; the caller holds mu and
+// reports the change with unlock
+func (b *circuitBreaker) transition(to CircuitState, now time.Time) {
+	from := b.state
+	b.state = to
+
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+	b.probes = 0
+	b.successes = 0
+
+	if to == CircuitOpen {
+		b.openedAt = now
+	}
+
+	if from != to {
+		b.changes = append(b.changes, stateChange{from: from, to: to})
+	}
+}
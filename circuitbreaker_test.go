@@ -0,0 +1,183 @@
+package flespi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	var transitions []string
+	breaker := newCircuitBreaker(CircuitBreakerConfig{
+		FailureRatio:     0.5,
+		MinRequests:      4,
+		Window:           time.Minute,
+		OpenTimeout:      10 * time.Second,
+		HalfOpenRequests: 1,
+	}, func(from, to CircuitState) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+
+	now := time.Now()
+	serverErr := &APIError{StatusCode: 502}
+
+	// 1 failure out of 3 keeps it closed, the 4th attempt reaches the ratio
+	for _, err := range []error{nil, serverErr, nil, serverErr} {
+		if allowErr := breaker.allow(now); allowErr != nil {
+			t.Fatalf("Expected closed breaker to allow requests, got %v", allowErr)
+		}
+		breaker.record(err, now)
+	}
+
+	if state := breaker.currentState(now); state != CircuitOpen {
+		t.Fatalf("Expected breaker to be open, got %s", state)
+	}
+
+	err := breaker.allow(now.Add(time.Second))
+	if !IsCircuitOpenError(err) {
+		t.Fatalf("Expected a circuit open error, got %v", err)
+	}
+	if openErr := err.(*CircuitOpenError); !openErr.RetryAt.Equal(now.Add(10 * time.Second)) {
+		t.Errorf("Expected RetryAt %v, got %v", now.Add(10*time.Second), openErr.RetryAt)
+	}
+
+	// after OpenTimeout a single probe goes through
+	later := now.Add(11 * time.Second)
+	if err := breaker.allow(later); err != nil {
+		t.Fatalf("Expected a probe to be allowed, got %v", err)
+	}
+	if err := breaker.allow(later); !IsCircuitOpenError(err) {
+		t.Errorf("Expected a second concurrent probe to be rejected, got %v", err)
+	}
+
+	breaker.record(nil, later)
+
+	if state := breaker.currentState(later); state != CircuitClosed {
+		t.Errorf("Expected breaker to close after a successful probe, got %s", state)
+	}
+
+	expected := "closed->open,open->half-open,half-open->closed"
+	if got := strings.Join(transitions, ","); got != expected {
+		t.Errorf("Expected transitions %q, got %q", expected, got)
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	breaker := newCircuitBreaker(CircuitBreakerConfig{MinRequests: 2}, nil)
+	now := time.Now()
+
+	for i := 0; i < 5; i++ {
+		breaker.allow(now)
+		breaker.record(&APIError{StatusCode: 404}, now)
+		breaker.allow(now)
+		breaker.record(&APIError{StatusCode: 429}, now)
+	}
+
+	if state := breaker.currentState(now); state != CircuitClosed {
+		t.Errorf("Expected 4xx responses not to open the breaker, got %s", state)
+	}
+}
+
+func TestClient_WithCircuitBreaker(t *testing.T) {
+	attempts := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := &StdLogger{logger: log.New(&buf, "", 0), level: LogLevelWarn}
+
+	client, _ := NewClient(server.URL, "test-token",
+		WithLogger(logger),
+		WithCircuitBreaker(&CircuitBreakerConfig{MinRequests: 2, OpenTimeout: time.Minute}),
+	)
+
+	client.RequestAPI("GET", "gw/devices/all", nil, nil)
+	client.RequestAPI("GET", "gw/devices/all", nil, nil)
+
+	// scoped clients share the breaker
+	err := client.ForAccount(7).RequestAPI("GET", "gw/devices/all", nil, nil)
+
+	if !IsCircuitOpenError(err) {
+		t.Errorf("Expected a circuit open error, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected the open breaker to stop requests, got %d attempts", attempts)
+	}
+	if client.CircuitState() != CircuitOpen {
+		t.Errorf("Expected the breaker to be open, got %s", client.CircuitState())
+	}
+	if !strings.Contains(buf.String(), "Circuit breaker closed -> open") {
+		t.Errorf("Expected the transition to be logged, got %q", buf.String())
+	}
+}
+
+func TestCircuitBreaker_OnChangeCallsBack(t *testing.T) {
+	var breaker *circuitBreaker
+	var states []CircuitState
+	breaker = newCircuitBreaker(CircuitBreakerConfig{MinRequests: 1}, func(from, to CircuitState) {
+		// e.g. a logger reading the client's CircuitState
+		states = append(states, breaker.currentState(time.Now()))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		breaker.allow(time.Now())
+		breaker.record(&APIError{StatusCode: 502}, time.Now())
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected onChange to run after the breaker is unlocked")
+	}
+
+	if len(states) != 1 || states[0] != CircuitOpen {
+		t.Errorf("Expected onChange to see the open breaker, got %v", states)
+	}
+}
+
+func TestClient_CircuitBreakerIgnoresCallerDeadline(t *testing.T) {
+	attempts := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.Write([]byte(`{"result": []}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-token",
+		WithRetryConfig(nil),
+		WithCircuitBreaker(&CircuitBreakerConfig{MinRequests: 2, OpenTimeout: time.Minute}),
+	)
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		err := client.RequestAPIWithContext(ctx, "GET", "gw/devices/all", nil, nil)
+		cancel()
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the caller's deadline, got %v", err)
+		}
+	}
+
+	if client.CircuitState() != CircuitClosed {
+		t.Errorf("Expected caller deadlines not to open the breaker, got %s", client.CircuitState())
+	}
+	if atomic.LoadInt32(&attempts) != 3 {
+		t.Errorf("Expected every request to reach the server, got %d attempts", attempts)
+	}
+}
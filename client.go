@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/mixser/flespi-client/internal/flespiapi"
@@ -47,11 +48,17 @@ var _ APIRequester = (*Client)(nil)
 
 // Client represents a Flespi API client
 type Client struct {
-	Host        string
-	Token       string
+	Host string
+
+	// Token is the token passed to NewClient. It is read once, when the client
+	// is created: assigning a new value later has no effect. Rotate the token with
+	// SetToken or a provider set with WithCredentials instead.
+	Token string
+
 	HTTPClient  *http.Client
 	RetryConfig *RetryConfig
 	Logger      Logger
+	RateLimiter RateLimiter
 
 	// Gateway sub-clients
 	Devices     *flespi_device.DeviceClient
@@ -68,6 +75,27 @@ type Client struct {
 
 	// Storage sub-clients
 	CDNs *flespi_cdn.CDNClient
+
+	// accountId is the subaccount every request is scoped to, see ForAccount
+	accountId int64
+
+	// observers receive an event per HTTP attempt, see WithObserver
+	observers []Observer
+
+	// middlewares wrap every HTTP round trip, see WithMiddleware
+	middlewares []Middleware
+
+	// breaker fails requests fast during outages, see WithCircuitBreaker
+	breaker *circuitBreaker
+
+	// region is the deployment selected with WithRegion
+	region Region
+
+	// rateLimit tracks the budget reported by flespi, see RateLimit
+	rateLimit *rateLimitTracker
+
+	// credentials supplies the token of every attempt, see WithCredentials and SetToken
+	credentials *credentialsHolder
 }
 
 // ClientOption is a function that configures a Client
@@ -89,12 +117,15 @@ func WithTimeout(timeout time.Duration) ClientOption {
 
 // NewClient creates a new Flespi API client with the specified host and token.
 //
-// The host parameter should be the base URL of the Flespi API (e.g., "https://flespi.io").
+// The host parameter should be the base URL of the Flespi API (e.g., "https://flespi.io");
+// it is validated and may carry a path prefix. It can be left empty when WithRegion is used.
 // The token parameter should be a valid Flespi authentication token.
 //
 // Optional configuration can be provided using ClientOption functions:
 //   - WithTimeout(duration): Set a custom HTTP client timeout
 //   - WithHTTPClient(client): Use a custom HTTP client
+//   - WithRegion(region) / WithBaseURL(url): Choose the flespi deployment
+//   - WithCredentials(provider): Read the token from a file, environment variable or callback
 //
 // Example:
 //
@@ -106,12 +137,48 @@ func NewClient(host string, token string, options ...ClientOption) (*Client, err
 		Host:       host,
 		Token:      token,
 		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		rateLimit:  newRateLimitTracker(),
 	}
+	c.credentials = &credentialsHolder{provider: NewStaticCredentials(token)}
 
 	for _, opt := range options {
 		opt(c)
 	}
 
+	host, err := normalizeHost(c.Host)
+	if err != nil {
+		return nil, err
+	}
+	c.Host = host
+
+	c.initSubClients()
+
+	return c, nil
+}
+
+// ForAccount returns a client that runs every request in the context of the
+// subaccount accountId: all calls, including List, Get and Delete, carry the
+// x-flespi-cid header. The returned client shares the HTTP client, retry
+// configuration, logger, credentials, rate-limit budget and circuit breaker with c; c itself is left unscoped.
+//
+// Example:
+//
+//	customer := client.ForAccount(12345)
+//	devices, err := customer.Devices.List()
+func (c *Client) ForAccount(accountId int64) *Client {
+	scoped := *c
+	scoped.accountId = accountId
+	scoped.initSubClients()
+
+	return &scoped
+}
+
+// AccountId returns the subaccount the client is scoped to, or 0 for the token's own account.
+func (c *Client) AccountId() int64 {
+	return c.accountId
+}
+
+func (c *Client) initSubClients() {
 	c.Devices = flespi_device.NewDeviceClient(c)
 	c.Streams = flespi_stream.NewStreamClient(c)
 	c.Channels = flespi_channel.NewChannelClient(c)
@@ -122,84 +189,211 @@ func NewClient(host string, token string, options ...ClientOption) (*Client, err
 	c.Subaccounts = flespi_subaccount.NewSubaccountClient(c)
 	c.Limits = flespi_limit.NewLimitClient(c)
 	c.CDNs = flespi_cdn.NewCDNClient(c)
-
-	return c, nil
 }
 
-func (c *Client) doRequest(req *http.Request, method, endpoint string) ([]byte, error) {
-	req.Header.Set("Authorization", fmt.Sprintf("FlespiToken %s", c.Token))
-	req.Header.Set("Content-Type", "application/json")
-
-	res, err := c.HTTPClient.Do(req) //nolint:gosec // G704: URL is constructed from c.Host, set at client init — not user input
+// doRequest performs a single attempt and returns the body of a 2xx response
+// together with the status code
+func (c *Client) doRequest(req *http.Request, method, endpoint string, attempt int) ([]byte, int, error) {
+	res, finish, statusCode, err := c.sendAttempt(req, method, endpoint, attempt)
 	if err != nil {
-		return nil, err
+		return nil, statusCode, err
 	}
 	defer res.Body.Close()
 
 	body, err := io.ReadAll(res.Body)
+	finish(int64(len(body)), err)
+
 	if err != nil {
-		return nil, err
+		return nil, statusCode, err
+	}
+
+	return body, statusCode, nil
+}
+
+// sendAttempt runs the pre-flight checks, sends req through the middleware chain
+// and turns non-2xx responses into an *APIError. On success the caller owns the
+// response body and must report how it went with finish, which notifies the
+// observers and the circuit breaker.
+func (c *Client) sendAttempt(req *http.Request, method, endpoint string, attempt int) (*http.Response, func(responseBytes int64, err error), int, error) {
+	token, err := c.token(req.Context())
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	if c.breaker != nil {
+		if err := c.breaker.allow(time.Now()); err != nil {
+			return nil, nil, 0, err
+		}
+	}
+
+	if err := c.waitForBudget(req.Context()); err != nil {
+		if c.breaker != nil {
+			c.breaker.release()
+		}
+		return nil, nil, 0, err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("FlespiToken %s", token))
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	c.logHeaders(method, endpoint, attempt, req.Header)
+
+	event := RequestEvent{
+		Method:       method,
+		Endpoint:     EndpointTemplate(endpoint),
+		RequestBytes: req.ContentLength,
+		Attempt:      attempt,
+		AccountId:    c.accountId,
+	}
+
+	start := time.Now()
+	finish := func(responseBytes int64, err error) {
+		event.Duration = time.Since(start)
+		event.ResponseBytes = responseBytes
+		event.Err = err
+		event.ErrorClass = classifyError(err)
+
+		c.observe(req.Context(), event)
+
+		// an attempt the caller cancelled or let time out says nothing about flespi
+		if c.breaker != nil {
+			if req.Context().Err() != nil {
+				c.breaker.release()
+			} else {
+				c.breaker.record(err, time.Now())
+			}
+		}
+	}
+
+	res, err := c.handler()(req)
+	if err == nil && res == nil {
+		err = errNoResponse
+	}
+	if res != nil && res.Body == nil {
+		// middlewares may build responses without a body, e.g. a synthetic 204
+		res.Body = http.NoBody
+	}
+	if err != nil {
+		finish(0, err)
+		return nil, nil, 0, err
+	}
+
+	event.StatusCode = res.StatusCode
+
+	if c.rateLimit != nil {
+		c.rateLimit.update(res.Header, res.StatusCode, time.Now())
 	}
 
 	// Check for successful status codes (2xx)
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return nil, parseAPIError(res.StatusCode, method, endpoint, body)
-	}
+		defer res.Body.Close()
 
-	return body, nil
-}
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			finish(int64(len(body)), err)
+			return nil, nil, res.StatusCode, err
+		}
 
-func (c *Client) RequestAPI(method, endpoint string, payload, response interface{}) error {
-	return c.RequestAPIWithContextAndHeaders(context.Background(), method, endpoint, nil, payload, response)
-}
+		apiErr := parseAPIError(res.StatusCode, method, endpoint, body)
+		if retryAfter, ok := parseRetryAfter(res.Header, time.Now()); ok {
+			apiErr.RetryAfter = retryAfter
+		}
 
-func (c *Client) RequestAPIWithContext(ctx context.Context, method, endpoint string, payload, response interface{}) error {
-	return c.RequestAPIWithContextAndHeaders(ctx, method, endpoint, nil, payload, response)
-}
+		finish(int64(len(body)), apiErr)
+		return nil, nil, res.StatusCode, apiErr
+	}
 
-func (c *Client) RequestAPIWithHeaders(method, endpoint string, headers map[string]string, payload, response interface{}) error {
-	return c.RequestAPIWithContextAndHeaders(context.Background(), method, endpoint, headers, payload, response)
+	return res, finish, res.StatusCode, nil
 }
 
-func (c *Client) RequestAPIWithContextAndHeaders(ctx context.Context, method, endpoint string, headers map[string]string, payload, response interface{}) error {
-	c.logRequest(method, endpoint, payload)
-
+// newRequest marshals payload and builds the request with the scoping and per-call headers
+func (c *Client) newRequest(ctx context.Context, method, endpoint string, headers map[string]string, payload interface{}) (*http.Request, error) {
 	var body io.Reader
-
-	if payload != nil {
-		jsonData, err := json.Marshal(payload)
+	var jsonData []byte
+	var contentType string
+
+	if raw, ok := payload.(flespiapi.RawBody); ok {
+		body = bytes.NewReader(raw.Data)
+		contentType = raw.ContentType
+	} else if payload != nil {
+		var err error
+		jsonData, err = json.Marshal(payload)
 		if err != nil {
 			c.logError("Failed to marshal payload: %v", err)
-			return err
+			return nil, err
 		}
 		body = bytes.NewBuffer(jsonData)
 	}
 
+	c.logRequest(method, endpoint, jsonData)
+
 	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s", c.Host, endpoint), body)
 	if err != nil {
 		c.logError("Failed to create request: %v", err)
-		return err
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if c.accountId != 0 {
+		req.Header.Set("x-flespi-cid", strconv.FormatInt(c.accountId, 10))
 	}
 
+	// explicit headers win, so an object's own AccountId overrides the scoped one
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
 
-	var resp []byte
-	if c.RetryConfig != nil && c.RetryConfig.MaxRetries > 0 {
-		resp, err = c.doRequestWithRetry(ctx, req, method, endpoint)
-	} else {
-		resp, err = c.doRequest(req, method, endpoint)
+	return req, nil
+}
+
+func (c *Client) RequestAPI(method, endpoint string, payload, response interface{}) error {
+	return c.RequestAPIWithContextAndHeaders(context.Background(), method, endpoint, nil, payload, response)
+}
+
+func (c *Client) RequestAPIWithContext(ctx context.Context, method, endpoint string, payload, response interface{}) error {
+	return c.RequestAPIWithContextAndHeaders(ctx, method, endpoint, nil, payload, response)
+}
+
+func (c *Client) RequestAPIWithHeaders(method, endpoint string, headers map[string]string, payload, response interface{}) error {
+	return c.RequestAPIWithContextAndHeaders(context.Background(), method, endpoint, headers, payload, response)
+}
+
+func (c *Client) RequestAPIWithContextAndHeaders(ctx context.Context, method, endpoint string, headers map[string]string, payload, response interface{}) error {
+	req, err := c.newRequest(ctx, method, endpoint, headers, payload)
+	if err != nil {
+		return err
 	}
 
+	var resp []byte
+	statusCode, err := c.doWithRetry(ctx, req, method, endpoint, func(req *http.Request, attempt int) (int, error) {
+		body, statusCode, err := c.doRequest(req, method, endpoint, attempt)
+		resp = body
+		return statusCode, err
+	})
+
 	if err != nil {
-		c.logResponse(method, endpoint, 0, err)
+		c.logResponse(method, endpoint, statusCode, err)
 		return err
 	}
 
-	c.logResponse(method, endpoint, 200, nil)
+	c.logResponse(method, endpoint, statusCode, nil)
+
+	// Batch requests may succeed with per-item errors next to the result. Callers that
+	// decode the body receive them in their response type; otherwise report them here.
+	if details := partialErrors(resp); len(details) > 0 {
+		if response == nil {
+			return &PartialError{Errors: details}
+		}
+		c.logWarn("Request %s %s succeeded with %d item error(s)", method, endpoint, len(details))
+	}
 
-	if response != nil {
+	// flespi may answer a DELETE with an empty body; leave response untouched then
+	if response != nil && len(resp) > 0 {
 		if err = json.Unmarshal(resp, response); err != nil {
 			c.logError("Failed to unmarshal response: %v", err)
 			return err
@@ -2,6 +2,7 @@ package flespi
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -256,3 +257,86 @@ func TestClient_AcceptsMultipleSuccessStatusCodes(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_PartialSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": [{"id": 1}], "errors": [{"id": 2, "reason": "access denied"}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-token")
+
+	err := client.RequestAPI("DELETE", "gw/devices/1,2", nil, nil)
+	if !IsPartialError(err) {
+		t.Fatalf("Expected *PartialError, got %v", err)
+	}
+
+	partialErr := err.(*PartialError)
+	if len(partialErr.Errors) != 1 || partialErr.Errors[0].ID != 2 {
+		t.Errorf("Unexpected errors %+v", partialErr.Errors)
+	}
+
+	// callers that decode the body get the errors in their own response type
+	var resp struct {
+		Result []map[string]interface{} `json:"result"`
+		Errors []ErrorDetail            `json:"errors"`
+	}
+	if err := client.RequestAPI("DELETE", "gw/devices/1,2", nil, &resp); err != nil {
+		t.Fatalf("RequestAPI() error = %v", err)
+	}
+	if len(resp.Result) != 1 || len(resp.Errors) != 1 {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+}
+
+func TestClient_ForAccount(t *testing.T) {
+	var cids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cids = append(cids, r.Header.Get("x-flespi-cid"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": [{"id": 1, "name": "d1"}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-token")
+	scoped := client.ForAccount(42)
+
+	if scoped.AccountId() != 42 || client.AccountId() != 0 {
+		t.Fatalf("unexpected account ids: scoped %d, client %d", scoped.AccountId(), client.AccountId())
+	}
+
+	if _, err := scoped.Devices.List(); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if err := scoped.Streams.DeleteById(7); err != nil {
+		t.Fatalf("DeleteById() error = %v", err)
+	}
+	if _, err := client.Devices.List(); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	expected := []string{"42", "42", ""}
+	for i, cid := range expected {
+		if cids[i] != cid {
+			t.Errorf("request %d: expected x-flespi-cid %q, got %q", i, cid, cids[i])
+		}
+	}
+}
+
+func TestClient_RawBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "text/plain" || string(body) != "raw content" {
+			t.Errorf("Expected the raw body with its content type, got %q %q", r.Header.Get("Content-Type"), body)
+		}
+		w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-token")
+
+	if err := client.RequestAPI("POST", "gw/devices/1/media", RawBody{ContentType: "text/plain", Data: []byte("raw content")}, nil); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package flespi
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	flespi_token "github.com/mixser/flespi-client/resources/gateway/token"
+)
+
+// CredentialProvider supplies the flespi token for each request. Implementations
+// must be safe for concurrent use; Token is called once per attempt.
+type CredentialProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// CredentialRefresher is implemented by providers that can fetch a new token.
+// When flespi answers 401, the client calls Refresh and, if the provider then
+// returns a different token, repeats the request once.
+type CredentialRefresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// compile-time check that token managers can be passed to WithCredentials
+var (
+	_ CredentialProvider  = (*flespi_token.TokenManager)(nil)
+	_ CredentialRefresher = (*flespi_token.TokenManager)(nil)
+)
+
+// WithCredentials makes the client take its token from provider instead of the
+// token passed to NewClient, which may then be empty.
+//
+// Example:
+//
+//	client, err := flespi.NewClient("https://flespi.io", "",
+//	    flespi.WithCredentials(flespi.NewFileCredentials("/run/secrets/flespi-token")),
+//	)
+func WithCredentials(provider CredentialProvider) ClientOption {
+	return func(c *Client) {
+		c.credentials.set(provider)
+	}
+}
+
+// SetToken replaces the token of c and of every client created from it with
+// ForAccount, discarding any provider set with WithCredentials. Together with a
+// provider it is the only supported way to rotate the token. It is safe to call
+// while requests are in flight; they finish with the token they started with.
+//
+// A client built as a struct literal rather than with NewClient has no
+// credentials to share; SetToken then assigns Token, without synchronisation.
+func (c *Client) SetToken(token string) {
+	if c.credentials == nil {
+		c.Token = token
+		return
+	}
+	c.credentials.set(NewStaticCredentials(token))
+}
+
+// credentialsHolder lets SetToken swap the provider shared by scoped clients
+type credentialsHolder struct {
+	mu       sync.RWMutex
+	provider CredentialProvider
+}
+
+func (h *credentialsHolder) set(provider CredentialProvider) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.provider = provider
+}
+
+func (h *credentialsHolder) get() CredentialProvider {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.provider
+}
+
+// token returns the token for the next attempt
+func (c *Client) token(ctx context.Context) (string, error) {
+	// clients built as struct literals have no holder and send the field as is
+	if c.credentials == nil {
+		return c.Token, nil
+	}
+
+	provider := c.credentials.get()
+	if provider == nil {
+		return "", nil
+	}
+
+	token, err := provider.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("flespi credentials: %w", err)
+	}
+
+	return token, nil
+}
+
+// refreshCredentials asks the provider for a new token after a 401 and reports
+// whether it differs from the rejected one
+func (c *Client) refreshCredentials(ctx context.Context, rejected string) bool {
+	if c.credentials == nil {
+		return false
+	}
+
+	refresher, ok := c.credentials.get().(CredentialRefresher)
+	if !ok {
+		return false
+	}
+
+	if err := refresher.Refresh(ctx); err != nil {
+		c.logWarn("Failed to refresh credentials after 401: %v", err)
+		return false
+	}
+
+	token, err := c.token(ctx)
+	if err != nil || token == rejected {
+		return false
+	}
+
+	if c.Logger != nil {
+		c.Logger.Infof("Credentials refreshed after 401")
+	}
+	return true
+}
+
+// StaticCredentials is a CredentialProvider holding a token in memory; Set swaps it atomically
+type StaticCredentials struct {
+	token atomic.Value
+}
+
+// NewStaticCredentials creates a provider that always returns token
+func NewStaticCredentials(token string) *StaticCredentials {
+	s := &StaticCredentials{}
+	s.token.Store(token)
+	return s
+}
+
+// Token implements CredentialProvider
+func (s *StaticCredentials) Token(ctx context.Context) (string, error) {
+	token, _ := s.token.Load().(string)
+	return token, nil
+}
+
+// Set replaces the token
+func (s *StaticCredentials) Set(token string) {
+	s.token.Store(token)
+}
+
+// EnvCredentials reads the token from an environment variable on every request
+type EnvCredentials struct {
+	name string
+}
+
+// NewEnvCredentials creates a provider reading the environment variable name, e.g. "FLESPI_TOKEN"
+func NewEnvCredentials(name string) *EnvCredentials {
+	return &EnvCredentials{name: name}
+}
+
+// Token implements CredentialProvider
+func (e *EnvCredentials) Token(ctx context.Context) (string, error) {
+	token := strings.TrimSpace(os.Getenv(e.name))
+	if token == "" {
+		return "", fmt.Errorf("environment variable %s is not set", e.name)
+	}
+	return token, nil
+}
+
+// Refresh implements CredentialRefresher; the variable is read on every call anyway
+func (e *EnvCredentials) Refresh(ctx context.Context) error {
+	return nil
+}
+
+// FileCredentials reads the token from a file, e.g. a mounted Kubernetes secret,
+// and re-reads it whenever the file's modification time changes
+type FileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+}
+
+// NewFileCredentials creates a provider reading the token from path; surrounding whitespace is ignored
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{path: path}
+}
+
+// Token implements CredentialProvider
+func (f *FileCredentials) Token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", err
+	}
+
+	if f.token != "" && info.ModTime().Equal(f.modTime) {
+		return f.token, nil
+	}
+
+	return f.load(info.ModTime())
+}
+
+// Refresh implements CredentialRefresher by re-reading the file
+func (f *FileCredentials) Refresh(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.load(info.ModTime())
+	return err
+}
+
+// load reads the file; the caller holds mu
+func (f *FileCredentials) load(modTime time.Time) (string, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", f.path)
+	}
+
+	f.token = token
+	f.modTime = modTime
+
+	return token, nil
+}
+
+// CredentialsFunc adapts a function to the CredentialProvider interface, e.g. to
+// fetch the token from a secrets manager. Cache in the function if the lookup is slow.
+type CredentialsFunc func(ctx context.Context) (string, error)
+
+// Token implements CredentialProvider
+func (f CredentialsFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
@@ -0,0 +1,230 @@
+package flespi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_SetToken(t *testing.T) {
+	var auth atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+		w.Write([]byte(`{"result": []}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "old-token")
+	scoped := client.ForAccount(42)
+
+	client.SetToken("new-token")
+
+	if err := scoped.RequestAPI("GET", "gw/devices/all", nil, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := auth.Load(); got != "FlespiToken new-token" {
+		t.Errorf("Expected the scoped client to use the new token, got %v", got)
+	}
+}
+
+func TestClient_AssignToken(t *testing.T) {
+	var auth atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+		w.Write([]byte(`{"result": []}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "old-token")
+	scoped := client.ForAccount(42)
+
+	// the field is only read by NewClient
+	client.Token = "assigned-token"
+
+	steps := []struct {
+		client   *Client
+		token    string
+		expected string
+	}{
+		// the scoped copy makes the first request
+		{scoped, "", "FlespiToken old-token"},
+		{client, "", "FlespiToken old-token"},
+		{client, "set-token", "FlespiToken set-token"},
+		{scoped, "", "FlespiToken set-token"},
+	}
+	for _, step := range steps {
+		if step.token != "" {
+			client.SetToken(step.token)
+		}
+		if err := step.client.RequestAPI("GET", "gw/devices/all", nil, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := auth.Load(); got != step.expected {
+			t.Errorf("Expected %q, got %v", step.expected, got)
+		}
+	}
+}
+
+func TestClient_StructLiteral(t *testing.T) {
+	var auth atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+		w.Write([]byte(`{"result": []}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Host: server.URL, Token: "literal-token", HTTPClient: http.DefaultClient}
+
+	if err := client.RequestAPI("GET", "gw/devices/all", nil, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := auth.Load(); got != "FlespiToken literal-token" {
+		t.Errorf("Expected the token of the field, got %v", got)
+	}
+
+	client.SetToken("set-token")
+	client.RequestAPI("GET", "gw/devices/all", nil, nil)
+	if got := auth.Load(); got != "FlespiToken set-token" {
+		t.Errorf("Expected the token from SetToken, got %v", got)
+	}
+}
+
+func TestClient_SetTokenConcurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result": []}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "token-0")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.SetToken("token-1")
+		}()
+		go func() {
+			defer wg.Done()
+			client.RequestAPI("GET", "gw/devices/all", nil, nil)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClient_RefreshOn401(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("Authorization") != "FlespiToken fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errors": [{"reason": "invalid token"}]}`))
+			return
+		}
+		w.Write([]byte(`{"result": []}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(path, []byte("stale\n"), 0o600)
+
+	var attempts []int
+	observer := ObserverFunc(func(ctx context.Context, event RequestEvent) {
+		attempts = append(attempts, event.Attempt)
+	})
+
+	provider := NewFileCredentials(path)
+	client, _ := NewClient(server.URL, "", WithCredentials(provider), WithObserver(observer))
+
+	if _, err := provider.Token(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// rotate the file without changing its modification time, only Refresh notices
+	info, _ := os.Stat(path)
+	os.WriteFile(path, []byte("fresh\n"), 0o600)
+	os.Chtimes(path, info.ModTime(), info.ModTime())
+
+	if err := client.RequestAPI("GET", "gw/devices/all", nil, nil); err != nil {
+		t.Fatalf("Expected the request to succeed after refresh, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+	if len(attempts) != 2 || attempts[0] != 0 || attempts[1] != 1 {
+		t.Errorf("Expected the repeat to be observed as attempt 1, got %v", attempts)
+	}
+}
+
+func TestClient_NoRefreshWithoutNewToken(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errors": [{"reason": "invalid token"}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("FLESPI_TEST_TOKEN", "revoked")
+	client, _ := NewClient(server.URL, "", WithCredentials(NewEnvCredentials("FLESPI_TEST_TOKEN")))
+
+	err := client.RequestAPI("GET", "gw/devices/all", nil, nil)
+	if !IsUnauthorizedError(err) {
+		t.Errorf("Expected unauthorized error, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected the request not to be repeated with the same token, got %d requests", requests)
+	}
+}
+
+func TestCredentialProviders(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("env missing", func(t *testing.T) {
+		t.Setenv("FLESPI_TEST_TOKEN", "")
+		if _, err := NewEnvCredentials("FLESPI_TEST_TOKEN").Token(ctx); err == nil {
+			t.Error("Expected an error for an unset variable")
+		}
+	})
+
+	t.Run("file picks up changes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		os.WriteFile(path, []byte("first"), 0o600)
+		provider := NewFileCredentials(path)
+
+		if token, _ := provider.Token(ctx); token != "first" {
+			t.Errorf("Expected 'first', got %q", token)
+		}
+
+		os.WriteFile(path, []byte("second"), 0o600)
+		later := time.Now().Add(time.Minute)
+		os.Chtimes(path, later, later)
+
+		if token, _ := provider.Token(ctx); token != "second" {
+			t.Errorf("Expected 'second', got %q", token)
+		}
+	})
+
+	t.Run("func error", func(t *testing.T) {
+		failing := errors.New("vault unavailable")
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("Expected no request without a token")
+		}))
+		defer server.Close()
+
+		client, _ := NewClient(server.URL, "", WithCredentials(CredentialsFunc(func(ctx context.Context) (string, error) {
+			return "", failing
+		})))
+
+		if err := client.RequestAPI("GET", "gw/devices/all", nil, nil); !errors.Is(err, failing) {
+			t.Errorf("Expected the provider error, got %v", err)
+		}
+	})
+}
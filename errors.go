@@ -2,7 +2,12 @@ package flespi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"time"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
 // APIError represents an error returned by the Flespi API
@@ -13,13 +18,13 @@ type APIError struct {
 	Message    string
 	RawBody    []byte
 	Errors     []ErrorDetail
+
+	// RetryAfter is the delay requested by the Retry-After header, if any
+	RetryAfter time.Duration
 }
 
 // ErrorDetail represents a single error from the Flespi API response
-type ErrorDetail struct {
-	Reason string `json:"reason"`
-	ID     int64  `json:"id,omitempty"`
-}
+type ErrorDetail = flespiapi.ErrorDetail
 
 // Error implements the error interface
 func (e *APIError) Error() string {
@@ -31,13 +36,21 @@ func (e *APIError) Error() string {
 		e.Method, e.Endpoint, e.StatusCode, e.Message)
 }
 
+// PartialError carries the per-item errors flespi reported in a successful (2xx) response.
+// Resource packages return it when a single-object call produced no item, and the client
+// returns it for requests that do not decode the response body.
+type PartialError = flespiapi.PartialError
+
+// ErrNoResult is returned when flespi answered a single-object call with an empty result.
+var ErrNoResult = flespiapi.ErrNoResult
+
 // errorResponse represents the error structure returned by Flespi API
 type errorResponse struct {
 	Errors []ErrorDetail `json:"errors"`
 }
 
 // parseAPIError attempts to parse the error response from Flespi API
-func parseAPIError(statusCode int, method, endpoint string, body []byte) error {
+func parseAPIError(statusCode int, method, endpoint string, body []byte) *APIError {
 	apiErr := &APIError{
 		StatusCode: statusCode,
 		Method:     method,
@@ -55,26 +68,120 @@ func parseAPIError(statusCode int, method, endpoint string, body []byte) error {
 	return apiErr
 }
 
-// IsNotFoundError checks if the error is a 404 Not Found error
-func IsNotFoundError(err error) bool {
-	if apiErr, ok := err.(*APIError); ok {
-		return apiErr.StatusCode == 404
+// partialErrors extracts the "errors" array from a successful response body
+func partialErrors(body []byte) []ErrorDetail {
+	if len(body) == 0 {
+		return nil
 	}
-	return false
+
+	var errResp errorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return nil
+	}
+
+	return errResp.Errors
+}
+
+// Sentinel errors for classifying failures with errors.Is. *APIError, *PartialError
+// and the argument checks of the resource packages report the matching sentinel.
+var (
+	ErrNotFound      = flespiapi.ErrNotFound
+	ErrUnauthorized  = flespiapi.ErrUnauthorized
+	ErrForbidden     = flespiapi.ErrForbidden
+	ErrValidation    = flespiapi.ErrValidation
+	ErrConflict      = flespiapi.ErrConflict
+	ErrRateLimited   = flespiapi.ErrRateLimited
+	ErrLimitExceeded = flespiapi.ErrLimitExceeded
+	ErrServer        = flespiapi.ErrServer
+)
+
+// RegisterErrorCode maps a flespi error code to one of the sentinel errors.
+//
+// Example:
+//
+//	flespi.RegisterErrorCode(7, flespi.ErrLimitExceeded)
+func RegisterErrorCode(code int64, kind error) {
+	flespiapi.RegisterErrorCode(code, kind)
+}
+
+// Kind returns the sentinel error describing e, or nil for unclassified statuses
+func (e *APIError) Kind() error {
+	return flespiapi.KindOf(e.StatusCode, e.Errors)
+}
+
+// Is lets errors.Is match an *APIError against the sentinel errors
+func (e *APIError) Is(target error) bool {
+	kind := e.Kind()
+	return kind != nil && kind == target
+}
+
+// Classify returns the sentinel error describing err (ErrNotFound, ErrForbidden, ...),
+// looking through wrapped errors. It returns nil when err is nil or unclassified.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	for _, kind := range []error{
+		ErrLimitExceeded, ErrRateLimited, ErrValidation, ErrUnauthorized,
+		ErrForbidden, ErrNotFound, ErrConflict, ErrServer,
+	} {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	return nil
+}
+
+// IsRetryable reports whether repeating the same request may succeed:
+// rate limiting, server errors and network timeouts. Everything else, such as
+// validation, ACL and limit errors, is permanent until something changes.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServer) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsNotFoundError checks if the error is a 404 Not Found error or an empty result
+func IsNotFoundError(err error) bool {
+	return errors.Is(err, ErrNotFound)
 }
 
 // IsUnauthorizedError checks if the error is a 401 Unauthorized error
 func IsUnauthorizedError(err error) bool {
-	if apiErr, ok := err.(*APIError); ok {
-		return apiErr.StatusCode == 401
-	}
-	return false
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsForbiddenError checks if the token's ACL denied the request (403)
+func IsForbiddenError(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsConflictError checks if the error is a 409 Conflict error
+func IsConflictError(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsLimitExceededError checks if an account limit prevented the request
+func IsLimitExceededError(err error) bool {
+	return errors.Is(err, ErrLimitExceeded)
 }
 
 // IsRateLimitError checks if the error is a 429 Too Many Requests error
 func IsRateLimitError(err error) bool {
-	if apiErr, ok := err.(*APIError); ok {
-		return apiErr.StatusCode == 429
-	}
-	return false
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsPartialError checks if flespi accepted the request but rejected some of its items
+func IsPartialError(err error) bool {
+	var partialErr *PartialError
+	return errors.As(err, &partialErr)
 }
@@ -0,0 +1,116 @@
+package flespi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	flespi_stream "github.com/mixser/flespi-client/resources/gateway/stream"
+)
+
+func TestErrorHelpers_Wrapped(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		checkFunc  func(error) bool
+	}{
+		{"IsNotFoundError", 404, IsNotFoundError},
+		{"IsUnauthorizedError", 401, IsUnauthorizedError},
+		{"IsForbiddenError", 403, IsForbiddenError},
+		{"IsValidationError", 400, IsValidationError},
+		{"IsConflictError", 409, IsConflictError},
+		{"IsRateLimitError", 429, IsRateLimitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("sync devices: %w", &APIError{StatusCode: tt.statusCode})
+			if !tt.checkFunc(err) {
+				t.Errorf("Expected %s to match a wrapped %d", tt.name, tt.statusCode)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"nil", nil, nil},
+		{"not found", &APIError{StatusCode: 404}, ErrNotFound},
+		{"empty result", ErrNoResult, ErrNotFound},
+		{"server", &APIError{StatusCode: 503}, ErrServer},
+		{"limit by reason", &APIError{StatusCode: 403, Errors: []ErrorDetail{{Reason: "devices limit exceeded"}}}, ErrLimitExceeded},
+		{"partial", &PartialError{Errors: []ErrorDetail{{Reason: "channels limit reached", ID: 3}}}, ErrLimitExceeded},
+		{"validation", &ValidationError{Field: "host", Message: "is required"}, ErrValidation},
+		{"unknown", errors.New("boom"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.expected {
+				t.Errorf("Classify() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"rate limited", &APIError{StatusCode: 429}, true},
+		{"server error", fmt.Errorf("wrapped: %w", &APIError{StatusCode: 502}), true},
+		{"validation", &APIError{StatusCode: 400}, false},
+		{"limit", &APIError{StatusCode: 400, Errors: []ErrorDetail{{Reason: "storage limit exceeded"}}}, false},
+		{"rate limit reason", &APIError{StatusCode: 429, Errors: []ErrorDetail{{Reason: "rate limit exceeded"}}}, true},
+		{"canceled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.expected {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRegisterErrorCode(t *testing.T) {
+	RegisterErrorCode(990001, ErrConflict)
+
+	err := &APIError{StatusCode: 400, Errors: []ErrorDetail{{Reason: "already exists", Code: 990001}}}
+
+	if !errors.Is(err, ErrConflict) {
+		t.Error("Expected the registered code to take precedence over the status")
+	}
+	if errors.Is(err, ErrValidation) {
+		t.Error("Expected the error not to match ErrValidation")
+	}
+}
+
+func TestErrors_FromResourcePackages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":[{"reason":"access denied"}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-token")
+
+	if _, err := client.Streams.Get(1); !IsForbiddenError(err) {
+		t.Errorf("Expected a forbidden error, got %v", err)
+	}
+
+	// argument checks fail before any request is sent
+	if err := flespi_stream.DeleteStream(client, flespi_stream.Stream{}); !IsValidationError(err) {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+}
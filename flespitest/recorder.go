@@ -0,0 +1,404 @@
+package flespitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	flespi "github.com/mixser/flespi-client"
+)
+
+// RecorderMode selects whether a Recorder talks to flespi or to its cassette
+type RecorderMode int
+
+const (
+	// ModeReplay answers from the cassette and fails requests it has no recording for
+	ModeReplay RecorderMode = iota
+	// ModeRecord sends every request to flespi and records it, replacing the cassette on Save
+	ModeRecord
+	// ModeAuto replays when the cassette file exists and records otherwise
+	ModeAuto
+)
+
+// Cassette holds recorded interactions as stored on disk
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and the response flespi gave
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest holds the parts of a request used for matching
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+
+	// AccountId is the x-flespi-cid header, 0 if absent
+	AccountId int64 `json:"cid,omitempty"`
+
+	// Body is the scrubbed JSON payload; Text holds other UTF-8 payloads and
+	// Binary, base64-encoded on disk, the rest, such as media files. Multipart
+	// boundaries are replaced with a fixed one, so uploads match across runs.
+	Body   json.RawMessage `json:"body,omitempty"`
+	Text   string          `json:"text,omitempty"`
+	Binary []byte          `json:"binary,omitempty"`
+}
+
+// RecordedResponse is replayed for a matching request
+type RecordedResponse struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+
+	Body   json.RawMessage `json:"body,omitempty"`
+	Text   string          `json:"text,omitempty"`
+	Binary []byte          `json:"binary,omitempty"`
+}
+
+// recordedBoundary replaces the random boundary of multipart requests
+const recordedBoundary = "flespitest-boundary"
+
+// Recorder is an http.RoundTripper that records flespi traffic to a cassette
+// file and replays it, so tests can pin behaviour against real payloads
+// without network access or credentials:
+//
+//	recorder, err := flespitest.NewRecorder("testdata/devices.json", flespitest.ModeAuto)
+//	defer recorder.Save()
+//
+//	client, _ := flespi.NewClient("https://flespi.io", os.Getenv("FLESPI_TOKEN"),
+//	    flespi.WithHTTPClient(&http.Client{Transport: recorder}),
+//	)
+//
+// Requests match a recording by method, path, query (the order of parameters
+// and of the names in fields does not matter), x-flespi-cid and JSON body
+// (compared semantically). Matching
+// recordings are replayed in the order they were made, so a list before and
+// after a create returns different results.
+//
+// The Authorization header is never stored, and bodies pass through Scrub
+// before they are written.
+type Recorder struct {
+	// Transport performs real requests while recording (default: http.DefaultTransport)
+	Transport http.RoundTripper
+
+	// Scrub removes secrets from request and response bodies (default: flespi.RedactJSON)
+	Scrub func([]byte) []byte
+
+	path string
+	mode RecorderMode
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder opens the cassette at path. Replaying needs the file to exist;
+// recording creates it on Save.
+func NewRecorder(path string, mode RecorderMode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil && mode != ModeRecord:
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("flespitest: invalid cassette %s: %w", path, err)
+		}
+		r.mode = ModeReplay
+	case os.IsNotExist(err) && mode == ModeAuto:
+		r.mode = ModeRecord
+	case err != nil && mode != ModeRecord:
+		return nil, fmt.Errorf("flespitest: cannot read cassette: %w", err)
+	}
+
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Recording reports whether requests are sent to flespi
+func (r *Recorder) Recording() bool {
+	return r.mode == ModeRecord
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := r.recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode != ModeRecord {
+		return r.replay(req, recorded)
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	response := RecordedResponse{StatusCode: res.StatusCode, Header: recordedHeader(res.Header)}
+	response.Body, response.Text, response.Binary = r.encodeBody(body)
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{Request: recorded, Response: response})
+	r.used = append(r.used, true)
+	r.mu.Unlock()
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return res, nil
+}
+
+// Save writes the cassette when recording; it does nothing when replaying
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// Unused returns the recordings that were not replayed, to detect tests that
+// stopped making requests they were recorded with
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for i, interaction := range r.cassette.Interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !interaction.Request.matches(recorded) {
+			continue
+		}
+		r.used[i] = true
+
+		response := interaction.Response
+
+		body := []byte(response.Text)
+		switch {
+		case len(response.Body) > 0:
+			body = response.Body
+		case len(response.Binary) > 0:
+			body = response.Binary
+		}
+
+		header := response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode)),
+			StatusCode:    response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("flespitest: no recorded interaction for %s %s in %s", recorded.Method, recorded.Path, r.path)
+}
+
+// recordRequest extracts the matching key of req, restoring its body
+func (r *Recorder) recordRequest(req *http.Request) (RecordedRequest, error) {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Path:   strings.TrimPrefix(req.URL.EscapedPath(), "/"),
+		Query:  normalizeQuery(req.URL.Query()),
+	}
+
+	if cid := req.Header.Get("x-flespi-cid"); cid != "" {
+		fmt.Sscanf(cid, "%d", &recorded.AccountId)
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return recorded, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		if _, params, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err == nil && params["boundary"] != "" {
+			body = bytes.ReplaceAll(body, []byte(params["boundary"]), []byte(recordedBoundary))
+		}
+		recorded.Body, recorded.Text, recorded.Binary = r.encodeBody(body)
+	}
+
+	return recorded, nil
+}
+
+// encodeBody scrubs body and stores it as JSON when possible, else as text or,
+// when it is not valid UTF-8, as binary
+func (r *Recorder) encodeBody(body []byte) (json.RawMessage, string, []byte) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, "", nil
+	}
+
+	if !utf8.Valid(body) {
+		return nil, "", body
+	}
+	if !json.Valid(body) {
+		return nil, string(body), nil
+	}
+
+	scrub := r.Scrub
+	if scrub == nil {
+		scrub = flespi.RedactJSON
+	}
+
+	return json.RawMessage(scrub(body)), "", nil
+}
+
+func (q RecordedRequest) matches(other RecordedRequest) bool {
+	if q.Method != other.Method || q.Path != other.Path || !queryEqual(q.Query, other.Query) ||
+		q.AccountId != other.AccountId || q.Text != other.Text || !bytes.Equal(q.Binary, other.Binary) {
+		return false
+	}
+
+	return jsonEqual(q.Body, other.Body)
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	var x, y interface{}
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// queryEqual compares normalised queries; data parameters, which carry a JSON
+// document such as a device messages request, are compared semantically
+func queryEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	x, errX := url.ParseQuery(a)
+	y, errY := url.ParseQuery(b)
+	if errX != nil || errY != nil || len(x) != len(y) {
+		return false
+	}
+
+	for key, values := range x {
+		others := y[key]
+		if len(values) != len(others) {
+			return false
+		}
+		for i, value := range values {
+			if value == others[i] || key == "data" && jsonEqual(json.RawMessage(value), json.RawMessage(others[i])) {
+				continue
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeQuery sorts parameters, the names in fields and the keys of a JSON
+// data parameter, so that their order does not affect matching
+func normalizeQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			if key == "fields" {
+				fields := strings.Split(value, ",")
+				sort.Strings(fields)
+				value = strings.Join(fields, ",")
+			}
+			if key == "data" {
+				value = canonicalJSON(value)
+			}
+			parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// canonicalJSON re-encodes a JSON document with sorted keys; other values are returned as they are
+func canonicalJSON(value string) string {
+	var document interface{}
+	if json.Unmarshal([]byte(value), &document) != nil {
+		return value
+	}
+
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return value
+	}
+	return string(encoded)
+}
+
+// recordedHeader keeps the response headers the client acts on
+func recordedHeader(header http.Header) http.Header {
+	kept := http.Header{}
+	for _, name := range []string{"Content-Type", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"} {
+		if values := header.Values(name); len(values) > 0 {
+			kept[name] = values
+		}
+	}
+	return kept
+}
@@ -0,0 +1,193 @@
+package flespitest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	flespi "github.com/mixser/flespi-client"
+	flespi_device "github.com/mixser/flespi-client/resources/gateway/device"
+)
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "tokens.json")
+
+	server := NewServer()
+
+	recorder, err := NewRecorder(path, ModeAuto)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	if !recorder.Recording() {
+		t.Fatal("Expected ModeAuto to record without a cassette")
+	}
+
+	client, _ := flespi.NewClient(server.URL, "secret-token", flespi.WithHTTPClient(&http.Client{Transport: recorder}))
+
+	created, err := client.Tokens.Create("ci")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := client.Tokens.List(flespi.WithFields("id", "info")); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	server.Close()
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "secret-token") || strings.Contains(string(data), created.Key) {
+		t.Error("Expected the cassette to contain neither the request token nor the created key")
+	}
+
+	replayer, err := NewRecorder(path, ModeAuto)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	if replayer.Recording() {
+		t.Fatal("Expected ModeAuto to replay an existing cassette")
+	}
+
+	// the host does not matter when replaying, nothing is dialled
+	client, _ = flespi.NewClient("https://flespi.io", "other-token", flespi.WithHTTPClient(&http.Client{Transport: replayer}))
+
+	token, err := client.Tokens.Create("ci")
+	if err != nil {
+		t.Fatalf("Create() replay error = %v", err)
+	}
+	if token.Id != created.Id || token.Key != "[REDACTED]" {
+		t.Errorf("Expected the recorded token with a scrubbed key, got %+v", token)
+	}
+
+	// fields in a different order still match
+	tokens, err := client.Tokens.List(flespi.WithFields("info", "id"))
+	if err != nil || len(tokens) != 1 {
+		t.Errorf("Expected the recorded list, got %v (%v)", tokens, err)
+	}
+
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("Expected every recording to be replayed, %d left", len(unused))
+	}
+}
+
+func TestRecorder_ReplayMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+
+	if _, err := NewRecorder(path, ModeReplay); err == nil {
+		t.Error("Expected an error for a missing cassette in replay mode")
+	}
+
+	os.WriteFile(path, []byte(`{"interactions": [{
+		"request": {"method": "POST", "path": "platform/tokens", "body": [{"info": "a", "enabled": false, "expire": 0, "ttl": 0}]},
+		"response": {"status": 200, "body": {"result": [{"id": 1, "info": "a"}]}}
+	}]}`), 0o644)
+
+	recorder, err := NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+
+	client, _ := flespi.NewClient("https://flespi.io", "token",
+		flespi.WithHTTPClient(&http.Client{Transport: recorder}),
+		flespi.WithRetryConfig(nil),
+	)
+
+	if _, err := client.Tokens.Create("b"); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Errorf("Expected a mismatch for a different body, got %v", err)
+	}
+	if _, err := client.Tokens.Create("a"); err != nil {
+		t.Errorf("Expected the matching body to replay, got %v", err)
+	}
+	if _, err := client.Tokens.Create("a"); err == nil {
+		t.Error("Expected a recording to be replayed only once")
+	}
+}
+
+func TestRecorder_DataQueryMatchesSemantically(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.json")
+
+	// recorded with another key order and number formatting than the client produces
+	data := url.QueryEscape(`{"to": 1.7000036e9, "count": 10, "from": 1700000000}`)
+	os.WriteFile(path, []byte(`{"interactions": [{
+		"request": {"method": "GET", "path": "gw/devices/7/messages", "query": "data=`+data+`"},
+		"response": {"status": 200, "body": {"result": [{"timestamp": 1700000001, "device.id": 7}]}}
+	}]}`), 0o644)
+
+	recorder, err := NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+
+	client, _ := flespi.NewClient("https://flespi.io", "token",
+		flespi.WithHTTPClient(&http.Client{Transport: recorder}),
+		flespi.WithRetryConfig(nil),
+	)
+
+	from := time.Unix(1700000000, 0)
+	messages, err := client.Devices.Messages(7, flespi_device.MessagesInRange(from, from.Add(time.Hour)), flespi_device.MessagesPageSize(10))
+	if err != nil || len(messages) != 1 {
+		t.Errorf("Expected the recorded messages, got %v (%v)", messages, err)
+	}
+}
+
+func TestRecorder_BinaryAndMultipartBodies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "media.json")
+	content := []byte{0xff, 0xd8, 0xff, 0xe0, 0x00, 0x10, 'J', 'F', 'I', 'F', 0x00, 0xfe}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gw/devices/7/media":
+			w.Write([]byte(`{"result": [{"uuid": "abc", "device_id": 7, "name": "photo.jpg"}]}`))
+		case "/gw/devices/7/media/abc/data":
+			w.Write(content)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	run := func(recorder *Recorder, host string) {
+		client, _ := flespi.NewClient(host, "token",
+			flespi.WithHTTPClient(&http.Client{Transport: recorder}),
+			flespi.WithRetryConfig(nil),
+		)
+
+		// every upload has a new random multipart boundary
+		if _, err := client.Devices.UploadMedia(7, "photo.jpg", bytes.NewReader(content)); err != nil {
+			t.Fatalf("UploadMedia() error = %v", err)
+		}
+
+		var downloaded bytes.Buffer
+		if _, err := client.Devices.DownloadMedia(7, "abc", &downloaded); err != nil {
+			t.Fatalf("DownloadMedia() error = %v", err)
+		}
+		if !bytes.Equal(downloaded.Bytes(), content) {
+			t.Errorf("Expected the media bytes unchanged, got %x", downloaded.Bytes())
+		}
+	}
+
+	recorder, _ := NewRecorder(path, ModeRecord)
+	run(recorder, server.URL)
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	server.Close()
+
+	replayer, err := NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	run(replayer, "https://flespi.io")
+
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("Expected every recording to be replayed, %d left", len(unused))
+	}
+}
@@ -0,0 +1,178 @@
+package flespitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// expression is a parsed {field=value,...} selector; all conditions must match
+type expression []condition
+
+type condition struct {
+	field    []string
+	operator string
+	value    string
+	quoted   bool
+}
+
+// operators in the order they are looked for, so that "!=" wins over "="
+var operators = []string{"!=", ">=", "<=", "=", ">", "<"}
+
+// parseExpression supports the subset of flespi expressions produced by
+// SelectWhere: comparisons of dotted field paths joined by commas, with quoted
+// strings compared literally and * wildcards in unquoted values
+func parseExpression(selector string) (expression, error) {
+	body := strings.TrimSuffix(strings.TrimPrefix(selector, "{"), "}")
+
+	var expr expression
+	for _, part := range splitOutsideQuotes(body, ',') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		cond, err := parseCondition(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %s: %w", selector, err)
+		}
+		expr = append(expr, cond)
+	}
+
+	if len(expr) == 0 {
+		return nil, fmt.Errorf("invalid selector %s: empty expression", selector)
+	}
+
+	return expr, nil
+}
+
+func parseCondition(part string) (condition, error) {
+	for _, op := range operators {
+		index := strings.Index(part, op)
+		if index <= 0 {
+			continue
+		}
+
+		value := strings.TrimSpace(part[index+len(op):])
+		cond := condition{
+			field:    strings.Split(strings.TrimSpace(part[:index]), "."),
+			operator: op,
+			value:    value,
+		}
+
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return condition{}, err
+			}
+			cond.value = unquoted
+			cond.quoted = true
+		}
+
+		return cond, nil
+	}
+
+	return condition{}, fmt.Errorf("no operator in %q", part)
+}
+
+func (e expression) matches(item Item) bool {
+	for _, cond := range e {
+		if !cond.matches(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c condition) matches(item Item) bool {
+	var value interface{} = map[string]interface{}(item)
+	for _, key := range c.field {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			value = nil
+			break
+		}
+		value = object[key]
+	}
+
+	actual := stringify(value)
+
+	switch c.operator {
+	case "=", "!=":
+		equal := actual == c.value
+		if !c.quoted && strings.Contains(c.value, "*") {
+			equal, _ = path.Match(c.value, actual)
+		} else if !c.quoted {
+			equal = equal || numbersEqual(actual, c.value)
+		}
+		return equal == (c.operator == "=")
+	}
+
+	left, errLeft := strconv.ParseFloat(actual, 64)
+	right, errRight := strconv.ParseFloat(c.value, 64)
+	if errLeft != nil || errRight != nil {
+		return false
+	}
+
+	switch c.operator {
+	case ">":
+		return left > right
+	case "<":
+		return left < right
+	case ">=":
+		return left >= right
+	default:
+		return left <= right
+	}
+}
+
+func stringify(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func numbersEqual(a, b string) bool {
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	return errX == nil && errY == nil && x == y
+}
+
+func splitOutsideQuotes(s string, sep rune) []string {
+	var parts []string
+	var current strings.Builder
+	inQuotes := false
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == sep && !inQuotes:
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+
+	return append(parts, current.String())
+}
@@ -0,0 +1,627 @@
+// Package flespitest provides an in-memory fake of the flespi REST API for
+// integration tests that should not need a live account.
+//
+// The fake keeps devices, channels, streams, calcs, geofences, tokens, webhooks,
+// subaccounts, limits and CDNs in memory and supports creating, listing,
+// updating and deleting them by id list, "all" or expression selectors. Requests
+// are scoped to the subaccount named in x-flespi-cid, the fields parameter is
+// honoured and errors can be injected per method and path:
+//
+//	server := flespitest.NewServer()
+//	defer server.Close()
+//
+//	client, _ := server.Client()
+//	device, _ := client.Devices.Create("truck", true, 1)
+//
+//	server.Inject(flespitest.Fault{Method: "GET", Path: "gw/devices", StatusCode: 503, Times: 1})
+//
+// Endpoints the fake does not emulate answer 404 unless a handler is added with Handle.
+package flespitest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	flespi "github.com/mixser/flespi-client"
+)
+
+// Collections lists the endpoints the fake emulates
+var Collections = []string{
+	"gw/devices",
+	"gw/channels",
+	"gw/streams",
+	"gw/calcs",
+	"gw/geofences",
+	"platform/tokens",
+	"platform/webhooks",
+	"platform/subaccounts",
+	"platform/limits",
+	"storage/cdns",
+}
+
+// DefaultAccountId is the id of the account the fake's tokens belong to
+const DefaultAccountId int64 = 1
+
+// Item is a stored flespi object as decoded from JSON
+type Item map[string]interface{}
+
+// Id returns the item's id
+func (i Item) Id() int64 {
+	id, _ := toInt64(i["id"])
+	return id
+}
+
+// AccountId returns the id of the account owning the item (cid)
+func (i Item) AccountId() int64 {
+	cid, _ := toInt64(i["cid"])
+	return cid
+}
+
+// Fault describes an error response to return instead of handling a request
+type Fault struct {
+	// Method matches the HTTP method; empty matches any
+	Method string
+
+	// Path matches requests whose path, without the leading slash, starts with it,
+	// e.g. "gw/devices"; empty matches any
+	Path string
+
+	// StatusCode of the response (default: 500)
+	StatusCode int
+
+	// Reason is reported in the errors array (default: the status text)
+	Reason string
+
+	// Header is added to the response, e.g. Retry-After
+	Header http.Header
+
+	// Times limits how often the fault fires; 0 means until ClearFaults
+	Times int
+}
+
+func (f *Fault) matches(method, path string) bool {
+	return (f.Method == "" || strings.EqualFold(f.Method, method)) && strings.HasPrefix(path, f.Path)
+}
+
+// Request is a request received by the fake
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+
+	// AccountId is the x-flespi-cid header, 0 if absent
+	AccountId int64
+
+	Body []byte
+}
+
+// Server is a running fake flespi API; its URL is the host to pass to flespi.NewClient
+type Server struct {
+	*httptest.Server
+
+	// AccountId owns the items created without x-flespi-cid (default: DefaultAccountId)
+	AccountId int64
+
+	// Token, when set, is the only token accepted; otherwise any token is
+	Token string
+
+	mux *http.ServeMux
+
+	mu       sync.Mutex
+	nextId   int64
+	items    map[string]map[int64]Item
+	faults   []*Fault
+	requests []Request
+}
+
+// NewServer starts a fake flespi API with no items; call Close when done
+func NewServer() *Server {
+	s := &Server{
+		AccountId: DefaultAccountId,
+		mux:       http.NewServeMux(),
+		nextId:    1000,
+		items:     make(map[string]map[int64]Item),
+	}
+
+	for _, collection := range Collections {
+		s.items[collection] = make(map[int64]Item)
+	}
+
+	s.mux.HandleFunc("/", s.serveREST)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Client returns a flespi client pointed at the fake
+func (s *Server) Client(options ...flespi.ClientOption) (*flespi.Client, error) {
+	token := s.Token
+	if token == "" {
+		token = "flespitest-token"
+	}
+
+	return flespi.NewClient(s.URL, token, options...)
+}
+
+// Handle serves pattern with handler instead of the built-in emulation, for
+// endpoints the fake does not cover. Patterns follow http.ServeMux, e.g.
+// "GET /gw/devices/{id}/telemetry/all". Faults and authorization still apply.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Inject makes matching requests fail; faults are checked in the order they were added
+func (s *Server) Inject(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fault.StatusCode == 0 {
+		fault.StatusCode = http.StatusInternalServerError
+	}
+	if fault.Reason == "" {
+		fault.Reason = http.StatusText(fault.StatusCode)
+	}
+
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults removes all injected faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// Requests returns the requests received so far, oldest first
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// Seed stores items in collection as if accountId had created them (0 for the
+// server's own account) and returns their ids. Items may be structs of the
+// resource packages or maps; ids already set are kept. An id that is already
+// taken is an error, and then none of the items are stored.
+func (s *Server) Seed(collection string, accountId int64, items ...interface{}) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, ok := s.items[collection]
+	if !ok {
+		return nil, fmt.Errorf("flespitest: unknown collection %q", collection)
+	}
+	if accountId == 0 {
+		accountId = s.AccountId
+	}
+
+	decoded := make([]Item, 0, len(items))
+	taken := make(map[int64]bool)
+	for _, value := range items {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		item, err := decodeItem(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if id := item.Id(); id != 0 {
+			if _, exists := store[id]; exists || taken[id] {
+				return nil, fmt.Errorf("flespitest: %s already holds an item with id %d", collection, id)
+			}
+			taken[id] = true
+		}
+
+		decoded = append(decoded, item)
+	}
+
+	ids := make([]int64, 0, len(decoded))
+	for _, item := range decoded {
+		ids = append(ids, s.insert(collection, store, item, accountId).Id())
+	}
+
+	return ids, nil
+}
+
+// Items returns a copy of the items stored in collection, ordered by id
+func (s *Server) Items(collection string) []Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []Item
+	for _, item := range sortedItems(s.items[collection]) {
+		items = append(items, copyItem(item))
+	}
+	return items
+}
+
+// Reset removes all items, faults and recorded requests
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for collection := range s.items {
+		s.items[collection] = make(map[int64]Item)
+	}
+	s.faults = nil
+	s.requests = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/")
+
+	accountId := int64(0)
+	if cid := r.Header.Get("x-flespi-cid"); cid != "" {
+		parsed, err := strconv.ParseInt(cid, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid x-flespi-cid header")
+			return
+		}
+		accountId = parsed
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method:    r.Method,
+		Path:      path,
+		Query:     r.URL.Query(),
+		AccountId: accountId,
+		Body:      body,
+	})
+	s.mu.Unlock()
+
+	// an unauthorised request must not use up a fault meant for a real one
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "FlespiToken ") || (s.Token != "" && auth != "FlespiToken "+s.Token) {
+		writeError(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+
+	s.mu.Lock()
+	fault := s.takeFault(r.Method, path)
+	s.mu.Unlock()
+
+	if fault != nil {
+		for key, values := range fault.Header {
+			for _, value := range values {
+				w.Header().Add(key, value)
+			}
+		}
+		writeError(w, fault.StatusCode, fault.Reason)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// takeFault returns the first fault matching the request; the caller holds mu
+func (s *Server) takeFault(method, path string) *Fault {
+	for i, fault := range s.faults {
+		if !fault.matches(method, path) {
+			continue
+		}
+
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+func (s *Server) serveREST(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	if len(segments) < 2 || len(segments) > 3 {
+		writeError(w, http.StatusNotFound, "endpoint not found")
+		return
+	}
+
+	collection := segments[0] + "/" + segments[1]
+
+	selector := ""
+	if len(segments) == 3 {
+		unescaped, err := url.PathUnescape(segments[2])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid selector")
+			return
+		}
+		selector = unescaped
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, ok := s.items[collection]
+	if !ok {
+		writeError(w, http.StatusNotFound, "endpoint not found")
+		return
+	}
+
+	accountId := s.AccountId
+	if cid := r.Header.Get("x-flespi-cid"); cid != "" {
+		accountId, _ = strconv.ParseInt(cid, 10, 64)
+		if !s.accountExists(accountId) {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("access denied to account %d", accountId))
+			return
+		}
+	}
+
+	switch {
+	case r.Method == http.MethodPost && selector == "":
+		s.create(w, r, collection, store, accountId)
+	case r.Method == http.MethodGet && selector != "":
+		s.list(w, r, store, selector, accountId)
+	case r.Method == http.MethodPut && selector != "":
+		s.update(w, r, store, selector, accountId)
+	case r.Method == http.MethodDelete && selector != "":
+		s.remove(w, store, selector, accountId)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed here", r.Method))
+	}
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request, collection string, store map[int64]Item, accountId int64) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		writeError(w, http.StatusBadRequest, "request body must be an array of objects")
+		return
+	}
+
+	created := make([]Item, 0, len(raw))
+	for _, data := range raw {
+		item, err := decodeItem(data)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// ids are assigned by flespi
+		delete(item, "id")
+		created = append(created, s.insert(collection, store, item, accountId))
+	}
+
+	writeResult(w, r, created, nil)
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, store map[int64]Item, selector string, accountId int64) {
+	matched, missing, err := s.selectItems(store, selector, accountId)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeResult(w, r, matched, missing)
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request, store map[int64]Item, selector string, accountId int64) {
+	var changes Item
+	if err := json.NewDecoder(r.Body).Decode(&changes); err != nil {
+		writeError(w, http.StatusBadRequest, "request body must be an object")
+		return
+	}
+	delete(changes, "id")
+	delete(changes, "cid")
+
+	matched, missing, err := s.selectItems(store, selector, accountId)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for _, item := range matched {
+		for key, value := range changes {
+			item[key] = value
+		}
+	}
+
+	writeResult(w, r, matched, missing)
+}
+
+func (s *Server) remove(w http.ResponseWriter, store map[int64]Item, selector string, accountId int64) {
+	matched, missing, err := s.selectItems(store, selector, accountId)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	deleted := make([]Item, 0, len(matched))
+	for _, item := range matched {
+		delete(store, item.Id())
+		deleted = append(deleted, Item{"id": item.Id()})
+	}
+
+	writeJSON(w, http.StatusOK, deleted, missing)
+}
+
+// insert assigns an id if needed and stores item; the caller holds mu
+func (s *Server) insert(collection string, store map[int64]Item, item Item, accountId int64) Item {
+	id := item.Id()
+	if id == 0 {
+		s.nextId++
+		id = s.nextId
+	} else if id > s.nextId {
+		s.nextId = id
+	}
+
+	item["id"] = id
+	item["cid"] = accountId
+
+	if collection == "platform/tokens" {
+		if key, _ := item["key"].(string); key == "" {
+			item["key"] = randomKey()
+		}
+	}
+
+	store[id] = item
+	return item
+}
+
+// accountExists reports whether accountId is the server's account or a
+// subaccount; the caller holds mu
+func (s *Server) accountExists(accountId int64) bool {
+	if accountId == s.AccountId {
+		return true
+	}
+	_, ok := s.items["platform/subaccounts"][accountId]
+	return ok
+}
+
+// selectItems returns the items of accountId matched by selector and an error
+// detail for every requested id that does not exist; the caller holds mu
+func (s *Server) selectItems(store map[int64]Item, selector string, accountId int64) ([]Item, []map[string]interface{}, error) {
+	var matched []Item
+	var missing []map[string]interface{}
+
+	switch {
+	case selector == "all":
+		for _, item := range sortedItems(store) {
+			if item.AccountId() == accountId {
+				matched = append(matched, item)
+			}
+		}
+
+	case strings.HasPrefix(selector, "{"):
+		expr, err := parseExpression(selector)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, item := range sortedItems(store) {
+			if item.AccountId() == accountId && expr.matches(item) {
+				matched = append(matched, item)
+			}
+		}
+
+	default:
+		for _, part := range strings.Split(selector, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid selector %q", selector)
+			}
+
+			item, ok := store[id]
+			if !ok || item.AccountId() != accountId {
+				missing = append(missing, map[string]interface{}{"id": id, "reason": "item not found"})
+				continue
+			}
+			matched = append(matched, item)
+		}
+	}
+
+	return matched, missing, nil
+}
+
+func writeResult(w http.ResponseWriter, r *http.Request, items []Item, errors []map[string]interface{}) {
+	var fields []string
+	if raw := r.URL.Query().Get("fields"); raw != "" {
+		fields = strings.Split(raw, ",")
+	}
+
+	result := make([]Item, 0, len(items))
+	for _, item := range items {
+		result = append(result, project(item, fields))
+	}
+
+	writeJSON(w, http.StatusOK, result, errors)
+}
+
+func writeJSON(w http.ResponseWriter, status int, result []Item, errors []map[string]interface{}) {
+	response := map[string]interface{}{"result": result}
+	if len(errors) > 0 {
+		response["errors"] = errors
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func writeError(w http.ResponseWriter, status int, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []map[string]interface{}{{"reason": reason}},
+	})
+}
+
+// project keeps the top-level keys named in fields (all if empty) and copies the item
+func project(item Item, fields []string) Item {
+	if len(fields) == 0 {
+		return copyItem(item)
+	}
+
+	projected := Item{}
+	for _, field := range fields {
+		key := strings.SplitN(field, ".", 2)[0]
+		if value, ok := item[key]; ok {
+			projected[key] = value
+		}
+	}
+	return projected
+}
+
+func decodeItem(data []byte) (Item, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var item Item
+	if err := decoder.Decode(&item); err != nil || item == nil {
+		return nil, fmt.Errorf("items must be JSON objects")
+	}
+	return item, nil
+}
+
+func copyItem(item Item) Item {
+	data, _ := json.Marshal(item)
+	copied, _ := decodeItem(data)
+	return copied
+}
+
+func sortedItems(store map[int64]Item) []Item {
+	items := make([]Item, 0, len(store))
+	for _, item := range store {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Id() < items[j].Id() })
+	return items
+}
+
+func randomKey() string {
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
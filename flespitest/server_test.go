@@ -0,0 +1,248 @@
+package flespitest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	flespi "github.com/mixser/flespi-client"
+	flespi_device "github.com/mixser/flespi-client/resources/gateway/device"
+	flespi_token "github.com/mixser/flespi-client/resources/gateway/token"
+)
+
+func TestServer_CRUD(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client, err := server.Client()
+	if err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+
+	device, err := client.Devices.Create("truck-1", true, 1, flespi_device.WithConfiguration(map[string]string{"ident": "123"}))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if device.Id == 0 || device.AccountId != DefaultAccountId {
+		t.Errorf("Expected an id and cid %d, got %+v", DefaultAccountId, device)
+	}
+
+	device.Name = "truck-2"
+	if _, err := client.Devices.Update(*device); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got, err := client.Devices.Get(device.Id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Name != "truck-2" || got.Configuration["ident"] != "123" {
+		t.Errorf("Expected the updated device, got %+v", got)
+	}
+
+	if err := client.Devices.DeleteById(device.Id); err != nil {
+		t.Fatalf("DeleteById() error = %v", err)
+	}
+
+	if _, err := client.Devices.Get(device.Id); !flespi.IsNotFoundError(err) {
+		t.Errorf("Expected not found after delete, got %v", err)
+	}
+}
+
+func TestServer_Selectors(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.Seed("gw/devices", 0,
+		flespi_device.Device{Name: "truck-1", Metadata: map[string]string{"fleet": "north"}},
+		flespi_device.Device{Name: "truck-2", Metadata: map[string]string{"fleet": "south"}},
+		flespi_device.Device{Name: "van-1", Metadata: map[string]string{"fleet": "north"}},
+	)
+
+	client, _ := server.Client()
+
+	tests := []struct {
+		name     string
+		selector flespi.Selector
+		want     int
+	}{
+		{name: "all", selector: flespi.SelectAll(), want: 3},
+		{name: "ids", selector: flespi.SelectIds(1001, 1003), want: 2},
+		{name: "eq", selector: flespi.SelectWhere(flespi.Eq("metadata.fleet", "north")), want: 2},
+		{name: "like", selector: flespi.SelectWhere(flespi.Like("name", "truck*")), want: 2},
+		{name: "and", selector: flespi.SelectWhere(flespi.Like("name", "truck*"), flespi.NotEq("metadata.fleet", "north")), want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devices, err := client.Devices.ListBySelector(tt.selector)
+			if err != nil {
+				t.Fatalf("ListBySelector() error = %v", err)
+			}
+			if len(devices) != tt.want {
+				t.Errorf("Expected %d devices, got %d", tt.want, len(devices))
+			}
+		})
+	}
+
+	devices, _ := client.Devices.List(flespi.WithFields("id"))
+	if devices[0].Name != "" {
+		t.Errorf("Expected the name to be left out by the fields parameter, got %q", devices[0].Name)
+	}
+
+	result, err := client.Devices.DeleteBySelector(flespi.SelectWhere(flespi.Eq("metadata.fleet", "north")))
+	if err != nil || len(result.Ids) != 2 {
+		t.Errorf("Expected 2 deleted devices, got %+v (%v)", result, err)
+	}
+	if items := server.Items("gw/devices"); len(items) != 1 {
+		t.Errorf("Expected 1 remaining device, got %d", len(items))
+	}
+}
+
+func TestServer_AccountScoping(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client, _ := server.Client()
+
+	if _, err := client.ForAccount(42).Devices.List(); !flespi.IsForbiddenError(err) {
+		t.Errorf("Expected forbidden for an unknown subaccount, got %v", err)
+	}
+
+	subaccount, err := client.Subaccounts.Create("customer")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	customer := client.ForAccount(subaccount.Id)
+	if _, err := customer.Devices.Create("truck", true, 1); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	own, _ := client.Devices.List()
+	scoped, _ := customer.Devices.List()
+	if len(own) != 0 || len(scoped) != 1 {
+		t.Errorf("Expected the device only in the subaccount, got %d own and %d scoped", len(own), len(scoped))
+	}
+	if scoped[0].AccountId != subaccount.Id {
+		t.Errorf("Expected cid %d, got %d", subaccount.Id, scoped[0].AccountId)
+	}
+
+	requests := server.Requests()
+	if last := requests[len(requests)-1]; last.AccountId != subaccount.Id {
+		t.Errorf("Expected the last request to carry cid %d, got %d", subaccount.Id, last.AccountId)
+	}
+}
+
+func TestServer_Faults(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client, _ := server.Client(flespi.WithRetryConfig(nil))
+
+	server.Inject(Fault{Method: "GET", Path: "gw/devices", StatusCode: http.StatusServiceUnavailable, Times: 1})
+
+	if _, err := client.Devices.List(); !errors.Is(err, flespi.ErrServer) {
+		t.Errorf("Expected the injected server error, got %v", err)
+	}
+	if _, err := client.Devices.List(); err != nil {
+		t.Errorf("Expected the fault to fire once, got %v", err)
+	}
+
+	server.Inject(Fault{Path: "platform/tokens", StatusCode: http.StatusConflict, Reason: "limit exceeded"})
+	if _, err := client.Tokens.Create("test", flespi_token.WithStatus(true)); !flespi.IsLimitExceededError(err) {
+		t.Errorf("Expected the injected limit error, got %v", err)
+	}
+
+	server.ClearFaults()
+	token, err := client.Tokens.Create("test")
+	if err != nil || token.Key == "" {
+		t.Errorf("Expected a token with a key, got %+v (%v)", token, err)
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	server := NewServer()
+	server.Token = "secret"
+	defer server.Close()
+
+	client, _ := flespi.NewClient(server.URL, "wrong")
+	if _, err := client.Devices.List(); !flespi.IsUnauthorizedError(err) {
+		t.Errorf("Expected unauthorized, got %v", err)
+	}
+
+	client, _ = server.Client()
+	if _, err := client.Devices.List(); err != nil {
+		t.Errorf("Expected the configured token to be accepted, got %v", err)
+	}
+}
+
+func TestServer_AuthBeforeFaults(t *testing.T) {
+	server := NewServer()
+	server.Token = "secret"
+	defer server.Close()
+
+	server.Inject(Fault{Method: "GET", Path: "gw/devices", StatusCode: http.StatusServiceUnavailable, Times: 1})
+
+	unauthorised, _ := flespi.NewClient(server.URL, "wrong", flespi.WithRetryConfig(nil))
+	if _, err := unauthorised.Devices.List(); !flespi.IsUnauthorizedError(err) {
+		t.Errorf("Expected unauthorized, got %v", err)
+	}
+
+	client, _ := server.Client(flespi.WithRetryConfig(nil))
+	if _, err := client.Devices.List(); !errors.Is(err, flespi.ErrServer) {
+		t.Errorf("Expected the fault to be left for the authorised request, got %v", err)
+	}
+}
+
+func TestServer_SeedCollision(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	if _, err := server.Seed("gw/devices", 0, map[string]interface{}{"id": 5, "name": "a"}); err != nil {
+		t.Fatalf("Seed() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		items []interface{}
+	}{
+		{"stored id", []interface{}{map[string]interface{}{"id": 5, "name": "b"}}},
+		{"id repeated in the call", []interface{}{
+			map[string]interface{}{"id": 6, "name": "c"},
+			map[string]interface{}{"id": 6, "name": "d"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := server.Seed("gw/devices", 0, tt.items...); err == nil {
+				t.Error("Expected an error for a colliding id")
+			}
+		})
+	}
+
+	items := server.Items("gw/devices")
+	if len(items) != 1 || items[0]["name"] != "a" {
+		t.Errorf("Expected only the first device to be stored, got %v", items)
+	}
+}
+
+func TestServer_Handle(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.Handle("GET /gw/devices/{id}/telemetry/all", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result": [{"id": 1, "telemetry": {}}]}`))
+	}))
+
+	client, _ := server.Client()
+
+	var response map[string]interface{}
+	if err := client.RequestAPI("GET", "gw/devices/1/telemetry/all", nil, &response); err != nil {
+		t.Errorf("Expected the custom handler to answer, got %v", err)
+	}
+	if err := client.RequestAPI("GET", "gw/devices/1/messages", nil, nil); !flespi.IsNotFoundError(err) {
+		t.Errorf("Expected not found for an unknown endpoint, got %v", err)
+	}
+}
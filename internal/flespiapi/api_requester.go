@@ -11,3 +11,10 @@ type APIRequester interface {
 	RequestAPIWithHeaders(method, endpoint string, headers map[string]string, payload, response interface{}) error
 	RequestAPIWithContextAndHeaders(ctx context.Context, method, endpoint string, headers map[string]string, payload, response interface{}) error
 }
+
+// RawBody is a payload sent as is instead of being marshalled to JSON, e.g. a
+// multipart file upload. Data is held in memory so a retried request can send it again.
+type RawBody struct {
+	ContentType string
+	Data        []byte
+}
@@ -0,0 +1,162 @@
+package flespiapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Sentinel errors describing what went wrong, independent of the transport.
+// Match them with errors.Is; *APIError, *PartialError and the validation errors
+// of the resource packages all report the matching sentinel.
+var (
+	// ErrNotFound: the item does not exist or is not visible to the token (404, empty result)
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnauthorized: the token is missing, invalid or expired (401)
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrForbidden: the token's ACL does not allow the operation (403)
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrValidation: the request was rejected as malformed, by flespi (400) or before sending it
+	ErrValidation = errors.New("validation failed")
+
+	// ErrConflict: the request conflicts with the current state of the item (409)
+	ErrConflict = errors.New("conflict")
+
+	// ErrRateLimited: too many requests, retry later (429)
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrLimitExceeded: an account limit (devices, channels, storage, ...) has been reached
+	ErrLimitExceeded = errors.New("account limit exceeded")
+
+	// ErrServer: flespi failed to process the request (5xx)
+	ErrServer = errors.New("server error")
+)
+
+var (
+	codesMu sync.RWMutex
+	codes   = map[int64]error{}
+)
+
+// RegisterErrorCode maps a flespi error code to one of the sentinel errors, so
+// that details carrying that code classify accordingly. Codes take precedence
+// over the HTTP status and the reason text.
+func RegisterErrorCode(code int64, kind error) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	codes[code] = kind
+}
+
+func lookupCode(code int64) error {
+	if code == 0 {
+		return nil
+	}
+
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	return codes[code]
+}
+
+// Kind returns the sentinel error matching detail, or nil when it cannot be classified
+func (d ErrorDetail) Kind() error {
+	if kind := lookupCode(d.Code); kind != nil {
+		return kind
+	}
+
+	return kindFromReason(d.Reason)
+}
+
+// kindFromReason recognises the few reasons flespi phrases consistently
+func kindFromReason(reason string) error {
+	reason = strings.ToLower(reason)
+
+	switch {
+	case strings.Contains(reason, "limit") && (strings.Contains(reason, "exceed") || strings.Contains(reason, "reached")):
+		return ErrLimitExceeded
+	case strings.Contains(reason, "access denied"), strings.Contains(reason, "not allowed"):
+		return ErrForbidden
+	case strings.Contains(reason, "not found"):
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// KindOf classifies an error response by its details and HTTP status; it returns
+// nil for statuses that do not map to a sentinel.
+//
+// Error responses carry no item context, so a detail's ID is treated as an error
+// code there when Code is not set.
+func KindOf(statusCode int, details []ErrorDetail) error {
+	for _, detail := range details {
+		code := detail.Code
+		if code == 0 {
+			code = detail.ID
+		}
+		if kind := lookupCode(code); kind != nil {
+			return kind
+		}
+	}
+
+	// a 429 is throttling even when its reason mentions a "rate limit exceeded"
+	if statusCode == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+
+	for _, detail := range details {
+		// a limit hit is reported with various statuses, the reason is more telling
+		if kind := kindFromReason(detail.Reason); kind == ErrLimitExceeded {
+			return kind
+		}
+	}
+
+	switch {
+	case statusCode == http.StatusBadRequest, statusCode == http.StatusUnprocessableEntity:
+		return ErrValidation
+	case statusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case statusCode == http.StatusForbidden:
+		return ErrForbidden
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+	case statusCode == http.StatusConflict:
+		return ErrConflict
+	case statusCode >= 500:
+		return ErrServer
+	}
+
+	return nil
+}
+
+// Is reports whether any of the rejected items matches target, e.g.
+// errors.Is(err, ErrLimitExceeded) after a bulk create.
+func (e *PartialError) Is(target error) bool {
+	for _, detail := range e.Errors {
+		if detail.Kind() == target {
+			return true
+		}
+	}
+	return false
+}
+
+// validationError is an argument check that failed before a request was sent
+type validationError struct {
+	msg string
+}
+
+func (e *validationError) Error() string {
+	return e.msg
+}
+
+func (e *validationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Invalid returns an error matching ErrValidation with the formatted message.
+func Invalid(format string, args ...interface{}) error {
+	return &validationError{msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,57 @@
+package flespiapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		details    []ErrorDetail
+		expected   error
+	}{
+		{"bad request", 400, nil, ErrValidation},
+		{"forbidden", 403, nil, ErrForbidden},
+		{"conflict", 409, nil, ErrConflict},
+		{"limit reason wins over status", 400, []ErrorDetail{{Reason: "Devices limit reached"}}, ErrLimitExceeded},
+		{"rate limit reason keeps 429 a rate limit", 429, []ErrorDetail{{Reason: "Rate limit exceeded"}}, ErrRateLimited},
+		{"server", 500, nil, ErrServer},
+		{"unclassified", 418, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KindOf(tt.statusCode, tt.details); got != tt.expected {
+				t.Errorf("KindOf() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestKindOf_ErrorCode(t *testing.T) {
+	RegisterErrorCode(990002, ErrLimitExceeded)
+
+	// error responses have no item context, so ID doubles as the code
+	if got := KindOf(400, []ErrorDetail{{Reason: "oops", ID: 990002}}); got != ErrLimitExceeded {
+		t.Errorf("Expected ErrLimitExceeded, got %v", got)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	err := Invalid("ID must be provided")
+
+	if err.Error() != "ID must be provided" {
+		t.Errorf("Expected message to be kept, got %q", err.Error())
+	}
+	if !errors.Is(err, ErrValidation) {
+		t.Error("Expected error to match ErrValidation")
+	}
+}
+
+func TestErrNoResult_IsNotFound(t *testing.T) {
+	if !errors.Is(ErrNoResult, ErrNotFound) {
+		t.Error("Expected ErrNoResult to match ErrNotFound")
+	}
+}
@@ -0,0 +1,245 @@
+package flespiapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StreamRequester is implemented by requesters that can hand out the response
+// body unread, so large responses can be decoded while they arrive.
+type StreamRequester interface {
+	RequestAPIStream(ctx context.Context, method, endpoint string, headers map[string]string, payload interface{}) (io.ReadCloser, error)
+}
+
+// StreamResponse is a successful response with its body left unread; the caller must close Body.
+type StreamResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       io.ReadCloser
+}
+
+// ResponseStreamer is implemented by requesters that can hand out the status and
+// headers of a response along with its unread body, e.g. to check that a Range
+// request was answered with 206 Partial Content.
+type ResponseStreamer interface {
+	RequestAPIResponse(ctx context.Context, method, endpoint string, headers map[string]string, payload interface{}) (*StreamResponse, error)
+}
+
+// Iterator decodes the items of a list response one at a time.
+//
+// Only the current item is held in memory when the requester implements
+// StreamRequester; otherwise the body is read at once and the items are still
+// decoded lazily. Close releases the response and may be called at any point
+// to stop early.
+//
+//	it := flespi_device.IterateDevices(client, flespiapi.SelectAll())
+//	defer it.Close()
+//	for it.Next() {
+//	    device := it.Value()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type Iterator[T any] struct {
+	ctx      context.Context
+	c        APIRequester
+	endpoint string
+	decode   func(json.RawMessage) (T, error)
+
+	body     io.ReadCloser
+	decoder  *json.Decoder
+	started  bool
+	inResult bool
+	done     bool
+
+	current T
+	errors  []ErrorDetail
+	err     error
+}
+
+// NewIterator prepares an iterator over the "result" array of a GET endpoint; no
+// request is made until the first call to Next. decode converts a single item and
+// may be nil, in which case items are decoded with json.Unmarshal.
+func NewIterator[T any](ctx context.Context, c APIRequester, endpoint string, decode func(json.RawMessage) (T, error)) *Iterator[T] {
+	if decode == nil {
+		decode = func(raw json.RawMessage) (T, error) {
+			var item T
+			err := json.Unmarshal(raw, &item)
+			return item, err
+		}
+	}
+
+	return &Iterator[T]{ctx: ctx, c: c, endpoint: endpoint, decode: decode}
+}
+
+// FailedIterator returns an iterator that yields nothing and reports err,
+// for arguments rejected before any request is made.
+func FailedIterator[T any](err error) *Iterator[T] {
+	return &Iterator[T]{err: err, done: true}
+}
+
+// Next advances to the next item and reports whether there is one.
+// It returns false at the end of the result or on error; see Err.
+func (it *Iterator[T]) Next() bool {
+	if it.done {
+		return false
+	}
+
+	if !it.started {
+		it.started = true
+		if err := it.open(); err != nil {
+			return it.fail(err)
+		}
+	}
+
+	if !it.inResult {
+		found, err := it.seekResult()
+		if err != nil {
+			return it.fail(err)
+		}
+		if !found {
+			it.Close()
+			return false
+		}
+	}
+
+	if !it.decoder.More() {
+		// closing bracket of the result array
+		if _, err := it.decoder.Token(); err != nil {
+			return it.fail(err)
+		}
+		it.inResult = false
+
+		// errors may follow the result
+		if _, err := it.seekResult(); err != nil {
+			return it.fail(err)
+		}
+		it.Close()
+		return false
+	}
+
+	var raw json.RawMessage
+	if err := it.decoder.Decode(&raw); err != nil {
+		return it.fail(err)
+	}
+
+	item, err := it.decode(raw)
+	if err != nil {
+		return it.fail(err)
+	}
+
+	it.current = item
+	return true
+}
+
+// Value returns the item Next advanced to.
+func (it *Iterator[T]) Value() T {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any. Once the whole
+// response has been read, per-item errors reported by flespi are returned as a
+// *PartialError.
+func (it *Iterator[T]) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	if it.done && len(it.errors) > 0 {
+		return &PartialError{Errors: it.errors}
+	}
+	return nil
+}
+
+// Close releases the response body; it is safe to call more than once.
+func (it *Iterator[T]) Close() error {
+	it.done = true
+	if it.body == nil {
+		return nil
+	}
+
+	body := it.body
+	it.body = nil
+	return body.Close()
+}
+
+func (it *Iterator[T]) open() error {
+	if streamer, ok := it.c.(StreamRequester); ok {
+		body, err := streamer.RequestAPIStream(it.ctx, "GET", it.endpoint, nil, nil)
+		if err != nil {
+			return err
+		}
+		it.body = body
+	} else {
+		var raw json.RawMessage
+		if err := it.c.RequestAPIWithContext(it.ctx, "GET", it.endpoint, nil, &raw); err != nil {
+			return err
+		}
+		it.body = io.NopCloser(bytes.NewReader(raw))
+	}
+
+	it.decoder = json.NewDecoder(it.body)
+
+	token, err := it.decoder.Token()
+	if err == io.EOF {
+		// empty body, nothing to iterate
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("unexpected response: expected a JSON object, got %v", token)
+	}
+
+	return nil
+}
+
+// seekResult reads object members until the "result" array starts (true) or the
+// object ends (false), collecting "errors" and skipping anything else
+func (it *Iterator[T]) seekResult() (bool, error) {
+	for it.decoder.More() {
+		token, err := it.decoder.Token()
+		if err != nil {
+			return false, err
+		}
+
+		key, _ := token.(string)
+
+		switch key {
+		case "result":
+			token, err := it.decoder.Token()
+			if err != nil {
+				return false, err
+			}
+			if token == nil {
+				continue
+			}
+			if delim, ok := token.(json.Delim); !ok || delim != '[' {
+				return false, fmt.Errorf("unexpected response: expected result array, got %v", token)
+			}
+			it.inResult = true
+			return true, nil
+		case "errors":
+			var errs []ErrorDetail
+			if err := it.decoder.Decode(&errs); err != nil {
+				return false, err
+			}
+			it.errors = append(it.errors, errs...)
+		default:
+			var skip json.RawMessage
+			if err := it.decoder.Decode(&skip); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (it *Iterator[T]) fail(err error) bool {
+	it.err = err
+	it.Close()
+	return false
+}
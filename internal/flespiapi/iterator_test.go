@@ -0,0 +1,147 @@
+package flespiapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type item struct {
+	Id int64 `json:"id"`
+}
+
+// fakeRequester serves a fixed body; streaming is optional to exercise both paths
+type fakeRequester struct {
+	body   string
+	closed bool
+}
+
+func (f *fakeRequester) RequestAPI(method, endpoint string, payload, response interface{}) error {
+	return f.RequestAPIWithContextAndHeaders(context.Background(), method, endpoint, nil, payload, response)
+}
+
+func (f *fakeRequester) RequestAPIWithContext(ctx context.Context, method, endpoint string, payload, response interface{}) error {
+	return f.RequestAPIWithContextAndHeaders(ctx, method, endpoint, nil, payload, response)
+}
+
+func (f *fakeRequester) RequestAPIWithHeaders(method, endpoint string, headers map[string]string, payload, response interface{}) error {
+	return f.RequestAPIWithContextAndHeaders(context.Background(), method, endpoint, headers, payload, response)
+}
+
+func (f *fakeRequester) RequestAPIWithContextAndHeaders(ctx context.Context, method, endpoint string, headers map[string]string, payload, response interface{}) error {
+	return json.Unmarshal([]byte(f.body), response)
+}
+
+type fakeStreamer struct {
+	fakeRequester
+}
+
+func (f *fakeStreamer) RequestAPIStream(ctx context.Context, method, endpoint string, headers map[string]string, payload interface{}) (io.ReadCloser, error) {
+	return &closeTracker{Reader: strings.NewReader(f.body), closed: &f.closed}, nil
+}
+
+type closeTracker struct {
+	io.Reader
+	closed *bool
+}
+
+func (c *closeTracker) Close() error {
+	*c.closed = true
+	return nil
+}
+
+func collect(it *Iterator[item]) []int64 {
+	var ids []int64
+	for it.Next() {
+		ids = append(ids, it.Value().Id)
+	}
+	return ids
+}
+
+func TestIterator(t *testing.T) {
+	body := `{"meta":{"x":[1,2]},"result":[{"id":1},{"id":2},{"id":3}],"errors":[{"reason":"hidden","id":4}]}`
+
+	requesters := map[string]APIRequester{
+		"streaming": &fakeStreamer{fakeRequester{body: body}},
+		"fallback":  &fakeRequester{body: body},
+	}
+
+	for name, requester := range requesters {
+		t.Run(name, func(t *testing.T) {
+			it := NewIterator[item](context.Background(), requester, "gw/devices/all", nil)
+
+			ids := collect(it)
+			if len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+				t.Errorf("Expected ids [1 2 3], got %v", ids)
+			}
+
+			var partialErr *PartialError
+			if !errors.As(it.Err(), &partialErr) || partialErr.Errors[0].ID != 4 {
+				t.Errorf("Expected a PartialError for item 4, got %v", it.Err())
+			}
+		})
+	}
+}
+
+func TestIterator_EarlyClose(t *testing.T) {
+	requester := &fakeStreamer{fakeRequester{body: `{"result":[{"id":1},{"id":2},{"id":3}]}`}}
+
+	it := NewIterator[item](context.Background(), requester, "gw/devices/all", nil)
+
+	if !it.Next() || it.Value().Id != 1 {
+		t.Fatalf("Expected the first item, got %v", it.Value())
+	}
+
+	it.Close()
+
+	if it.Next() {
+		t.Error("Expected Next to return false after Close")
+	}
+	if !requester.closed {
+		t.Error("Expected the response body to be closed")
+	}
+	if it.Err() != nil {
+		t.Errorf("Expected no error, got %v", it.Err())
+	}
+}
+
+func TestIterator_EmptyAndErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"empty result", `{"result":[]}`, false},
+		{"null result", `{"result":null}`, false},
+		{"empty body", ``, false},
+		{"not an object", `[1,2]`, true},
+		{"bad item", `{"result":[{"id":"x"}]}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := NewIterator[item](context.Background(), &fakeStreamer{fakeRequester{body: tt.body}}, "x", nil)
+
+			if ids := collect(it); len(ids) != 0 {
+				t.Errorf("Expected no items, got %v", ids)
+			}
+			if (it.Err() != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, it.Err())
+			}
+		})
+	}
+}
+
+func TestFailedIterator(t *testing.T) {
+	it := FailedIterator[item](Invalid("selector must not be empty"))
+
+	if it.Next() {
+		t.Error("Expected no items")
+	}
+	if !errors.Is(it.Err(), ErrValidation) {
+		t.Errorf("Expected a validation error, got %v", it.Err())
+	}
+}
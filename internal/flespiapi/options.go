@@ -0,0 +1,58 @@
+package flespiapi
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RequestOption tunes a single Get or List call of a resource package.
+type RequestOption func(*RequestOptions)
+
+// RequestOptions holds the values collected from RequestOption functions.
+type RequestOptions struct {
+	// Fields limits the response to the named fields (flespi "fields" parameter).
+	Fields []string
+}
+
+// WithFields asks flespi to return only the given fields, e.g. WithFields("id", "name", "cid").
+// Fields that are not returned keep their zero value in the decoded objects.
+func WithFields(fields ...string) RequestOption {
+	return func(o *RequestOptions) {
+		o.Fields = append(o.Fields, fields...)
+	}
+}
+
+// NewRequestOptions applies options in order and returns the result.
+func NewRequestOptions(options ...RequestOption) RequestOptions {
+	var o RequestOptions
+	for _, opt := range options {
+		opt(&o)
+	}
+	return o
+}
+
+// Endpoint appends the query string described by options to endpoint.
+// defaultFields is sent when the caller did not pass WithFields; nil means all fields.
+func Endpoint(endpoint string, defaultFields []string, options ...RequestOption) string {
+	o := NewRequestOptions(options...)
+
+	fields := o.Fields
+	if len(fields) == 0 {
+		fields = defaultFields
+	}
+
+	if len(fields) == 0 {
+		return endpoint
+	}
+
+	query := url.Values{}
+	query.Set("fields", strings.Join(fields, ","))
+
+	separator := "?"
+	if strings.Contains(endpoint, "?") {
+		separator = "&"
+	}
+
+	// keep the commas readable, flespi accepts them unescaped
+	return endpoint + separator + strings.ReplaceAll(query.Encode(), "%2C", ",")
+}
@@ -0,0 +1,26 @@
+package flespiapi
+
+import "testing"
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		name          string
+		endpoint      string
+		defaultFields []string
+		options       []RequestOption
+		expected      string
+	}{
+		{"no fields", "gw/devices/all", nil, nil, "gw/devices/all"},
+		{"default fields", "gw/devices/1", []string{"id", "name"}, nil, "gw/devices/1?fields=id,name"},
+		{"override", "gw/devices/1", []string{"id", "name"}, []RequestOption{WithFields("id", "cid")}, "gw/devices/1?fields=id,cid"},
+		{"existing query", "gw/devices/1?x=1", nil, []RequestOption{WithFields("id")}, "gw/devices/1?x=1&fields=id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Endpoint(tt.endpoint, tt.defaultFields, tt.options...); got != tt.expected {
+				t.Errorf("Endpoint() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package flespiapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ErrorDetail represents a single error from the Flespi API response
+type ErrorDetail struct {
+	Reason string `json:"reason"`
+	ID     int64  `json:"id,omitempty"`
+	Code   int64  `json:"code,omitempty"`
+}
+
+// ErrNoResult is returned by single-object calls when flespi answered with
+// a successful status but an empty result and no errors. It matches ErrNotFound.
+var ErrNoResult = fmt.Errorf("flespi returned an empty result (%w)", ErrNotFound)
+
+// PartialError carries the per-item errors flespi reported in a successful (2xx) response.
+type PartialError struct {
+	Errors []ErrorDetail
+}
+
+// Error implements the error interface
+func (e *PartialError) Error() string {
+	reasons := make([]string, 0, len(e.Errors))
+	for _, detail := range e.Errors {
+		if detail.ID != 0 {
+			reasons = append(reasons, fmt.Sprintf("%s (id %d)", detail.Reason, detail.ID))
+		} else {
+			reasons = append(reasons, detail.Reason)
+		}
+	}
+	return fmt.Sprintf("flespi reported %d item error(s): %s", len(e.Errors), strings.Join(reasons, "; "))
+}
+
+// EmptyResultError explains why a single-object response carried no item:
+// a *PartialError when flespi reported errors, ErrNoResult otherwise.
+func EmptyResultError(errs []ErrorDetail) error {
+	if len(errs) > 0 {
+		return &PartialError{Errors: errs}
+	}
+	return ErrNoResult
+}
+
+// First returns the only item of a single-object response without panicking on
+// an empty result; see EmptyResultError for the error returned in that case.
+func First[T any](items []T, errs []ErrorDetail) (*T, error) {
+	if len(items) == 0 {
+		return nil, EmptyResultError(errs)
+	}
+	return &items[0], nil
+}
+
+// MultiResult is the outcome of a request that touches several items at once:
+// the items flespi returned and the per-item errors it reported alongside them.
+type MultiResult[T any] struct {
+	Items  []T
+	Ids    []int64
+	Errors []ErrorDetail
+}
+
+// HasErrors reports whether flespi rejected any of the items.
+func (r *MultiResult[T]) HasErrors() bool {
+	return len(r.Errors) > 0
+}
+
+// Err returns a *PartialError describing the rejected items, or nil when there are none.
+func (r *MultiResult[T]) Err() error {
+	if !r.HasErrors() {
+		return nil
+	}
+	return &PartialError{Errors: r.Errors}
+}
+
+// Append adds the items and errors of one response to the result.
+// id extracts the item id that is recorded in Ids.
+func (r *MultiResult[T]) Append(items []T, errors []ErrorDetail, id func(T) int64) {
+	for _, item := range items {
+		r.Items = append(r.Items, item)
+		r.Ids = append(r.Ids, id(item))
+	}
+	r.Errors = append(r.Errors, errors...)
+}
+
+// DeleteResult is the outcome of a bulk delete: the ids flespi removed and the
+// per-item errors for the ones it could not.
+type DeleteResult struct {
+	Ids    []int64
+	Errors []ErrorDetail
+}
+
+// HasErrors reports whether flespi failed to delete any of the selected items.
+func (r *DeleteResult) HasErrors() bool {
+	return len(r.Errors) > 0
+}
+
+// Err returns a *PartialError describing the items that were not deleted, or nil.
+func (r *DeleteResult) Err() error {
+	if !r.HasErrors() {
+		return nil
+	}
+	return &PartialError{Errors: r.Errors}
+}
+
+// UnmarshalJSON decodes the {"result": [{"id": 1}, ...], "errors": [...]} body
+// flespi returns for DELETE requests.
+func (r *DeleteResult) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Result []struct {
+			Id int64 `json:"id"`
+		} `json:"result"`
+		Errors []ErrorDetail `json:"errors"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	r.Ids = r.Ids[:0]
+	for _, item := range raw.Result {
+		r.Ids = append(r.Ids, item.Id)
+	}
+	r.Errors = raw.Errors
+
+	return nil
+}
+
+// GroupByAccount splits items by the subaccount returned by accountId, keeping
+// the input order inside each group. Resource packages use it to send one
+// request per x-flespi-cid value.
+func GroupByAccount[T any](items []T, accountId func(T) int64) ([]int64, map[int64][]T) {
+	var accounts []int64
+	groups := make(map[int64][]T)
+
+	for _, item := range items {
+		id := accountId(item)
+		if _, ok := groups[id]; !ok {
+			accounts = append(accounts, id)
+		}
+		groups[id] = append(groups[id], item)
+	}
+
+	return accounts, groups
+}
@@ -0,0 +1,23 @@
+package flespiapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFirst(t *testing.T) {
+	item, err := First([]int{7, 8}, nil)
+	if err != nil || *item != 7 {
+		t.Errorf("First() = %v, %v", item, err)
+	}
+
+	if _, err := First([]int{}, nil); !errors.Is(err, ErrNoResult) {
+		t.Errorf("Expected ErrNoResult, got %v", err)
+	}
+
+	_, err = First([]int{}, []ErrorDetail{{Reason: "invalid ident", ID: 3}})
+	var partialErr *PartialError
+	if !errors.As(err, &partialErr) || partialErr.Errors[0].ID != 3 {
+		t.Errorf("Expected *PartialError, got %v", err)
+	}
+}
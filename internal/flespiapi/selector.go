@@ -0,0 +1,120 @@
+package flespiapi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Selector identifies a set of flespi items in a REST API URI.
+//
+// flespi accepts the keyword "all", a comma-separated list of ids (1,5,9) or an
+// expression enclosed in curly braces ({name=truck*}, {metadata.fleet="north"}).
+// The zero value selects nothing and is rejected by the resource packages.
+type Selector struct {
+	expr string
+}
+
+// SelectAll selects every item of a collection.
+func SelectAll() Selector {
+	return Selector{expr: "all"}
+}
+
+// SelectIds selects items by their numeric ids.
+func SelectIds(ids ...int64) Selector {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+
+	return Selector{expr: strings.Join(parts, ",")}
+}
+
+// SelectExpr selects items with a raw flespi expression, e.g. "name=truck*".
+// Surrounding curly braces are added when missing.
+func SelectExpr(expr string) Selector {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return Selector{}
+	}
+
+	if !strings.HasPrefix(expr, "{") || !strings.HasSuffix(expr, "}") {
+		expr = "{" + expr + "}"
+	}
+
+	return Selector{expr: expr}
+}
+
+// SelectWhere builds an expression selector from conditions; all of them must match.
+func SelectWhere(conditions ...Condition) Selector {
+	parts := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		parts = append(parts, condition.String())
+	}
+
+	return SelectExpr(strings.Join(parts, ","))
+}
+
+// String returns the selector as it appears in flespi URIs.
+func (s Selector) String() string {
+	return s.expr
+}
+
+// IsZero reports whether the selector selects nothing.
+func (s Selector) IsZero() bool {
+	return s.expr == ""
+}
+
+// Path returns the selector escaped for use as a URI path segment.
+func (s Selector) Path() (string, error) {
+	if s.IsZero() {
+		return "", Invalid("selector must not be empty")
+	}
+
+	// "all" and id lists are plain path segments, expressions need escaping
+	if !strings.HasPrefix(s.expr, "{") {
+		return s.expr, nil
+	}
+
+	return url.PathEscape(s.expr), nil
+}
+
+// Condition is a single field comparison inside an expression selector.
+type Condition struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+// String returns the condition in flespi expression syntax.
+func (c Condition) String() string {
+	return c.Field + c.Operator + c.Value
+}
+
+// Eq matches items whose field equals value. Strings are quoted, so they are
+// compared literally; use Like for wildcard patterns.
+func Eq(field string, value interface{}) Condition {
+	return Condition{Field: field, Operator: "=", Value: formatValue(value)}
+}
+
+// NotEq matches items whose field differs from value.
+func NotEq(field string, value interface{}) Condition {
+	return Condition{Field: field, Operator: "!=", Value: formatValue(value)}
+}
+
+// Like matches items whose field matches a pattern with * wildcards, e.g. "truck*".
+func Like(field string, pattern string) Condition {
+	return Condition{Field: field, Operator: "=", Value: pattern}
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case fmt.Stringer:
+		return strconv.Quote(v.String())
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
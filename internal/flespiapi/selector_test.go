@@ -0,0 +1,48 @@
+package flespiapi
+
+import "testing"
+
+func TestSelector_Path(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector Selector
+		expected string
+	}{
+		{"all", SelectAll(), "all"},
+		{"ids", SelectIds(1, 5, 9), "1,5,9"},
+		{"expression", SelectExpr("name=truck*"), "%7Bname=truck%2A%7D"},
+		{"expression with braces", SelectExpr("{name=truck*}"), "%7Bname=truck%2A%7D"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := tt.selector.Path()
+			if err != nil {
+				t.Fatalf("Path() error = %v", err)
+			}
+			if path != tt.expected {
+				t.Errorf("Path() = %q, want %q", path, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSelector_Empty(t *testing.T) {
+	for _, selector := range []Selector{{}, SelectIds(), SelectExpr("  "), SelectWhere()} {
+		if !selector.IsZero() {
+			t.Errorf("expected %q to be empty", selector.String())
+		}
+		if _, err := selector.Path(); err == nil {
+			t.Errorf("expected error for empty selector")
+		}
+	}
+}
+
+func TestSelectWhere(t *testing.T) {
+	selector := SelectWhere(Like("name", "truck*"), Eq("metadata.fleet", "north"), NotEq("enabled", false))
+
+	expected := `{name=truck*,metadata.fleet="north",enabled!=false}`
+	if selector.String() != expected {
+		t.Errorf("SelectWhere() = %q, want %q", selector.String(), expected)
+	}
+}
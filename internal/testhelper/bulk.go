@@ -0,0 +1,122 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
+
+// BulkRequest is a bulk create request received by a BulkServer
+type BulkRequest struct {
+	// AccountId is the x-flespi-cid header, empty if absent
+	AccountId string
+
+	Items []map[string]interface{}
+}
+
+// BulkServer answers bulk create requests by echoing the items back with ids
+// 1, 2, ... and records what it received. Handlers run outside the test
+// goroutine, so a malformed body is reported with t.Errorf and a 400.
+type BulkServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []BulkRequest
+}
+
+// NewBulkServer starts a BulkServer; the caller closes it
+func NewBulkServer(t *testing.T) *BulkServer {
+	s := &BulkServer{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var items []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+			t.Errorf("Expected a JSON array of items, got %v", err)
+			http.Error(w, `{"errors": [{"reason": "invalid payload"}]}`, http.StatusBadRequest)
+			return
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, BulkRequest{AccountId: r.Header.Get("x-flespi-cid"), Items: items})
+		s.mu.Unlock()
+
+		created := make([]map[string]interface{}, len(items))
+		for i, item := range items {
+			created[i] = make(map[string]interface{}, len(item)+1)
+			for key, value := range item {
+				created[i][key] = value
+			}
+			created[i]["id"] = i + 1
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": created})
+	}))
+
+	return s
+}
+
+// Requests returns the requests received so far
+func (s *BulkServer) Requests() []BulkRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]BulkRequest(nil), s.requests...)
+}
+
+// BulkCreate calls a New*s function with one item per id, each carrying that
+// id, and returns how many items were created and the ids left on the input
+type BulkCreate func(c flespiapi.APIRequester, ids []int64) (created int, inputIds []int64, err error)
+
+// CheckBulkCreate runs the cases every New*s function must pass: an empty input
+// sends no request, and ids set on the input are neither sent, nor cleared on
+// the caller's items. Neither are account ids, which travel in x-flespi-cid.
+func CheckBulkCreate(t *testing.T, create BulkCreate) {
+	t.Helper()
+
+	tests := []struct {
+		name     string
+		ids      []int64
+		requests int
+	}{
+		{"empty input sends no request", nil, 0},
+		{"ids are not sent", []int64{5, 6}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewBulkServer(t)
+			defer server.Close()
+
+			created, inputIds, err := create(New(server.URL), tt.ids)
+			if err != nil {
+				t.Fatalf("bulk create error = %v", err)
+			}
+
+			requests := server.Requests()
+			if len(requests) != tt.requests {
+				t.Errorf("Expected %d requests, got %d", tt.requests, len(requests))
+			}
+			for _, request := range requests {
+				for _, item := range request.Items {
+					if _, ok := item["id"]; ok {
+						t.Errorf("id must not be sent in the body, got %v", item)
+					}
+					if _, ok := item["cid"]; ok {
+						t.Errorf("cid must not be sent in the body, got %v", item)
+					}
+				}
+			}
+
+			if created != len(tt.ids) {
+				t.Errorf("Expected %d created items, got %d", len(tt.ids), created)
+			}
+			if len(tt.ids) > 0 && !reflect.DeepEqual(inputIds, tt.ids) {
+				t.Errorf("Expected the input ids %v to be left unchanged, got %v", tt.ids, inputIds)
+			}
+		})
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
 // TestClient is a minimal APIRequester implementation for use in tests.
@@ -39,22 +41,54 @@ func (c *TestClient) RequestAPIWithHeaders(method, endpoint string, headers map[
 	return c.RequestAPIWithContextAndHeaders(context.Background(), method, endpoint, headers, payload, response)
 }
 
-func (c *TestClient) RequestAPIWithContextAndHeaders(_ context.Context, method, endpoint string, headers map[string]string, payload, response interface{}) error {
+func (c *TestClient) RequestAPIWithContextAndHeaders(ctx context.Context, method, endpoint string, headers map[string]string, payload, response interface{}) error {
+	body, err := c.RequestAPIStream(ctx, method, endpoint, headers, payload)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	respBody, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	if response != nil && len(respBody) > 0 {
+		return json.Unmarshal(respBody, response)
+	}
+	return nil
+}
+
+// RequestAPIStream returns the body of a successful response unread, like the real client.
+func (c *TestClient) RequestAPIStream(ctx context.Context, method, endpoint string, headers map[string]string, payload interface{}) (io.ReadCloser, error) {
+	res, err := c.RequestAPIResponse(ctx, method, endpoint, headers, payload)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// RequestAPIResponse returns the status, headers and unread body of a successful response.
+func (c *TestClient) RequestAPIResponse(ctx context.Context, method, endpoint string, headers map[string]string, payload interface{}) (*flespiapi.StreamResponse, error) {
 	var body io.Reader
-	if payload != nil {
+	contentType := "application/json"
+	if raw, ok := payload.(flespiapi.RawBody); ok {
+		body = bytes.NewReader(raw.Data)
+		contentType = raw.ContentType
+	} else if payload != nil {
 		data, err := json.Marshal(payload)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		body = bytes.NewBuffer(data)
 	}
 
-	req, err := http.NewRequest(method, fmt.Sprintf("%s/%s", c.baseURL, endpoint), body)
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s", c.baseURL, endpoint), body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("FlespiToken %s", c.token))
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 
 	for k, v := range headers {
 		req.Header.Set(k, v)
@@ -62,21 +96,23 @@ func (c *TestClient) RequestAPIWithContextAndHeaders(_ context.Context, method,
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, respBody)
-	}
+		defer resp.Body.Close()
 
-	if response != nil {
-		return json.Unmarshal(respBody, response)
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		// wrap the sentinel so resource package tests can use errors.Is
+		if kind := flespiapi.KindOf(resp.StatusCode, nil); kind != nil {
+			return nil, fmt.Errorf("HTTP %d: %s: %w", resp.StatusCode, respBody, kind)
+		}
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, respBody)
 	}
-	return nil
+
+	return &flespiapi.StreamResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: resp.Body}, nil
 }
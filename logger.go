@@ -3,6 +3,7 @@ package flespi
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 )
 
@@ -88,19 +89,28 @@ func (l *StdLogger) Errorf(format string, args ...interface{}) {
 	}
 }
 
-// logRequest logs an outgoing HTTP request
-func (c *Client) logRequest(method, endpoint string, payload interface{}) {
+// logRequest logs an outgoing HTTP request; the payload is logged with secrets redacted
+func (c *Client) logRequest(method, endpoint string, payload []byte) {
 	if c.Logger == nil {
 		return
 	}
 
 	if payload != nil {
-		c.Logger.Debugf("Request: %s %s with payload", method, endpoint)
+		c.Logger.Debugf("Request: %s %s with payload %s", method, endpoint, RedactJSON(payload))
 	} else {
 		c.Logger.Debugf("Request: %s %s", method, endpoint)
 	}
 }
 
+// logHeaders logs the headers of a single attempt with credentials redacted
+func (c *Client) logHeaders(method, endpoint string, attempt int, header http.Header) {
+	if c.Logger == nil {
+		return
+	}
+
+	c.Logger.Debugf("Request headers: %s %s (attempt %d): %v", method, endpoint, attempt, RedactHeaders(header))
+}
+
 // logResponse logs an HTTP response
 func (c *Client) logResponse(method, endpoint string, statusCode int, err error) {
 	if c.Logger == nil {
@@ -108,7 +118,11 @@ func (c *Client) logResponse(method, endpoint string, statusCode int, err error)
 	}
 
 	if err != nil {
-		c.Logger.Errorf("Response: %s %s failed - %v", method, endpoint, err)
+		if statusCode != 0 {
+			c.Logger.Errorf("Response: %s %s failed (status %d) - %v", method, endpoint, statusCode, err)
+		} else {
+			c.Logger.Errorf("Response: %s %s failed - %v", method, endpoint, err)
+		}
 	} else {
 		c.Logger.Debugf("Response: %s %s succeeded (status %d)", method, endpoint, statusCode)
 	}
@@ -121,6 +135,13 @@ func (c *Client) logError(format string, args ...interface{}) {
 	}
 }
 
+// logWarn is a helper to log warnings
+func (c *Client) logWarn(format string, args ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Warnf(format, args...)
+	}
+}
+
 // logDebug is a helper to log debug messages
 func (c *Client) logDebug(format string, args ...interface{}) {
 	if c.Logger != nil {
@@ -0,0 +1,58 @@
+package flespi
+
+import (
+	"errors"
+	"net/http"
+)
+
+// errNoResponse is returned for an attempt whose middleware chain returned neither a response nor an error
+var errNoResponse = errors.New("flespi: middleware returned no response")
+
+// Handler sends a single HTTP request and returns flespi's response
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to inspect or rewrite requests and responses.
+//
+// A middleware may modify req before calling next, replace or modify the
+// returned response, or answer without calling next at all (caching, fault
+// injection). When it replaces a response body it must close the original one.
+// A response built without a Body is treated as empty.
+//
+// Middlewares run once per attempt, inside the retry loop, after the
+// Authorization and Content-Type headers have been set.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware appends middlewares to the client's chain. The first middleware
+// is the outermost one: it sees the request first and the response last.
+//
+// Example:
+//
+//	audit := func(next flespi.Handler) flespi.Handler {
+//	    return func(req *http.Request) (*http.Response, error) {
+//	        res, err := next(req)
+//	        if err == nil {
+//	            log.Printf("%s %s -> %d", req.Method, req.URL.Path, res.StatusCode)
+//	        }
+//	        return res, err
+//	    }
+//	}
+//
+//	client, err := flespi.NewClient("https://flespi.io", "your-token", flespi.WithMiddleware(audit))
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// handler builds the middleware chain around the HTTP client
+func (c *Client) handler() Handler {
+	var h Handler = func(req *http.Request) (*http.Response, error) {
+		return c.HTTPClient.Do(req) //nolint:gosec // G704: URL is constructed from c.Host, set at client init — not user input
+	}
+
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+
+	return h
+}
@@ -0,0 +1,177 @@
+package flespi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithMiddleware_Order(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" in")
+				res, err := next(req)
+				calls = append(calls, name+" out")
+				return res, err
+			}
+		}
+	}
+
+	client, _ := NewClient(server.URL, "test-token", WithMiddleware(trace("a"), trace("b")))
+
+	if err := client.RequestAPI("GET", "test", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "a in,b in,b out,a out"
+	if got := strings.Join(calls, ","); got != expected {
+		t.Errorf("Expected call order %q, got %q", expected, got)
+	}
+}
+
+func TestWithMiddleware_RewritesRequestAndResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "FlespiToken rotated" {
+			t.Errorf("Expected rotated token, got %q", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"result":[{"id":1}]}`))
+	}))
+	defer server.Close()
+
+	rotate := func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("Authorization", "FlespiToken rotated")
+			return next(req)
+		}
+	}
+
+	rewrite := func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			res, err := next(req)
+			if err != nil {
+				return nil, err
+			}
+			res.Body.Close()
+			res.Body = io.NopCloser(strings.NewReader(`{"result":[{"id":2}]}`))
+			return res, nil
+		}
+	}
+
+	client, _ := NewClient(server.URL, "test-token", WithMiddleware(rotate, rewrite))
+
+	var response struct {
+		Result []struct {
+			Id int64 `json:"id"`
+		} `json:"result"`
+	}
+
+	if err := client.RequestAPI("GET", "test", nil, &response); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(response.Result) != 1 || response.Result[0].Id != 2 {
+		t.Errorf("Expected rewritten result with id 2, got %+v", response.Result)
+	}
+}
+
+func TestWithMiddleware_ShortCircuit(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	fault := func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(`{"errors":[{"reason":"injected"}]}`)),
+				Request:    req,
+			}, nil
+		}
+	}
+
+	client, _ := NewClient(server.URL, "test-token", WithMiddleware(fault))
+
+	err := client.RequestAPI("GET", "test", nil, nil)
+
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("Expected *APIError, got %T", err)
+	}
+	if apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Errors[0].Reason != "injected" {
+		t.Errorf("Expected injected 503, got %v", apiErr)
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Error("Expected the server not to be called")
+	}
+}
+
+func TestWithMiddleware_NoResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	var events []RequestEvent
+	observer := ObserverFunc(func(ctx context.Context, event RequestEvent) {
+		events = append(events, event)
+	})
+
+	broken := func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			return nil, nil
+		}
+	}
+
+	client, _ := NewClient(server.URL, "test-token", WithMiddleware(broken), WithObserver(observer))
+
+	err := client.RequestAPI("GET", "test", nil, nil)
+	if !errors.Is(err, errNoResponse) {
+		t.Fatalf("Expected errNoResponse, got %v", err)
+	}
+	if len(events) != 1 || !errors.Is(events[0].Err, errNoResponse) {
+		t.Errorf("Expected the attempt to be observed with the error, got %+v", events)
+	}
+}
+
+func TestWithMiddleware_ResponseWithoutBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+	}{
+		{"no content", http.StatusNoContent, false},
+		{"server error", http.StatusBadGateway, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			synthetic := func(next Handler) Handler {
+				return func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: tt.statusCode, Header: http.Header{}}, nil
+				}
+			}
+
+			client, _ := NewClient("https://flespi.io", "test-token", WithMiddleware(synthetic), WithRetryConfig(nil))
+
+			err := client.RequestAPI("DELETE", "gw/devices/1", nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RequestAPI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
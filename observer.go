@@ -0,0 +1,175 @@
+package flespi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrorClass groups failed attempts into a few low-cardinality categories
+type ErrorClass string
+
+const (
+	// ErrorClassNone marks a successful attempt
+	ErrorClassNone ErrorClass = ""
+	// ErrorClassCanceled means the request context was cancelled
+	ErrorClassCanceled ErrorClass = "canceled"
+	// ErrorClassTimeout means the request timed out
+	ErrorClassTimeout ErrorClass = "timeout"
+	// ErrorClassNetwork covers connection and transport failures
+	ErrorClassNetwork ErrorClass = "network"
+	// ErrorClassRateLimited means flespi answered 429 Too Many Requests
+	ErrorClassRateLimited ErrorClass = "rate_limited"
+	// ErrorClassClient covers the other 4xx responses
+	ErrorClassClient ErrorClass = "client_error"
+	// ErrorClassServer covers 5xx responses
+	ErrorClassServer ErrorClass = "server_error"
+)
+
+// RequestEvent describes a single HTTP attempt; retries produce one event each
+type RequestEvent struct {
+	// Method is the HTTP method
+	Method string
+
+	// Endpoint is the endpoint with ids and selectors replaced by placeholders and
+	// the query stripped, e.g. "gw/devices/{id}", so it is safe to use as a metric label
+	Endpoint string
+
+	// StatusCode is the HTTP status, 0 if no response was received
+	StatusCode int
+
+	// Duration is the time from sending the request until the response body was read
+	Duration time.Duration
+
+	// RequestBytes is the size of the request body
+	RequestBytes int64
+
+	// ResponseBytes is the size of the response body
+	ResponseBytes int64
+
+	// Attempt is 0 for the first try and counts every further attempt of the
+	// call, including the repeat after a credentials refresh
+	Attempt int
+
+	// ErrorClass categorises a failed attempt, empty on success
+	ErrorClass ErrorClass
+
+	// Err is the error of a failed attempt
+	Err error
+
+	// AccountId is the subaccount the request was scoped to, 0 if none
+	AccountId int64
+}
+
+// Observer receives an event for every HTTP attempt made by the client.
+// ObserveRequest is called synchronously, so implementations should be fast
+// and must be safe for concurrent use.
+type Observer interface {
+	ObserveRequest(ctx context.Context, event RequestEvent)
+}
+
+// ObserverFunc adapts a function to the Observer interface
+type ObserverFunc func(ctx context.Context, event RequestEvent)
+
+// ObserveRequest implements Observer
+func (f ObserverFunc) ObserveRequest(ctx context.Context, event RequestEvent) {
+	f(ctx, event)
+}
+
+// WithObserver adds an observer; several observers are called in the order they were added.
+//
+// Example:
+//
+//	requests := expvar.NewMap("flespi_requests")
+//	client, err := flespi.NewClient("https://flespi.io", "your-token",
+//	    flespi.WithObserver(flespi.ObserverFunc(func(ctx context.Context, e flespi.RequestEvent) {
+//	        requests.Add(e.Method+" "+e.Endpoint+" "+string(e.ErrorClass), 1)
+//	    })),
+//	)
+func WithObserver(observer Observer) ClientOption {
+	return func(c *Client) {
+		c.observers = append(c.observers, observer)
+	}
+}
+
+func (c *Client) observe(ctx context.Context, event RequestEvent) {
+	for _, observer := range c.observers {
+		observer.ObserveRequest(ctx, event)
+	}
+}
+
+var numericSegment = regexp.MustCompile(`^[0-9]+(,[0-9]+)*$`)
+
+// freeFormSegments names the placeholder for the segment that follows a collection
+// whose items are addressed by something other than a numeric id
+var freeFormSegments = map[string]string{
+	"media":     "{uuid}",
+	"settings":  "{name}",
+	"telemetry": "{name}",
+}
+
+// EndpointTemplate replaces ids, names and selectors in endpoint with {id}, {uuid},
+// {name} and {selector} and drops the query string, e.g.
+// "gw/devices/42/settings/all?fields=x" becomes "gw/devices/{id}/settings/{selector}"
+// and "gw/devices/42/media/3f2a/data" becomes "gw/devices/{id}/media/{uuid}/data".
+func EndpointTemplate(endpoint string) string {
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+
+	segments := strings.Split(endpoint, "/")
+	previous := ""
+	for i, segment := range segments {
+		placeholder, freeForm := freeFormSegments[previous]
+		previous = segment
+
+		switch {
+		case segment == "all", strings.HasPrefix(segment, "{"), strings.HasPrefix(segment, "%7B"):
+			segments[i] = "{selector}"
+		case freeForm && segment != "":
+			segments[i] = placeholder
+		case numericSegment.MatchString(segment):
+			if strings.Contains(segment, ",") {
+				segments[i] = "{selector}"
+			} else {
+				segments[i] = "{id}"
+			}
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// classifyError returns the ErrorClass of an attempt's error
+func classifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassNone
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			return ErrorClassRateLimited
+		case apiErr.StatusCode >= 500:
+			return ErrorClassServer
+		default:
+			return ErrorClassClient
+		}
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ErrorClassCanceled
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorClassTimeout
+	}
+
+	return ErrorClassNetwork
+}
@@ -0,0 +1,147 @@
+package flespi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEndpointTemplate(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		expected string
+	}{
+		{"gw/devices/42", "gw/devices/{id}"},
+		{"gw/devices/1,5,9", "gw/devices/{selector}"},
+		{"gw/devices/all?fields=id,name", "gw/devices/{selector}"},
+		{"gw/devices/%7Bname=truck*%7D", "gw/devices/{selector}"},
+		{"gw/devices/42/settings/all", "gw/devices/{id}/settings/{selector}"},
+		{"platform/tokens", "platform/tokens"},
+		{"gw/devices/42/media/3f2a9c1e-7b4d", "gw/devices/{id}/media/{uuid}"},
+		{"gw/devices/42/media/3f2a9c1e-7b4d/data", "gw/devices/{id}/media/{uuid}/data"},
+		{"gw/devices/42/media/%7Btimestamp%3E=1700000000%7D", "gw/devices/{id}/media/{selector}"},
+		{"gw/devices/42/media", "gw/devices/{id}/media"},
+		{"gw/devices/42/settings/report_interval", "gw/devices/{id}/settings/{name}"},
+		{"gw/devices/1,2/settings/report_interval", "gw/devices/{selector}/settings/{name}"},
+		{"gw/devices/42/telemetry/position.latitude,position.longitude", "gw/devices/{id}/telemetry/{name}"},
+		{"gw/devices/42/telemetry/all", "gw/devices/{id}/telemetry/{selector}"},
+		{"gw/devices/42/commands-queue/7", "gw/devices/{id}/commands-queue/{id}"},
+	}
+
+	for _, tt := range tests {
+		if got := EndpointTemplate(tt.endpoint); got != tt.expected {
+			t.Errorf("EndpointTemplate(%q) = %q, want %q", tt.endpoint, got, tt.expected)
+		}
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorClass
+	}{
+		{"success", nil, ErrorClassNone},
+		{"429", &APIError{StatusCode: 429}, ErrorClassRateLimited},
+		{"404", &APIError{StatusCode: 404}, ErrorClassClient},
+		{"502", &APIError{StatusCode: 502}, ErrorClassServer},
+		{"canceled", context.Canceled, ErrorClassCanceled},
+		{"deadline", context.DeadlineExceeded, ErrorClassTimeout},
+		{"network", errors.New("connection refused"), ErrorClassNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.err); got != tt.expected {
+				t.Errorf("classifyError() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWithObserver_EventPerAttempt(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"result":[{"id":42}]}`))
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var events []RequestEvent
+	observer := ObserverFunc(func(ctx context.Context, event RequestEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	})
+
+	config := &RetryConfig{
+		MaxRetries:           1,
+		InitialBackoff:       time.Millisecond,
+		MaxBackoff:           time.Millisecond,
+		BackoffMultiplier:    1,
+		RetryableStatusCodes: map[int]bool{http.StatusBadGateway: true},
+	}
+
+	client, _ := NewClient(server.URL, "test-token", WithRetryConfig(config), WithObserver(observer))
+
+	err := client.ForAccount(7).RequestAPI("PUT", "gw/devices/42?fields=id", map[string]string{"name": "x"}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+
+	first, second := events[0], events[1]
+
+	if first.Attempt != 0 || first.StatusCode != http.StatusBadGateway || first.ErrorClass != ErrorClassServer {
+		t.Errorf("Unexpected first event: %+v", first)
+	}
+	if second.Attempt != 1 || second.StatusCode != http.StatusOK || second.ErrorClass != ErrorClassNone || second.Err != nil {
+		t.Errorf("Unexpected second event: %+v", second)
+	}
+	if second.Method != "PUT" || second.Endpoint != "gw/devices/{id}" || second.AccountId != 7 {
+		t.Errorf("Unexpected request details: %+v", second)
+	}
+	if second.RequestBytes != int64(len(`{"name":"x"}`)) {
+		t.Errorf("Expected request size %d, got %d", len(`{"name":"x"}`), second.RequestBytes)
+	}
+	if second.ResponseBytes != int64(len(`{"result":[{"id":42}]}`)) {
+		t.Errorf("Expected response size %d, got %d", len(`{"result":[{"id":42}]}`), second.ResponseBytes)
+	}
+	if second.Duration <= 0 {
+		t.Error("Expected a positive duration")
+	}
+}
+
+func TestLogResponse_RealStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := &StdLogger{logger: log.New(&buf, "", 0), level: LogLevelDebug}
+	client, _ := NewClient(server.URL, "test-token", WithLogger(logger))
+
+	if err := client.RequestAPI("DELETE", "test", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Response: DELETE test succeeded (status 204)") {
+		t.Errorf("Expected the real status to be logged, got %q", buf.String())
+	}
+}
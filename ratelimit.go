@@ -0,0 +1,292 @@
+package flespi
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Rate-limit related response headers
+const (
+	headerRetryAfter         = "Retry-After"
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// RateLimitStatus is the request budget flespi reported in its latest response
+type RateLimitStatus struct {
+	// Limit is the number of requests allowed in the current window (-1 if not reported)
+	Limit int
+
+	// Remaining is the number of requests left in the current window (-1 if not reported)
+	Remaining int
+
+	// Reset is when the current window ends (zero if not reported)
+	Reset time.Time
+
+	// RetryAfter is the delay requested by the last 429/503 response
+	RetryAfter time.Duration
+
+	// UpdatedAt is when the status was last refreshed from a response
+	UpdatedAt time.Time
+}
+
+// Known reports whether flespi has sent any rate-limit information yet
+func (s RateLimitStatus) Known() bool {
+	return !s.UpdatedAt.IsZero()
+}
+
+// Exhausted reports whether the budget is used up and the window has not reset yet
+func (s RateLimitStatus) Exhausted(now time.Time) bool {
+	return s.Remaining == 0 && now.Before(s.Reset)
+}
+
+// rateLimitTracker keeps the latest RateLimitStatus; it is shared by scoped clients
+type rateLimitTracker struct {
+	mu           sync.Mutex
+	status       RateLimitStatus
+	blockedUntil time.Time
+}
+
+func newRateLimitTracker() *rateLimitTracker {
+	return &rateLimitTracker{status: RateLimitStatus{Limit: -1, Remaining: -1}}
+}
+
+// update refreshes the status from response headers
+func (t *rateLimitTracker) update(header http.Header, statusCode int, now time.Time) {
+	limit, hasLimit := parseIntHeader(header, headerRateLimitLimit)
+	remaining, hasRemaining := parseIntHeader(header, headerRateLimitRemaining)
+	reset, hasReset := parseResetHeader(header, now)
+	retryAfter, hasRetryAfter := parseRetryAfter(header, now)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// a pause only applies until the next response
+	t.status.RetryAfter = 0
+
+	if !hasLimit && !hasRemaining && !hasReset && !hasRetryAfter {
+		return
+	}
+
+	if hasLimit {
+		t.status.Limit = limit
+	}
+	if hasRemaining {
+		t.status.Remaining = remaining
+	}
+	if hasReset {
+		t.status.Reset = reset
+	}
+
+	if hasRetryAfter && (statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable) {
+		t.status.RetryAfter = retryAfter
+		if until := now.Add(retryAfter); until.After(t.blockedUntil) {
+			t.blockedUntil = until
+		}
+	}
+
+	if t.status.Exhausted(now) && t.status.Reset.After(t.blockedUntil) {
+		t.blockedUntil = t.status.Reset
+	}
+
+	t.status.UpdatedAt = now
+}
+
+func (t *rateLimitTracker) snapshot() RateLimitStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+// wait blocks until the server-announced pause, if any, is over
+func (t *rateLimitTracker) wait(ctx context.Context) error {
+	t.mu.Lock()
+	delay := time.Until(t.blockedUntil)
+	t.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func parseIntHeader(header http.Header, name string) (int, bool) {
+	value := header.Get(name)
+	if value == "" {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// parseResetHeader accepts both seconds until reset and a unix timestamp
+func parseResetHeader(header http.Header, now time.Time) (time.Time, bool) {
+	value := header.Get(headerRateLimitReset)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	// anything that large is an absolute timestamp, not a delay
+	if n > 1e9 {
+		return time.Unix(0, int64(n*float64(time.Second))), true
+	}
+
+	return now.Add(time.Duration(n * float64(time.Second))), true
+}
+
+// parseRetryAfter accepts both delay-seconds and HTTP-date values
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get(headerRetryAfter)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// RateLimit returns the request budget flespi reported in its latest response.
+// Scoped clients created with ForAccount share the budget of their parent.
+func (c *Client) RateLimit() RateLimitStatus {
+	if c.rateLimit == nil {
+		return RateLimitStatus{Limit: -1, Remaining: -1}
+	}
+	return c.rateLimit.snapshot()
+}
+
+// RateLimiter paces outgoing requests on the client side
+type RateLimiter interface {
+	// Wait blocks until a request may be sent or ctx is done
+	Wait(ctx context.Context) error
+}
+
+// WithRateLimiter paces every request (including retries) through limiter.
+// With a limiter configured the client also holds requests back while flespi
+// reports an exhausted budget or a Retry-After pause, so concurrent workers
+// sharing one token back off together instead of piling into 429s.
+func WithRateLimiter(limiter RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.RateLimiter = limiter
+	}
+}
+
+// TokenBucket is a RateLimiter that allows Rate requests per second on average
+// with bursts of up to Burst requests. It is safe for concurrent use.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a token bucket limiter; it starts full. The rate must
+// be greater than 0, or the bucket would never refill.
+func NewTokenBucket(rate float64, burst int) (*TokenBucket, error) {
+	if rate <= 0 || math.IsNaN(rate) {
+		return nil, &ValidationError{Field: "rate", Message: "must be greater than 0"}
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}, nil
+}
+
+// Wait implements RateLimiter
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		delay := b.reserve(time.Now())
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available, otherwise returns how long to wait
+// for one; only a zero delay means a token was taken
+func (b *TokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	// round up, a deficit too small for a nanosecond must still be waited for
+	return time.Duration(math.Ceil((1 - b.tokens) / b.rate * float64(time.Second)))
+}
+
+// waitForBudget paces the request through the configured RateLimiter and, when
+// one is set, holds it back until a server-announced pause is over
+func (c *Client) waitForBudget(ctx context.Context) error {
+	if c.RateLimiter == nil {
+		return nil
+	}
+
+	if c.rateLimit != nil {
+		if err := c.rateLimit.wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	return c.RateLimiter.Wait(ctx)
+}
@@ -0,0 +1,215 @@
+package flespi
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{name: "seconds", value: "3", expected: 3 * time.Second, ok: true},
+		{name: "http date", value: now.Add(5 * time.Second).Format(http.TimeFormat), expected: 5 * time.Second, ok: true},
+		{name: "date in the past", value: now.Add(-time.Minute).Format(http.TimeFormat), expected: 0, ok: true},
+		{name: "missing", value: "", ok: false},
+		{name: "garbage", value: "soon", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+
+			got, ok := parseRetryAfter(header, now)
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestClient_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("X-RateLimit-Reset", "30")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-token")
+
+	if client.RateLimit().Known() {
+		t.Error("Expected rate limit to be unknown before the first request")
+	}
+
+	// scoped clients share the budget with their parent
+	if err := client.ForAccount(7).RequestAPI("GET", "test", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	status := client.RateLimit()
+	if !status.Known() {
+		t.Fatal("Expected rate limit to be known")
+	}
+	if status.Limit != 100 || status.Remaining != 42 {
+		t.Errorf("Expected limit 100 and remaining 42, got %d and %d", status.Limit, status.Remaining)
+	}
+	if until := time.Until(status.Reset); until < 25*time.Second || until > 30*time.Second {
+		t.Errorf("Expected reset in ~30s, got %v", until)
+	}
+}
+
+func TestClient_RetryHonoursRetryAfter(t *testing.T) {
+	var attempts int32
+	var firstAt, secondAt time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			firstAt = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		secondAt = time.Now()
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	config := &RetryConfig{
+		MaxRetries:           1,
+		InitialBackoff:       10 * time.Millisecond,
+		MaxBackoff:           10 * time.Millisecond,
+		BackoffMultiplier:    1,
+		RetryableStatusCodes: map[int]bool{http.StatusTooManyRequests: true},
+	}
+
+	client, _ := NewClient(server.URL, "test-token", WithRetryConfig(config))
+
+	if err := client.RequestAPI("GET", "test", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if wait := secondAt.Sub(firstAt); wait < time.Second {
+		t.Errorf("Expected retry after at least 1s, got %v", wait)
+	}
+
+	if client.RateLimit().RetryAfter != 0 {
+		t.Error("Expected RetryAfter to be cleared by the successful response")
+	}
+}
+
+func TestAPIError_RetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-token")
+
+	err := client.RequestAPI("GET", "test", nil, nil)
+
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("Expected *APIError, got %T", err)
+	}
+	if apiErr.RetryAfter != 7*time.Second {
+		t.Errorf("Expected RetryAfter 7s, got %v", apiErr.RetryAfter)
+	}
+}
+
+func TestTokenBucket_Wait(t *testing.T) {
+	bucket, _ := NewTokenBucket(20, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := bucket.Wait(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// two requests fit in the burst, the other two wait ~50ms each
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("Expected requests to be paced, took only %v", elapsed)
+	}
+}
+
+func TestTokenBucket_WaitCancelled(t *testing.T) {
+	bucket, _ := NewTokenBucket(0.1, 1)
+	bucket.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := bucket.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestNewTokenBucket_InvalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -1, math.NaN()} {
+		if _, err := NewTokenBucket(rate, 1); !IsValidationError(err) {
+			t.Errorf("Expected a validation error for rate %v, got %v", rate, err)
+		}
+	}
+}
+
+func TestTokenBucket_ReserveRoundsUp(t *testing.T) {
+	bucket, _ := NewTokenBucket(1e6, 1)
+
+	now := time.Now()
+	bucket.last = now
+	bucket.tokens = 1 - 1e-12
+
+	// the deficit needs far less than a nanosecond, but no token is available yet
+	if delay := bucket.reserve(now); delay <= 0 {
+		t.Errorf("Expected a positive delay, got %v", delay)
+	}
+	if bucket.tokens >= 1 || bucket.tokens < 0 {
+		t.Errorf("Expected no token to be taken, %v left", bucket.tokens)
+	}
+}
+
+func TestClient_RateLimiterWaitsForReset(t *testing.T) {
+	var calls int32
+	var firstAt, secondAt time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			firstAt = time.Now()
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "0.3")
+		} else {
+			secondAt = time.Now()
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	bucket, _ := NewTokenBucket(1000, 10)
+	client, _ := NewClient(server.URL, "test-token", WithRateLimiter(bucket))
+
+	client.RequestAPI("GET", "test", nil, nil)
+	client.RequestAPI("GET", "test", nil, nil)
+
+	if wait := secondAt.Sub(firstAt); wait < 250*time.Millisecond {
+		t.Errorf("Expected the second request to wait for the budget reset, got %v", wait)
+	}
+}
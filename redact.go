@@ -0,0 +1,85 @@
+package flespi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// redactedValue replaces secrets in log output
+const redactedValue = "[REDACTED]"
+
+// sensitiveHeaders are HTTP headers never written to logs
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// sensitiveFields are JSON object keys whose values are secrets, e.g. Token.Key
+var sensitiveFields = map[string]bool{
+	"key":      true,
+	"token":    true,
+	"password": true,
+}
+
+// RedactHeaders returns a copy of header with credentials replaced by [REDACTED].
+func RedactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name := range redacted {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			redacted[name] = []string{redactedValue}
+		}
+	}
+	return redacted
+}
+
+// RedactJSON returns a copy of a JSON document with secrets replaced by [REDACTED]:
+// token keys, passwords and the values of webhook headers ({"name": ..., "value": ...}
+// items of a "headers" array). Input that is not valid JSON is replaced entirely,
+// since it cannot be inspected.
+func RedactJSON(data []byte) []byte {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return data
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return []byte(redactedValue)
+	}
+
+	redacted, err := json.Marshal(redactValue(doc, false))
+	if err != nil {
+		return []byte(redactedValue)
+	}
+
+	return redacted
+}
+
+// redactValue walks a decoded JSON value; inHeaders is set for the items of a "headers" array
+func redactValue(value interface{}, inHeaders bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, item := range v {
+			switch {
+			case sensitiveFields[strings.ToLower(field)]:
+				v[field] = redactedValue
+			case inHeaders && field == "value":
+				v[field] = redactedValue
+			default:
+				v[field] = redactValue(item, field == "headers")
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item, inHeaders)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,89 @@
+package flespi
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "FlespiToken secret")
+	header.Set("x-flespi-cid", "42")
+
+	redacted := RedactHeaders(header)
+
+	if redacted.Get("Authorization") != "[REDACTED]" {
+		t.Errorf("Expected Authorization to be redacted, got %q", redacted.Get("Authorization"))
+	}
+	if redacted.Get("x-flespi-cid") != "42" {
+		t.Errorf("Expected x-flespi-cid to be kept, got %q", redacted.Get("x-flespi-cid"))
+	}
+	if header.Get("Authorization") != "FlespiToken secret" {
+		t.Error("Expected the original header to be untouched")
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "token key",
+			input:    `[{"info":"ci","key":"abc"}]`,
+			expected: `[{"info":"ci","key":"[REDACTED]"}]`,
+		},
+		{
+			name:     "webhook headers",
+			input:    `{"configuration":{"headers":[{"name":"X-Api-Key","value":"s3cr3t"}],"uri":"https://example.com"}}`,
+			expected: `{"configuration":{"headers":[{"name":"X-Api-Key","value":"[REDACTED]"}],"uri":"https://example.com"}}`,
+		},
+		{
+			name:     "value outside headers",
+			input:    `{"value":12}`,
+			expected: `{"value":12}`,
+		},
+		{
+			name:     "invalid json",
+			input:    `key=abc`,
+			expected: `[REDACTED]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(RedactJSON([]byte(tt.input))); got != tt.expected {
+				t.Errorf("RedactJSON() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClient_DebugLogIsRedacted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := &StdLogger{logger: log.New(&buf, "", 0), level: LogLevelDebug}
+	client, _ := NewClient(server.URL, "top-secret-token", WithLogger(logger))
+
+	payload := []map[string]string{{"info": "ci", "key": "top-secret-key"}}
+	if err := client.RequestAPI("POST", "platform/tokens", payload, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	output := buf.String()
+	if strings.Contains(output, "top-secret") {
+		t.Errorf("Expected secrets to be redacted, got %q", output)
+	}
+	if !strings.Contains(output, `"info":"ci"`) {
+		t.Errorf("Expected the payload to be logged, got %q", output)
+	}
+}
@@ -0,0 +1,122 @@
+package flespi
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Region describes a flespi deployment and the endpoints it serves
+type Region struct {
+	// Name identifies the region, e.g. "eu"
+	Name string
+
+	// APIURL is the base URL of the REST API
+	APIURL string
+
+	// MQTTURL is the MQTT broker address (TLS)
+	MQTTURL string
+
+	// MQTTWebSocketURL is the MQTT over secure WebSocket address
+	MQTTWebSocketURL string
+
+	// MediaURL is the base URL device media and CDN files are downloaded from
+	MediaURL string
+}
+
+var (
+	// RegionEU is the main flespi deployment at flespi.io
+	RegionEU = Region{
+		Name:             "eu",
+		APIURL:           "https://flespi.io",
+		MQTTURL:          "mqtts://mqtt.flespi.io:8883",
+		MQTTWebSocketURL: "wss://mqtt.flespi.io:443",
+		MediaURL:         "https://flespi.io",
+	}
+
+	// RegionRU is the flespi deployment at flespi.ru
+	RegionRU = Region{
+		Name:             "ru",
+		APIURL:           "https://flespi.ru",
+		MQTTURL:          "mqtts://mqtt.flespi.ru:8883",
+		MQTTWebSocketURL: "wss://mqtt.flespi.ru:443",
+		MediaURL:         "https://flespi.ru",
+	}
+)
+
+// Regions lists the known flespi deployments
+var Regions = []Region{RegionEU, RegionRU}
+
+// RegionByName looks up a known region by name, case-insensitively
+func RegionByName(name string) (Region, bool) {
+	for _, region := range Regions {
+		if strings.EqualFold(region.Name, name) {
+			return region, true
+		}
+	}
+	return Region{}, false
+}
+
+// WithRegion points the client at a flespi deployment; it overrides the host
+// passed to NewClient, which may then be empty.
+//
+// Example:
+//
+//	client, err := flespi.NewClient("", "your-token", flespi.WithRegion(flespi.RegionRU))
+//	broker := client.Region().MQTTURL
+func WithRegion(region Region) ClientOption {
+	return func(c *Client) {
+		c.Host = region.APIURL
+		c.region = region
+	}
+}
+
+// WithBaseURL sends requests to a custom base URL, e.g. a proxy or an on-premise
+// gateway. A path prefix is kept: with "https://proxy.local/flespi" devices are
+// requested from https://proxy.local/flespi/gw/devices/all.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.Host = baseURL
+		c.region = Region{}
+	}
+}
+
+// Region returns the deployment the client talks to. For hosts of a known
+// region the full Region is returned; for custom base URLs only Name ("custom")
+// and APIURL are set, and MediaURL defaults to APIURL.
+func (c *Client) Region() Region {
+	if c.region.APIURL != "" && c.region.APIURL == c.Host {
+		return c.region
+	}
+
+	for _, region := range Regions {
+		if region.APIURL == c.Host {
+			return region
+		}
+	}
+
+	return Region{Name: "custom", APIURL: c.Host, MediaURL: c.Host}
+}
+
+// normalizeHost validates host and strips the trailing slash, so that endpoints
+// can be appended with a single "/"
+func normalizeHost(host string) (string, error) {
+	if err := ValidateHost(host); err != nil {
+		return "", err
+	}
+
+	host = strings.TrimRight(host, "/")
+
+	parsed, err := url.Parse(host)
+	if err != nil {
+		return "", &ValidationError{Field: "host", Message: err.Error()}
+	}
+	if parsed.Host == "" {
+		return "", &ValidationError{Field: "host", Message: "must include a host name"}
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", &ValidationError{Field: "host", Message: fmt.Sprintf("must not contain a query or fragment: %s", host)}
+	}
+
+	return host, nil
+}
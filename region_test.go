@@ -0,0 +1,88 @@
+package flespi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewClient_HostValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		options  []ClientOption
+		wantHost string
+		wantErr  bool
+	}{
+		{name: "trailing slash", host: "https://flespi.io/", wantHost: "https://flespi.io"},
+		{name: "path prefix", host: "https://proxy.local/flespi/", wantHost: "https://proxy.local/flespi"},
+		{name: "empty host", host: "", wantErr: true},
+		{name: "missing scheme", host: "flespi.io", wantErr: true},
+		{name: "query", host: "https://flespi.io?x=1", wantErr: true},
+		{name: "region without host", host: "", options: []ClientOption{WithRegion(RegionRU)}, wantHost: "https://flespi.ru"},
+		{name: "base url overrides host", host: "https://flespi.io", options: []ClientOption{WithBaseURL("http://localhost:9000/api")}, wantHost: "http://localhost:9000/api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.host, "test-token", tt.options...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !IsValidationError(err) {
+					t.Errorf("Expected a validation error, got %T", err)
+				}
+				return
+			}
+			if client.Host != tt.wantHost {
+				t.Errorf("NewClient() Host = %v, want %v", client.Host, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestClient_Region(t *testing.T) {
+	client, _ := NewClient("", "test-token", WithRegion(RegionRU))
+	if region := client.Region(); region.MQTTURL != "mqtts://mqtt.flespi.ru:8883" {
+		t.Errorf("Expected the RU MQTT endpoint, got %q", region.MQTTURL)
+	}
+
+	client, _ = NewClient("https://flespi.io", "test-token")
+	if region := client.Region(); region.Name != "eu" {
+		t.Errorf("Expected the EU region to be derived from the host, got %q", region.Name)
+	}
+
+	client, _ = NewClient("https://proxy.local", "test-token")
+	if region := client.Region(); region.Name != "custom" || region.MediaURL != "https://proxy.local" {
+		t.Errorf("Expected a custom region, got %+v", region)
+	}
+}
+
+func TestRegionByName(t *testing.T) {
+	if region, ok := RegionByName("EU"); !ok || region.APIURL != "https://flespi.io" {
+		t.Errorf("Expected the EU region, got %+v", region)
+	}
+	if _, ok := RegionByName("mars"); ok {
+		t.Error("Expected an unknown region not to be found")
+	}
+}
+
+func TestWithBaseURL_PathPrefix(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/flespi/gw/devices/all" {
+			t.Errorf("Expected path /flespi/gw/devices/all, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"result":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("", "test-token", WithBaseURL(server.URL+"/flespi/"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := client.Devices.List(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
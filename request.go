@@ -0,0 +1,24 @@
+package flespi
+
+import "github.com/mixser/flespi-client/internal/flespiapi"
+
+// RequestOption tunes a single Get or List call of a resource package.
+type RequestOption = flespiapi.RequestOption
+
+// WithFields limits a Get or List response to the named fields (server-side projection).
+// Fields that are not returned keep their zero value in the decoded objects.
+//
+// Example:
+//
+//	devices, err := client.Devices.List(flespi.WithFields("id", "name", "cid"))
+func WithFields(fields ...string) RequestOption {
+	return flespiapi.WithFields(fields...)
+}
+
+// DeleteResult is returned by the Delete*BySelector functions of the resource packages
+// and lists the ids flespi removed.
+type DeleteResult = flespiapi.DeleteResult
+
+// RawBody is a payload RequestAPI sends as is, with its own Content-Type, instead
+// of marshalling it to JSON.
+type RawBody = flespiapi.RawBody
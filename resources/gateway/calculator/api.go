@@ -1,12 +1,20 @@
 package flespi_calculator
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
+// calculatorFields are requested by GetCalculator unless a flespiapi.WithFields option overrides them.
+var calculatorFields = []string{"id", "name", "messages_source", "update_period", "update_delay", "update_onchange", "intervals_ttl", "intervals_rotate", "selectors", "counters", "validate_interval", "validate_message", "timezone", "metadata", "cid"}
+
 func NewCalculator(client flespiapi.APIRequester, name string, options ...CreateCalculatorOption) (*Calculator, error) {
+	return NewCalculatorWithContext(context.Background(), client, name, options...)
+}
+
+func NewCalculatorWithContext(ctx context.Context, client flespiapi.APIRequester, name string, options ...CreateCalculatorOption) (*Calculator, error) {
 	calc := Calculator{
 		Name: name,
 	}
@@ -28,34 +36,119 @@ func NewCalculator(client flespiapi.APIRequester, name string, options ...Create
 
 	response := calculatorsResponse{}
 
-	if err := client.RequestAPIWithHeaders("POST", "gw/calcs", headers, []Calculator{calc}, &response); err != nil {
+	if err := client.RequestAPIWithContextAndHeaders(ctx, "POST", "gw/calcs", headers, []Calculator{calc}, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Calculators[0], nil
+	return flespiapi.First(response.Calculators, response.Errors)
+}
+
+// NewCalculators creates calculators in bulk. Calculators owned by different
+// subaccounts are sent in separate requests.
+func NewCalculators(client flespiapi.APIRequester, calculators []Calculator) (*CalculatorsResult, error) {
+	return NewCalculatorsWithContext(context.Background(), client, calculators)
+}
+
+func NewCalculatorsWithContext(ctx context.Context, client flespiapi.APIRequester, calculators []Calculator) (*CalculatorsResult, error) {
+	result := &CalculatorsResult{}
+
+	if len(calculators) == 0 {
+		return result, nil
+	}
+
+	accountIds, groups := flespiapi.GroupByAccount(calculators, func(calc Calculator) int64 { return calc.AccountId })
+
+	for _, accountId := range accountIds {
+		var headers map[string]string
+		if accountId != 0 {
+			headers = map[string]string{
+				"x-flespi-cid": fmt.Sprintf("%d", accountId),
+			}
+		}
+
+		// Id is assigned by flespi and AccountId (cid) is conveyed via header;
+		// the groups are copies, so clearing them is safe
+		batch := groups[accountId]
+		for i := range batch {
+			batch[i].Id = 0
+			batch[i].AccountId = 0
+		}
+
+		response := calculatorsResponse{}
+
+		if err := client.RequestAPIWithContextAndHeaders(ctx, "POST", "gw/calcs", headers, batch, &response); err != nil {
+			return result, err
+		}
+
+		result.Append(response.Calculators, response.Errors, func(calc Calculator) int64 { return calc.Id })
+	}
+
+	return result, nil
+}
+
+func ListCalculators(client flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Calculator, error) {
+	return ListCalculatorsWithContext(context.Background(), client, options...)
+}
+
+func ListCalculatorsWithContext(ctx context.Context, client flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Calculator, error) {
+	return ListCalculatorsBySelectorWithContext(ctx, client, flespiapi.SelectAll(), options...)
+}
+
+// ListCalculatorsBySelector returns the calculators matched by selector,
+// for example {name=mileage*}.
+func ListCalculatorsBySelector(client flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Calculator, error) {
+	return ListCalculatorsBySelectorWithContext(context.Background(), client, selector, options...)
 }
 
-func ListCalculators(client flespiapi.APIRequester) ([]Calculator, error) {
+func ListCalculatorsBySelectorWithContext(ctx context.Context, client flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Calculator, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
 	response := calculatorsResponse{}
 
-	if err := client.RequestAPI("GET", "gw/calcs/all", nil, &response); err != nil {
+	if err := client.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("gw/calcs/%s", path), nil, options...), nil, &response); err != nil {
 		return nil, err
 	}
 
 	return response.Calculators, nil
 }
 
-func GetCalculator(client flespiapi.APIRequester, calculatorId int64) (*Calculator, error) {
+// IterateCalculators decodes the calculators matched by selector one by one while the response arrives.
+// Close the iterator when done.
+func IterateCalculators(client flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *CalculatorIterator {
+	return IterateCalculatorsWithContext(context.Background(), client, selector, options...)
+}
+
+func IterateCalculatorsWithContext(ctx context.Context, client flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *CalculatorIterator {
+	path, err := selector.Path()
+	if err != nil {
+		return flespiapi.FailedIterator[Calculator](err)
+	}
+
+	return flespiapi.NewIterator[Calculator](ctx, client, flespiapi.Endpoint(fmt.Sprintf("gw/calcs/%s", path), nil, options...), nil)
+}
+
+func GetCalculator(client flespiapi.APIRequester, calculatorId int64, options ...flespiapi.RequestOption) (*Calculator, error) {
+	return GetCalculatorWithContext(context.Background(), client, calculatorId, options...)
+}
+
+func GetCalculatorWithContext(ctx context.Context, client flespiapi.APIRequester, calculatorId int64, options ...flespiapi.RequestOption) (*Calculator, error) {
 	response := calculatorsResponse{}
 
-	if err := client.RequestAPI("GET", fmt.Sprintf("gw/calcs/%d?fields=id,name,messages_source,update_period,update_delay,update_onchange,intervals_ttl,intervals_rotate,selectors,counters,validate_interval,validate_message,timezone,metadata,cid", calculatorId), nil, &response); err != nil {
+	if err := client.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("gw/calcs/%d", calculatorId), calculatorFields, options...), nil, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Calculators[0], nil
+	return flespiapi.First(response.Calculators, response.Errors)
 }
 
 func UpdateCalculator(client flespiapi.APIRequester, calc Calculator) (*Calculator, error) {
+	return UpdateCalculatorWithContext(context.Background(), client, calc)
+}
+
+func UpdateCalculatorWithContext(ctx context.Context, client flespiapi.APIRequester, calc Calculator) (*Calculator, error) {
 	response := calculatorsResponse{}
 
 	calculatorId := calc.Id
@@ -76,21 +169,72 @@ func UpdateCalculator(client flespiapi.APIRequester, calc Calculator) (*Calculat
 		}
 	}
 
-	if err := client.RequestAPIWithHeaders("PUT", fmt.Sprintf("gw/calcs/%d", calculatorId), headers, calc, &response); err != nil {
+	if err := client.RequestAPIWithContextAndHeaders(ctx, "PUT", fmt.Sprintf("gw/calcs/%d", calculatorId), headers, calc, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Calculators[0], nil
+	return flespiapi.First(response.Calculators, response.Errors)
 }
 
 func DeleteCalculator(client flespiapi.APIRequester, calc Calculator) error {
+	return DeleteCalculatorWithContext(context.Background(), client, calc)
+}
+
+func DeleteCalculatorWithContext(ctx context.Context, client flespiapi.APIRequester, calc Calculator) error {
 	if calc.Id == 0 {
-		return fmt.Errorf("calculator id is not set")
+		return flespiapi.Invalid("calculator id is not set")
 	}
 
-	return DeleteCalculatorById(client, calc.Id)
+	return DeleteCalculatorByIdWithContext(ctx, client, calc.Id)
 }
 
 func DeleteCalculatorById(client flespiapi.APIRequester, calculatorId int64) error {
-	return client.RequestAPI("DELETE", fmt.Sprintf("gw/calcs/%d", calculatorId), nil, nil)
+	return DeleteCalculatorByIdWithContext(context.Background(), client, calculatorId)
+}
+
+func DeleteCalculatorByIdWithContext(ctx context.Context, client flespiapi.APIRequester, calculatorId int64) error {
+	return client.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("gw/calcs/%d", calculatorId), nil, nil)
+}
+
+// UpdateCalculatorsBySelector changes the given fields on every calculator matched by selector.
+func UpdateCalculatorsBySelector(client flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*CalculatorsResult, error) {
+	return UpdateCalculatorsBySelectorWithContext(context.Background(), client, selector, changes)
+}
+
+func UpdateCalculatorsBySelectorWithContext(ctx context.Context, client flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*CalculatorsResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	response := calculatorsResponse{}
+
+	if err := client.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("gw/calcs/%s", path), changes, &response); err != nil {
+		return nil, err
+	}
+
+	result := &CalculatorsResult{}
+	result.Append(response.Calculators, response.Errors, func(calc Calculator) int64 { return calc.Id })
+
+	return result, nil
+}
+
+// DeleteCalculatorsBySelector deletes every calculator matched by selector.
+func DeleteCalculatorsBySelector(client flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteCalculatorsBySelectorWithContext(context.Background(), client, selector)
+}
+
+func DeleteCalculatorsBySelectorWithContext(ctx context.Context, client flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &flespiapi.DeleteResult{}
+
+	if err := client.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("gw/calcs/%s", path), nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
@@ -1,9 +1,14 @@
 package flespi_calculator
 
-import "github.com/mixser/flespi-client/internal/flespiapi"
+import (
+	"context"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
 
 // CalculatorClient provides receiver-based methods for managing Flespi calculators.
 // Access it via Client.Calculators after creating a flespi.Client.
+// Every method has a ...WithContext variant that takes a context.Context first.
 type CalculatorClient struct {
 	c flespiapi.APIRequester
 }
@@ -17,22 +22,86 @@ func (cc *CalculatorClient) Create(name string, options ...CreateCalculatorOptio
 	return NewCalculator(cc.c, name, options...)
 }
 
-func (cc *CalculatorClient) List() ([]Calculator, error) {
-	return ListCalculators(cc.c)
+func (cc *CalculatorClient) CreateWithContext(ctx context.Context, name string, options ...CreateCalculatorOption) (*Calculator, error) {
+	return NewCalculatorWithContext(ctx, cc.c, name, options...)
+}
+
+func (cc *CalculatorClient) CreateMany(calculators []Calculator) (*CalculatorsResult, error) {
+	return NewCalculators(cc.c, calculators)
+}
+
+func (cc *CalculatorClient) CreateManyWithContext(ctx context.Context, calculators []Calculator) (*CalculatorsResult, error) {
+	return NewCalculatorsWithContext(ctx, cc.c, calculators)
+}
+
+func (cc *CalculatorClient) List(options ...flespiapi.RequestOption) ([]Calculator, error) {
+	return ListCalculators(cc.c, options...)
+}
+
+func (cc *CalculatorClient) ListWithContext(ctx context.Context, options ...flespiapi.RequestOption) ([]Calculator, error) {
+	return ListCalculatorsWithContext(ctx, cc.c, options...)
+}
+
+func (cc *CalculatorClient) ListBySelector(selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Calculator, error) {
+	return ListCalculatorsBySelector(cc.c, selector, options...)
+}
+
+func (cc *CalculatorClient) ListBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Calculator, error) {
+	return ListCalculatorsBySelectorWithContext(ctx, cc.c, selector, options...)
+}
+
+func (cc *CalculatorClient) Iterate(selector flespiapi.Selector, options ...flespiapi.RequestOption) *CalculatorIterator {
+	return IterateCalculators(cc.c, selector, options...)
+}
+
+func (cc *CalculatorClient) IterateWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) *CalculatorIterator {
+	return IterateCalculatorsWithContext(ctx, cc.c, selector, options...)
 }
 
-func (cc *CalculatorClient) Get(calculatorId int64) (*Calculator, error) {
-	return GetCalculator(cc.c, calculatorId)
+func (cc *CalculatorClient) Get(calculatorId int64, options ...flespiapi.RequestOption) (*Calculator, error) {
+	return GetCalculator(cc.c, calculatorId, options...)
+}
+
+func (cc *CalculatorClient) GetWithContext(ctx context.Context, calculatorId int64, options ...flespiapi.RequestOption) (*Calculator, error) {
+	return GetCalculatorWithContext(ctx, cc.c, calculatorId, options...)
 }
 
 func (cc *CalculatorClient) Update(calc Calculator) (*Calculator, error) {
 	return UpdateCalculator(cc.c, calc)
 }
 
+func (cc *CalculatorClient) UpdateWithContext(ctx context.Context, calc Calculator) (*Calculator, error) {
+	return UpdateCalculatorWithContext(ctx, cc.c, calc)
+}
+
 func (cc *CalculatorClient) Delete(calc Calculator) error {
 	return DeleteCalculator(cc.c, calc)
 }
 
+func (cc *CalculatorClient) DeleteWithContext(ctx context.Context, calc Calculator) error {
+	return DeleteCalculatorWithContext(ctx, cc.c, calc)
+}
+
 func (cc *CalculatorClient) DeleteById(calculatorId int64) error {
 	return DeleteCalculatorById(cc.c, calculatorId)
 }
+
+func (cc *CalculatorClient) DeleteByIdWithContext(ctx context.Context, calculatorId int64) error {
+	return DeleteCalculatorByIdWithContext(ctx, cc.c, calculatorId)
+}
+
+func (cc *CalculatorClient) UpdateBySelector(selector flespiapi.Selector, changes map[string]interface{}) (*CalculatorsResult, error) {
+	return UpdateCalculatorsBySelector(cc.c, selector, changes)
+}
+
+func (cc *CalculatorClient) UpdateBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, changes map[string]interface{}) (*CalculatorsResult, error) {
+	return UpdateCalculatorsBySelectorWithContext(ctx, cc.c, selector, changes)
+}
+
+func (cc *CalculatorClient) DeleteBySelector(selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteCalculatorsBySelector(cc.c, selector)
+}
+
+func (cc *CalculatorClient) DeleteBySelectorWithContext(ctx context.Context, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteCalculatorsBySelectorWithContext(ctx, cc.c, selector)
+}
@@ -3,6 +3,8 @@ package flespi_calculator
 import (
 	"encoding/json"
 	"fmt"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
 type Calculator struct {
@@ -131,6 +133,11 @@ func (cs *CalculatorSource) GetSource() string {
 type CreateCalculatorOption func(*Calculator)
 
 func unmarshalMessageSource(raw json.RawMessage) (MessagesSource, error) {
+	// messages_source is absent when the caller projected it away with fields=
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
 	var source struct {
 		Source       string `json:"source"`
 		CalculatorId int64  `json:"calculator_id,omitempty"`
@@ -195,5 +202,12 @@ func WithAccountId(accountId int64) CreateCalculatorOption {
 }
 
 type calculatorsResponse struct {
-	Calculators []Calculator `json:"result"`
+	Calculators []Calculator            `json:"result"`
+	Errors      []flespiapi.ErrorDetail `json:"errors"`
 }
+
+// CalculatorsResult holds the calculators returned by a bulk request and the per-item errors reported by flespi.
+type CalculatorsResult = flespiapi.MultiResult[Calculator]
+
+// CalculatorIterator decodes calculators from a list response one at a time, see IterateCalculators.
+type CalculatorIterator = flespiapi.Iterator[Calculator]
@@ -1,12 +1,20 @@
 package flespi_channel
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
+// channelFields are requested by GetChannel unless a flespiapi.WithFields option overrides them.
+var channelFields = []string{"id", "name", "protocol_id", "protocol_name", "messages_ttl", "enabled", "configuration", "metadata", "cid"}
+
 func NewChannelWithProtocolName(c flespiapi.APIRequester, name string, protocolName string, options ...CreateChannelOption) (*Channel, error) {
+	return NewChannelWithProtocolNameWithContext(context.Background(), c, name, protocolName, options...)
+}
+
+func NewChannelWithProtocolNameWithContext(ctx context.Context, c flespiapi.APIRequester, name string, protocolName string, options ...CreateChannelOption) (*Channel, error) {
 	channel := Channel{
 		Name:          name,
 		ProtocolName:  protocolName,
@@ -18,10 +26,14 @@ func NewChannelWithProtocolName(c flespiapi.APIRequester, name string, protocolN
 		opt(&channel)
 	}
 
-	return newChannel(c, channel)
+	return newChannel(ctx, c, channel)
 }
 
 func NewChannelWithProtocolId(c flespiapi.APIRequester, name string, protocolId int64, options ...CreateChannelOption) (*Channel, error) {
+	return NewChannelWithProtocolIdWithContext(context.Background(), c, name, protocolId, options...)
+}
+
+func NewChannelWithProtocolIdWithContext(ctx context.Context, c flespiapi.APIRequester, name string, protocolId int64, options ...CreateChannelOption) (*Channel, error) {
 	channel := Channel{
 		Name:          name,
 		ProtocolId:    protocolId,
@@ -33,10 +45,10 @@ func NewChannelWithProtocolId(c flespiapi.APIRequester, name string, protocolId
 		opt(&channel)
 	}
 
-	return newChannel(c, channel)
+	return newChannel(ctx, c, channel)
 }
 
-func newChannel(c flespiapi.APIRequester, channel Channel) (*Channel, error) {
+func newChannel(ctx context.Context, c flespiapi.APIRequester, channel Channel) (*Channel, error) {
 	response := channelsResponse{}
 
 	var headers map[string]string
@@ -53,38 +65,121 @@ func newChannel(c flespiapi.APIRequester, channel Channel) (*Channel, error) {
 		channel.AccountId = accountId
 	}()
 
-	if err := c.RequestAPIWithHeaders("POST", "gw/channels", headers, []Channel{channel}, &response); err != nil {
+	if err := c.RequestAPIWithContextAndHeaders(ctx, "POST", "gw/channels", headers, []Channel{channel}, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Channels[0], nil
+	return flespiapi.First(response.Channels, response.Errors)
 }
 
-func ListChannels(c flespiapi.APIRequester) ([]Channel, error) {
-	response := channelsResponse{}
+// NewChannels creates channels in bulk with one POST per subaccount and reports
+// the per-item errors flespi returned next to the created channels.
+func NewChannels(c flespiapi.APIRequester, channels []Channel) (*ChannelsResult, error) {
+	return NewChannelsWithContext(context.Background(), c, channels)
+}
+
+func NewChannelsWithContext(ctx context.Context, c flespiapi.APIRequester, channels []Channel) (*ChannelsResult, error) {
+	result := &ChannelsResult{}
+
+	if len(channels) == 0 {
+		return result, nil
+	}
 
-	err := c.RequestAPI("GET", "gw/channels/all", nil, &response)
+	accountIds, groups := flespiapi.GroupByAccount(channels, func(channel Channel) int64 { return channel.AccountId })
+
+	for _, accountId := range accountIds {
+		var headers map[string]string
+		if accountId != 0 {
+			headers = map[string]string{
+				"x-flespi-cid": fmt.Sprintf("%d", accountId),
+			}
+		}
+
+		// Id is assigned by flespi and AccountId (cid) is conveyed via header;
+		// the groups are copies, so clearing them is safe
+		batch := groups[accountId]
+		for i := range batch {
+			batch[i].Id = 0
+			batch[i].AccountId = 0
+		}
+
+		response := channelsResponse{}
+
+		if err := c.RequestAPIWithContextAndHeaders(ctx, "POST", "gw/channels", headers, batch, &response); err != nil {
+			return result, err
+		}
+
+		result.Append(response.Channels, response.Errors, func(channel Channel) int64 { return channel.Id })
+	}
 
+	return result, nil
+}
+
+func ListChannels(c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Channel, error) {
+	return ListChannelsWithContext(context.Background(), c, options...)
+}
+
+func ListChannelsWithContext(ctx context.Context, c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Channel, error) {
+	return ListChannelsBySelectorWithContext(ctx, c, flespiapi.SelectAll(), options...)
+}
+
+// ListChannelsBySelector returns the channels matched by selector, such as
+// {protocol_name="teltonika"}; the filtering is done by flespi.
+func ListChannelsBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Channel, error) {
+	return ListChannelsBySelectorWithContext(context.Background(), c, selector, options...)
+}
+
+func ListChannelsBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Channel, error) {
+	path, err := selector.Path()
 	if err != nil {
 		return nil, err
 	}
 
+	response := channelsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("gw/channels/%s", path), nil, options...), nil, &response); err != nil {
+		return nil, err
+	}
+
 	return response.Channels, nil
 }
 
-func GetChannel(c flespiapi.APIRequester, channelId int64) (*Channel, error) {
+// IterateChannels walks the channels matched by selector without loading the whole list into memory.
+// Close the iterator when done.
+func IterateChannels(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *ChannelIterator {
+	return IterateChannelsWithContext(context.Background(), c, selector, options...)
+}
+
+func IterateChannelsWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *ChannelIterator {
+	path, err := selector.Path()
+	if err != nil {
+		return flespiapi.FailedIterator[Channel](err)
+	}
+
+	return flespiapi.NewIterator[Channel](ctx, c, flespiapi.Endpoint(fmt.Sprintf("gw/channels/%s", path), nil, options...), nil)
+}
+
+func GetChannel(c flespiapi.APIRequester, channelId int64, options ...flespiapi.RequestOption) (*Channel, error) {
+	return GetChannelWithContext(context.Background(), c, channelId, options...)
+}
+
+func GetChannelWithContext(ctx context.Context, c flespiapi.APIRequester, channelId int64, options ...flespiapi.RequestOption) (*Channel, error) {
 	response := channelsResponse{}
 
-	err := c.RequestAPI("GET", fmt.Sprintf("gw/channels/%d?fields=id,name,protocol_id,protocol_name,messages_ttl,enabled,configuration,metadata,cid", channelId), nil, &response)
+	err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("gw/channels/%d", channelId), channelFields, options...), nil, &response)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.Channels[0], nil
+	return flespiapi.First(response.Channels, response.Errors)
 }
 
 func UpdateChannel(c flespiapi.APIRequester, channel Channel) (*Channel, error) {
+	return UpdateChannelWithContext(context.Background(), c, channel)
+}
+
+func UpdateChannelWithContext(ctx context.Context, c flespiapi.APIRequester, channel Channel) (*Channel, error) {
 	response := channelsResponse{}
 
 	channelId := channel.Id
@@ -109,7 +204,7 @@ func UpdateChannel(c flespiapi.APIRequester, channel Channel) (*Channel, error)
 		}
 	}
 
-	err := c.RequestAPIWithHeaders("PUT", fmt.Sprintf("gw/channels/%d", channelId), headers, channel, &response)
+	err := c.RequestAPIWithContextAndHeaders(ctx, "PUT", fmt.Sprintf("gw/channels/%d", channelId), headers, channel, &response)
 
 	if err != nil {
 		return nil, err
@@ -117,17 +212,68 @@ func UpdateChannel(c flespiapi.APIRequester, channel Channel) (*Channel, error)
 
 	channel.Id = channelId
 
-	return &response.Channels[0], nil
+	return flespiapi.First(response.Channels, response.Errors)
 }
 
 func DeleteChannel(c flespiapi.APIRequester, channel Channel) error {
+	return DeleteChannelWithContext(context.Background(), c, channel)
+}
+
+func DeleteChannelWithContext(ctx context.Context, c flespiapi.APIRequester, channel Channel) error {
 	if channel.Id == 0 {
-		return fmt.Errorf("ID must be provided")
+		return flespiapi.Invalid("ID must be provided")
 	}
 
-	return DeleteChannelById(c, channel.Id)
+	return DeleteChannelByIdWithContext(ctx, c, channel.Id)
 }
 
 func DeleteChannelById(c flespiapi.APIRequester, channelId int64) error {
-	return c.RequestAPI("DELETE", fmt.Sprintf("gw/channels/%d", channelId), nil, nil)
+	return DeleteChannelByIdWithContext(context.Background(), c, channelId)
+}
+
+func DeleteChannelByIdWithContext(ctx context.Context, c flespiapi.APIRequester, channelId int64) error {
+	return c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("gw/channels/%d", channelId), nil, nil)
+}
+
+// UpdateChannelsBySelector sends one PUT that changes only the given keys on every matched channel.
+func UpdateChannelsBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*ChannelsResult, error) {
+	return UpdateChannelsBySelectorWithContext(context.Background(), c, selector, changes)
+}
+
+func UpdateChannelsBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*ChannelsResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	response := channelsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("gw/channels/%s", path), changes, &response); err != nil {
+		return nil, err
+	}
+
+	result := &ChannelsResult{}
+	result.Append(response.Channels, response.Errors, func(channel Channel) int64 { return channel.Id })
+
+	return result, nil
+}
+
+// DeleteChannelsBySelector removes all channels matched by selector in one call.
+func DeleteChannelsBySelector(c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteChannelsBySelectorWithContext(context.Background(), c, selector)
+}
+
+func DeleteChannelsBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &flespiapi.DeleteResult{}
+
+	if err := c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("gw/channels/%s", path), nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
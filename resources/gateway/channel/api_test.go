@@ -1,10 +1,12 @@
 package flespi_channel
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/mixser/flespi-client/internal/flespiapi"
 	"github.com/mixser/flespi-client/internal/testhelper"
 )
 
@@ -208,3 +210,23 @@ func TestDeleteChannel(t *testing.T) {
 		t.Errorf("DeleteChannelById() error = %v", err)
 	}
 }
+
+func TestNewChannels(t *testing.T) {
+	testhelper.CheckBulkCreate(t, func(c flespiapi.APIRequester, ids []int64) (int, []int64, error) {
+		channels := make([]Channel, len(ids))
+		for i, id := range ids {
+			channels[i] = Channel{Id: id, Name: fmt.Sprintf("channels-%d", i)}
+		}
+
+		result, err := NewChannels(c, channels)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		inputIds := make([]int64, len(channels))
+		for i, item := range channels {
+			inputIds[i] = item.Id
+		}
+		return len(result.Items), inputIds, nil
+	})
+}
@@ -1,9 +1,14 @@
 package flespi_channel
 
-import "github.com/mixser/flespi-client/internal/flespiapi"
+import (
+	"context"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
 
 // ChannelClient provides receiver-based methods for managing Flespi channels.
 // Access it via Client.Channels after creating a flespi.Client.
+// Every method has a ...WithContext variant that takes a context.Context first.
 type ChannelClient struct {
 	c flespiapi.APIRequester
 }
@@ -17,26 +22,94 @@ func (cc *ChannelClient) CreateWithProtocolName(name string, protocolName string
 	return NewChannelWithProtocolName(cc.c, name, protocolName, options...)
 }
 
+func (cc *ChannelClient) CreateWithProtocolNameWithContext(ctx context.Context, name string, protocolName string, options ...CreateChannelOption) (*Channel, error) {
+	return NewChannelWithProtocolNameWithContext(ctx, cc.c, name, protocolName, options...)
+}
+
 func (cc *ChannelClient) CreateWithProtocolId(name string, protocolId int64, options ...CreateChannelOption) (*Channel, error) {
 	return NewChannelWithProtocolId(cc.c, name, protocolId, options...)
 }
 
-func (cc *ChannelClient) List() ([]Channel, error) {
-	return ListChannels(cc.c)
+func (cc *ChannelClient) CreateWithProtocolIdWithContext(ctx context.Context, name string, protocolId int64, options ...CreateChannelOption) (*Channel, error) {
+	return NewChannelWithProtocolIdWithContext(ctx, cc.c, name, protocolId, options...)
+}
+
+func (cc *ChannelClient) CreateMany(channels []Channel) (*ChannelsResult, error) {
+	return NewChannels(cc.c, channels)
+}
+
+func (cc *ChannelClient) CreateManyWithContext(ctx context.Context, channels []Channel) (*ChannelsResult, error) {
+	return NewChannelsWithContext(ctx, cc.c, channels)
+}
+
+func (cc *ChannelClient) List(options ...flespiapi.RequestOption) ([]Channel, error) {
+	return ListChannels(cc.c, options...)
 }
 
-func (cc *ChannelClient) Get(channelId int64) (*Channel, error) {
-	return GetChannel(cc.c, channelId)
+func (cc *ChannelClient) ListWithContext(ctx context.Context, options ...flespiapi.RequestOption) ([]Channel, error) {
+	return ListChannelsWithContext(ctx, cc.c, options...)
+}
+
+func (cc *ChannelClient) ListBySelector(selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Channel, error) {
+	return ListChannelsBySelector(cc.c, selector, options...)
+}
+
+func (cc *ChannelClient) ListBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Channel, error) {
+	return ListChannelsBySelectorWithContext(ctx, cc.c, selector, options...)
+}
+
+func (cc *ChannelClient) Iterate(selector flespiapi.Selector, options ...flespiapi.RequestOption) *ChannelIterator {
+	return IterateChannels(cc.c, selector, options...)
+}
+
+func (cc *ChannelClient) IterateWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) *ChannelIterator {
+	return IterateChannelsWithContext(ctx, cc.c, selector, options...)
+}
+
+func (cc *ChannelClient) Get(channelId int64, options ...flespiapi.RequestOption) (*Channel, error) {
+	return GetChannel(cc.c, channelId, options...)
+}
+
+func (cc *ChannelClient) GetWithContext(ctx context.Context, channelId int64, options ...flespiapi.RequestOption) (*Channel, error) {
+	return GetChannelWithContext(ctx, cc.c, channelId, options...)
 }
 
 func (cc *ChannelClient) Update(channel Channel) (*Channel, error) {
 	return UpdateChannel(cc.c, channel)
 }
 
+func (cc *ChannelClient) UpdateWithContext(ctx context.Context, channel Channel) (*Channel, error) {
+	return UpdateChannelWithContext(ctx, cc.c, channel)
+}
+
 func (cc *ChannelClient) Delete(channel Channel) error {
 	return DeleteChannel(cc.c, channel)
 }
 
+func (cc *ChannelClient) DeleteWithContext(ctx context.Context, channel Channel) error {
+	return DeleteChannelWithContext(ctx, cc.c, channel)
+}
+
 func (cc *ChannelClient) DeleteById(channelId int64) error {
 	return DeleteChannelById(cc.c, channelId)
 }
+
+func (cc *ChannelClient) DeleteByIdWithContext(ctx context.Context, channelId int64) error {
+	return DeleteChannelByIdWithContext(ctx, cc.c, channelId)
+}
+
+func (cc *ChannelClient) UpdateBySelector(selector flespiapi.Selector, changes map[string]interface{}) (*ChannelsResult, error) {
+	return UpdateChannelsBySelector(cc.c, selector, changes)
+}
+
+func (cc *ChannelClient) UpdateBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, changes map[string]interface{}) (*ChannelsResult, error) {
+	return UpdateChannelsBySelectorWithContext(ctx, cc.c, selector, changes)
+}
+
+func (cc *ChannelClient) DeleteBySelector(selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteChannelsBySelector(cc.c, selector)
+}
+
+func (cc *ChannelClient) DeleteBySelectorWithContext(ctx context.Context, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteChannelsBySelectorWithContext(ctx, cc.c, selector)
+}
@@ -1,5 +1,7 @@
 package flespi_channel
 
+import "github.com/mixser/flespi-client/internal/flespiapi"
+
 type Channel struct {
 	Id            int64                  `json:"id,omitempty"`
 	Configuration map[string]interface{} `json:"configuration,omitempty"`
@@ -75,5 +77,12 @@ func WithMetadataItem(key, value string) CreateChannelOption {
 }
 
 type channelsResponse struct {
-	Channels []Channel `json:"result"`
+	Channels []Channel               `json:"result"`
+	Errors   []flespiapi.ErrorDetail `json:"errors"`
 }
+
+// ChannelsResult holds the channels returned by a bulk request and the per-item errors reported by flespi.
+type ChannelsResult = flespiapi.MultiResult[Channel]
+
+// ChannelIterator decodes channels from a list response one at a time, see IterateChannels.
+type ChannelIterator = flespiapi.Iterator[Channel]
@@ -1,11 +1,19 @@
 package flespi_device
 
 import (
+	"context"
 	"fmt"
 	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
+// deviceFields are requested by GetDevice unless a flespiapi.WithFields option overrides them.
+var deviceFields = []string{"id", "name", "enabled", "device_type_id", "messages_ttl", "messages_rotate", "media_ttl", "media_rotate", "configuration", "metadata", "cid"}
+
 func NewDevice(c flespiapi.APIRequester, name string, enabled bool, deviceTypeId int64, options ...CreateDeviceOption) (*Device, error) {
+	return NewDeviceWithContext(context.Background(), c, name, enabled, deviceTypeId, options...)
+}
+
+func NewDeviceWithContext(ctx context.Context, c flespiapi.APIRequester, name string, enabled bool, deviceTypeId int64, options ...CreateDeviceOption) (*Device, error) {
 	device := Device{
 		Name:          name,
 		Enabled:       enabled,
@@ -30,38 +38,123 @@ func NewDevice(c flespiapi.APIRequester, name string, enabled bool, deviceTypeId
 	device.AccountId = 0
 	defer func() { device.AccountId = accountId }()
 
-	if err := c.RequestAPIWithHeaders("POST", "gw/devices", headers, []Device{device}, &response); err != nil {
+	if err := c.RequestAPIWithContextAndHeaders(ctx, "POST", "gw/devices", headers, []Device{device}, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Devices[0], nil
+	return flespiapi.First(response.Devices, response.Errors)
 }
 
-func ListDevices(c flespiapi.APIRequester) ([]Device, error) {
-	response := devicesResponse{}
+// NewDevices creates several devices at once. Items are sent in a single request per
+// subaccount (AccountId), so mixing subaccounts costs one extra round-trip each.
+// The result lists every created device along with the per-item errors reported by flespi.
+func NewDevices(c flespiapi.APIRequester, devices []Device) (*DevicesResult, error) {
+	return NewDevicesWithContext(context.Background(), c, devices)
+}
+
+func NewDevicesWithContext(ctx context.Context, c flespiapi.APIRequester, devices []Device) (*DevicesResult, error) {
+	result := &DevicesResult{}
+
+	if len(devices) == 0 {
+		return result, nil
+	}
+
+	accountIds, groups := flespiapi.GroupByAccount(devices, func(device Device) int64 { return device.AccountId })
+
+	for _, accountId := range accountIds {
+		var headers map[string]string
+		if accountId != 0 {
+			headers = map[string]string{
+				"x-flespi-cid": fmt.Sprintf("%d", accountId),
+			}
+		}
+
+		// Id is assigned by flespi and AccountId (cid) is conveyed via header;
+		// the groups are copies, so clearing them is safe
+		batch := groups[accountId]
+		for i := range batch {
+			batch[i].Id = 0
+			batch[i].AccountId = 0
+		}
+
+		response := devicesResponse{}
+
+		if err := c.RequestAPIWithContextAndHeaders(ctx, "POST", "gw/devices", headers, batch, &response); err != nil {
+			return result, err
+		}
+
+		result.Append(response.Devices, response.Errors, func(device Device) int64 { return device.Id })
+	}
 
-	err := c.RequestAPI("GET", "gw/devices/all", nil, &response)
+	return result, nil
+}
+
+func ListDevices(c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Device, error) {
+	return ListDevicesWithContext(context.Background(), c, options...)
+}
+
+func ListDevicesWithContext(ctx context.Context, c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Device, error) {
+	return ListDevicesBySelectorWithContext(ctx, c, flespiapi.SelectAll(), options...)
+}
 
+// ListDevicesBySelector returns the devices matched by selector, e.g. flespi.SelectIds(1, 5, 9)
+// or {metadata.fleet="north"}. Filtering happens on the server.
+func ListDevicesBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Device, error) {
+	return ListDevicesBySelectorWithContext(context.Background(), c, selector, options...)
+}
+
+func ListDevicesBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Device, error) {
+	path, err := selector.Path()
 	if err != nil {
 		return nil, err
 	}
 
+	response := devicesResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("gw/devices/%s", path), nil, options...), nil, &response); err != nil {
+		return nil, err
+	}
+
 	return response.Devices, nil
 }
 
-func GetDevice(c flespiapi.APIRequester, deviceId int64) (*Device, error) {
+// IterateDevices streams the devices matched by selector, decoding one device per Next call;
+// use it instead of ListDevicesBySelector for accounts with many thousands of devices.
+// Close the iterator when done.
+func IterateDevices(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *DeviceIterator {
+	return IterateDevicesWithContext(context.Background(), c, selector, options...)
+}
+
+func IterateDevicesWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *DeviceIterator {
+	path, err := selector.Path()
+	if err != nil {
+		return flespiapi.FailedIterator[Device](err)
+	}
+
+	return flespiapi.NewIterator[Device](ctx, c, flespiapi.Endpoint(fmt.Sprintf("gw/devices/%s", path), nil, options...), nil)
+}
+
+func GetDevice(c flespiapi.APIRequester, deviceId int64, options ...flespiapi.RequestOption) (*Device, error) {
+	return GetDeviceWithContext(context.Background(), c, deviceId, options...)
+}
+
+func GetDeviceWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, options ...flespiapi.RequestOption) (*Device, error) {
 	response := devicesResponse{}
 
-	err := c.RequestAPI("GET", fmt.Sprintf("gw/devices/%d?fields=id,name,enabled,device_type_id,messages_ttl,messages_rotate,media_ttl,media_rotate,configuration,metadata,cid", deviceId), nil, &response)
+	err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("gw/devices/%d", deviceId), deviceFields, options...), nil, &response)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.Devices[0], nil
+	return flespiapi.First(response.Devices, response.Errors)
 }
 
 func UpdateDevice(c flespiapi.APIRequester, device Device) (*Device, error) {
+	return UpdateDeviceWithContext(context.Background(), c, device)
+}
+
+func UpdateDeviceWithContext(ctx context.Context, c flespiapi.APIRequester, device Device) (*Device, error) {
 	response := devicesResponse{}
 
 	deviceId := device.Id
@@ -82,19 +175,27 @@ func UpdateDevice(c flespiapi.APIRequester, device Device) (*Device, error) {
 		}
 	}
 
-	if err := c.RequestAPIWithHeaders("PUT", fmt.Sprintf("gw/devices/%d", deviceId), headers, device, &response); err != nil {
+	if err := c.RequestAPIWithContextAndHeaders(ctx, "PUT", fmt.Sprintf("gw/devices/%d", deviceId), headers, device, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Devices[0], nil
+	return flespiapi.First(response.Devices, response.Errors)
 }
 
 func DeleteDevice(c flespiapi.APIRequester, device Device) error {
-	return DeleteDeviceById(c, device.Id)
+	return DeleteDeviceWithContext(context.Background(), c, device)
+}
+
+func DeleteDeviceWithContext(ctx context.Context, c flespiapi.APIRequester, device Device) error {
+	return DeleteDeviceByIdWithContext(ctx, c, device.Id)
 }
 
 func DeleteDeviceById(c flespiapi.APIRequester, deviceId int64) error {
-	err := c.RequestAPI("DELETE", fmt.Sprintf("gw/devices/%d", deviceId), nil, nil)
+	return DeleteDeviceByIdWithContext(context.Background(), c, deviceId)
+}
+
+func DeleteDeviceByIdWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64) error {
+	err := c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("gw/devices/%d", deviceId), nil, nil)
 
 	if err != nil {
 		return err
@@ -102,3 +203,49 @@ func DeleteDeviceById(c flespiapi.APIRequester, deviceId int64) error {
 
 	return nil
 }
+
+// UpdateDevicesBySelector applies changes to every device matched by selector with a single
+// PUT, e.g. {"enabled": false} for all devices with metadata.state="decommissioned".
+// Only the keys present in changes are modified.
+func UpdateDevicesBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*DevicesResult, error) {
+	return UpdateDevicesBySelectorWithContext(context.Background(), c, selector, changes)
+}
+
+func UpdateDevicesBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*DevicesResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	response := devicesResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("gw/devices/%s", path), changes, &response); err != nil {
+		return nil, err
+	}
+
+	result := &DevicesResult{}
+	result.Append(response.Devices, response.Errors, func(device Device) int64 { return device.Id })
+
+	return result, nil
+}
+
+// DeleteDevicesBySelector deletes every device matched by selector with a single request
+// and reports the ids flespi removed.
+func DeleteDevicesBySelector(c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteDevicesBySelectorWithContext(context.Background(), c, selector)
+}
+
+func DeleteDevicesBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &flespiapi.DeleteResult{}
+
+	if err := c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("gw/devices/%s", path), nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -1,10 +1,14 @@
 package flespi_device
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/mixser/flespi-client/internal/flespiapi"
 	"github.com/mixser/flespi-client/internal/testhelper"
 )
 
@@ -46,6 +50,62 @@ func TestNewDevice(t *testing.T) {
 	}
 }
 
+func TestNewDevices(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		var payload []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+			http.Error(w, `{"errors": [{"reason": "invalid payload"}]}`, http.StatusBadRequest)
+			return
+		}
+		for _, item := range payload {
+			if _, ok := item["cid"]; ok {
+				t.Errorf("cid must not be sent in the body")
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		switch r.Header.Get("x-flespi-cid") {
+		case "":
+			if len(payload) != 2 {
+				t.Errorf("Expected 2 devices without cid, got %d", len(payload))
+			}
+			w.Write([]byte(`{"result": [{"id": 1, "name": "a"}], "errors": [{"reason": "ident already used"}]}`))
+		case "42":
+			w.Write([]byte(`{"result": [{"id": 3, "name": "c", "cid": 42}]}`))
+		default:
+			t.Errorf("Unexpected x-flespi-cid %q", r.Header.Get("x-flespi-cid"))
+		}
+	}))
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	devices := []Device{
+		{Name: "a", DeviceTypeId: 1},
+		{Name: "b", DeviceTypeId: 1},
+		{Name: "c", DeviceTypeId: 1, AccountId: 42},
+	}
+
+	result, err := NewDevices(client, devices)
+	if err != nil {
+		t.Fatalf("NewDevices() error = %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+	if len(result.Items) != 2 || !result.HasErrors() {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if devices[2].AccountId != 42 {
+		t.Errorf("NewDevices() must not modify the input slice")
+	}
+}
+
 func TestGetDevice(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -76,6 +136,52 @@ func TestGetDevice(t *testing.T) {
 	}
 }
 
+func TestGetDevice_WithFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fields := r.URL.Query().Get("fields"); fields != "id,name,cid" {
+			t.Errorf("Expected fields=id,name,cid, got %q", fields)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": [{"id": 789, "name": "test-device", "cid": 12}]}`))
+	}))
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	device, err := GetDevice(client, 789, flespiapi.WithFields("id", "name", "cid"))
+	if err != nil {
+		t.Fatalf("GetDevice() error = %v", err)
+	}
+
+	if device.AccountId != 12 || device.Configuration != nil {
+		t.Errorf("Unexpected device %+v", device)
+	}
+}
+
+func TestGetDevice_EmptyResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": [], "errors": [{"id": 789, "reason": "no access"}]}`))
+	}))
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	device, err := GetDevice(client, 789)
+	if device != nil {
+		t.Errorf("Expected nil device, got %+v", device)
+	}
+
+	var partialErr *flespiapi.PartialError
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("Expected *flespiapi.PartialError, got %v", err)
+	}
+	if partialErr.Errors[0].Reason != "no access" {
+		t.Errorf("Unexpected errors %+v", partialErr.Errors)
+	}
+}
+
 func TestListDevices(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -114,6 +220,33 @@ func TestListDevices(t *testing.T) {
 	}
 }
 
+func TestListDevicesBySelector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != `/gw/devices/{metadata.fleet="north"}` {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": [{"id": 7, "name": "truck7", "device_type_id": 1, "configuration": {}}]}`))
+	}))
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	devices, err := ListDevicesBySelector(client, flespiapi.SelectWhere(flespiapi.Eq("metadata.fleet", "north")))
+	if err != nil {
+		t.Fatalf("ListDevicesBySelector() error = %v", err)
+	}
+
+	if len(devices) != 1 || devices[0].Id != 7 {
+		t.Errorf("Expected device 7, got %+v", devices)
+	}
+
+	if _, err := ListDevicesBySelector(client, flespiapi.SelectIds()); err == nil {
+		t.Errorf("Expected error for empty selector")
+	}
+}
+
 func TestUpdateDevice(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPut {
@@ -168,3 +301,132 @@ func TestDeleteDevice(t *testing.T) {
 		t.Errorf("DeleteDeviceById() error = %v", err)
 	}
 }
+
+func TestUpdateDevicesBySelector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("Expected PUT request, got %s", r.Method)
+		}
+		if r.URL.Path != `/gw/devices/{metadata.state="decommissioned"}` {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+			http.Error(w, `{"errors": [{"reason": "invalid payload"}]}`, http.StatusBadRequest)
+			return
+		}
+		if len(payload) != 1 || payload["enabled"] != false {
+			t.Errorf("Expected only enabled=false, got %v", payload)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": [{"id": 4, "enabled": false}, {"id": 9, "enabled": false}]}`))
+	}))
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	selector := flespiapi.SelectWhere(flespiapi.Eq("metadata.state", "decommissioned"))
+
+	result, err := UpdateDevicesBySelector(client, selector, map[string]interface{}{"enabled": false})
+	if err != nil {
+		t.Fatalf("UpdateDevicesBySelector() error = %v", err)
+	}
+
+	if len(result.Ids) != 2 || result.Ids[0] != 4 || result.Ids[1] != 9 {
+		t.Errorf("Expected ids [4 9], got %v", result.Ids)
+	}
+}
+
+func TestDeleteDevicesBySelector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("Expected DELETE request, got %s", r.Method)
+		}
+		if r.URL.Path != "/gw/devices/1,2,3" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": [{"id": 1}, {"id": 3}], "errors": [{"id": 2, "reason": "access denied"}]}`))
+	}))
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	result, err := DeleteDevicesBySelector(client, flespiapi.SelectIds(1, 2, 3))
+	if err != nil {
+		t.Fatalf("DeleteDevicesBySelector() error = %v", err)
+	}
+
+	if len(result.Ids) != 2 || !result.HasErrors() || result.Errors[0].ID != 2 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestIterateDevices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gw/devices/{name=truck*}" {
+			t.Errorf("Expected path /gw/devices/{name=truck*}, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("fields") != "id,name" {
+			t.Errorf("Expected fields=id,name, got %s", r.URL.Query().Get("fields"))
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": [{"id": 1, "name": "truck-1"}, {"id": 2, "name": "truck-2"}]}`))
+	}))
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	it := IterateDevices(client, flespiapi.SelectExpr("name=truck*"), flespiapi.WithFields("id", "name"))
+	defer it.Close()
+
+	var names []string
+	for it.Next() {
+		names = append(names, it.Value().Name)
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatalf("IterateDevices() error = %v", err)
+	}
+
+	if len(names) != 2 || names[1] != "truck-2" {
+		t.Errorf("Expected [truck-1 truck-2], got %v", names)
+	}
+}
+
+func TestIterateDevices_EmptySelector(t *testing.T) {
+	it := IterateDevices(testhelper.New("http://unused"), flespiapi.Selector{})
+
+	if it.Next() {
+		t.Error("Expected no devices")
+	}
+
+	if !errors.Is(it.Err(), flespiapi.ErrValidation) {
+		t.Errorf("Expected a validation error, got %v", it.Err())
+	}
+}
+
+func TestNewDevices_Ids(t *testing.T) {
+	testhelper.CheckBulkCreate(t, func(c flespiapi.APIRequester, ids []int64) (int, []int64, error) {
+		devices := make([]Device, len(ids))
+		for i, id := range ids {
+			devices[i] = Device{Id: id, Name: fmt.Sprintf("devices-%d", i)}
+		}
+
+		result, err := NewDevices(c, devices)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		inputIds := make([]int64, len(devices))
+		for i, item := range devices {
+			inputIds[i] = item.Id
+		}
+		return len(result.Items), inputIds, nil
+	})
+}
@@ -1,9 +1,16 @@
 package flespi_device
 
-import "github.com/mixser/flespi-client/internal/flespiapi"
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
 
 // DeviceClient provides receiver-based methods for managing Flespi devices.
 // Access it via Client.Devices after creating a flespi.Client.
+// Every method has a ...WithContext variant that takes a context.Context first.
 type DeviceClient struct {
 	c flespiapi.APIRequester
 }
@@ -17,22 +24,286 @@ func (dc *DeviceClient) Create(name string, enabled bool, deviceTypeId int64, op
 	return NewDevice(dc.c, name, enabled, deviceTypeId, options...)
 }
 
-func (dc *DeviceClient) List() ([]Device, error) {
-	return ListDevices(dc.c)
+func (dc *DeviceClient) CreateWithContext(ctx context.Context, name string, enabled bool, deviceTypeId int64, options ...CreateDeviceOption) (*Device, error) {
+	return NewDeviceWithContext(ctx, dc.c, name, enabled, deviceTypeId, options...)
+}
+
+func (dc *DeviceClient) CreateMany(devices []Device) (*DevicesResult, error) {
+	return NewDevices(dc.c, devices)
+}
+
+func (dc *DeviceClient) CreateManyWithContext(ctx context.Context, devices []Device) (*DevicesResult, error) {
+	return NewDevicesWithContext(ctx, dc.c, devices)
+}
+
+func (dc *DeviceClient) List(options ...flespiapi.RequestOption) ([]Device, error) {
+	return ListDevices(dc.c, options...)
+}
+
+func (dc *DeviceClient) ListWithContext(ctx context.Context, options ...flespiapi.RequestOption) ([]Device, error) {
+	return ListDevicesWithContext(ctx, dc.c, options...)
+}
+
+func (dc *DeviceClient) ListBySelector(selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Device, error) {
+	return ListDevicesBySelector(dc.c, selector, options...)
+}
+
+func (dc *DeviceClient) ListBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Device, error) {
+	return ListDevicesBySelectorWithContext(ctx, dc.c, selector, options...)
+}
+
+func (dc *DeviceClient) Iterate(selector flespiapi.Selector, options ...flespiapi.RequestOption) *DeviceIterator {
+	return IterateDevices(dc.c, selector, options...)
+}
+
+func (dc *DeviceClient) IterateWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) *DeviceIterator {
+	return IterateDevicesWithContext(ctx, dc.c, selector, options...)
 }
 
-func (dc *DeviceClient) Get(deviceId int64) (*Device, error) {
-	return GetDevice(dc.c, deviceId)
+func (dc *DeviceClient) Get(deviceId int64, options ...flespiapi.RequestOption) (*Device, error) {
+	return GetDevice(dc.c, deviceId, options...)
+}
+
+func (dc *DeviceClient) GetWithContext(ctx context.Context, deviceId int64, options ...flespiapi.RequestOption) (*Device, error) {
+	return GetDeviceWithContext(ctx, dc.c, deviceId, options...)
 }
 
 func (dc *DeviceClient) Update(device Device) (*Device, error) {
 	return UpdateDevice(dc.c, device)
 }
 
+func (dc *DeviceClient) UpdateWithContext(ctx context.Context, device Device) (*Device, error) {
+	return UpdateDeviceWithContext(ctx, dc.c, device)
+}
+
 func (dc *DeviceClient) Delete(device Device) error {
 	return DeleteDevice(dc.c, device)
 }
 
+func (dc *DeviceClient) DeleteWithContext(ctx context.Context, device Device) error {
+	return DeleteDeviceWithContext(ctx, dc.c, device)
+}
+
 func (dc *DeviceClient) DeleteById(deviceId int64) error {
 	return DeleteDeviceById(dc.c, deviceId)
 }
+
+func (dc *DeviceClient) DeleteByIdWithContext(ctx context.Context, deviceId int64) error {
+	return DeleteDeviceByIdWithContext(ctx, dc.c, deviceId)
+}
+
+func (dc *DeviceClient) UpdateBySelector(selector flespiapi.Selector, changes map[string]interface{}) (*DevicesResult, error) {
+	return UpdateDevicesBySelector(dc.c, selector, changes)
+}
+
+func (dc *DeviceClient) UpdateBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, changes map[string]interface{}) (*DevicesResult, error) {
+	return UpdateDevicesBySelectorWithContext(ctx, dc.c, selector, changes)
+}
+
+func (dc *DeviceClient) DeleteBySelector(selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteDevicesBySelector(dc.c, selector)
+}
+
+func (dc *DeviceClient) DeleteBySelectorWithContext(ctx context.Context, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteDevicesBySelectorWithContext(ctx, dc.c, selector)
+}
+
+func (dc *DeviceClient) Messages(deviceId int64, options ...MessagesOption) ([]Message, error) {
+	return GetDeviceMessages(dc.c, deviceId, options...)
+}
+
+func (dc *DeviceClient) MessagesWithContext(ctx context.Context, deviceId int64, options ...MessagesOption) ([]Message, error) {
+	return GetDeviceMessagesWithContext(ctx, dc.c, deviceId, options...)
+}
+
+func (dc *DeviceClient) IterateMessages(deviceId int64, options ...MessagesOption) *MessageIterator {
+	return IterateDeviceMessages(dc.c, deviceId, options...)
+}
+
+func (dc *DeviceClient) IterateMessagesWithContext(ctx context.Context, deviceId int64, options ...MessagesOption) *MessageIterator {
+	return IterateDeviceMessagesWithContext(ctx, dc.c, deviceId, options...)
+}
+
+func (dc *DeviceClient) Telemetry(deviceId int64, params ...string) (*Telemetry, error) {
+	return GetDeviceTelemetry(dc.c, deviceId, params...)
+}
+
+func (dc *DeviceClient) TelemetryWithContext(ctx context.Context, deviceId int64, params ...string) (*Telemetry, error) {
+	return GetDeviceTelemetryWithContext(ctx, dc.c, deviceId, params...)
+}
+
+func (dc *DeviceClient) TelemetryBySelector(selector flespiapi.Selector, params ...string) ([]Telemetry, error) {
+	return ListDevicesTelemetryBySelector(dc.c, selector, params...)
+}
+
+func (dc *DeviceClient) TelemetryBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, params ...string) ([]Telemetry, error) {
+	return ListDevicesTelemetryBySelectorWithContext(ctx, dc.c, selector, params...)
+}
+
+func (dc *DeviceClient) SendCommand(deviceId int64, command Command) (*CommandResult, error) {
+	return SendDeviceCommand(dc.c, deviceId, command)
+}
+
+func (dc *DeviceClient) SendCommandWithContext(ctx context.Context, deviceId int64, command Command) (*CommandResult, error) {
+	return SendDeviceCommandWithContext(ctx, dc.c, deviceId, command)
+}
+
+func (dc *DeviceClient) QueueCommand(deviceId int64, command Command) (*Command, error) {
+	return QueueDeviceCommand(dc.c, deviceId, command)
+}
+
+func (dc *DeviceClient) QueueCommandWithContext(ctx context.Context, deviceId int64, command Command) (*Command, error) {
+	return QueueDeviceCommandWithContext(ctx, dc.c, deviceId, command)
+}
+
+func (dc *DeviceClient) QueueCommandBySelector(selector flespiapi.Selector, command Command) (*CommandsResult, error) {
+	return QueueDevicesCommandBySelector(dc.c, selector, command)
+}
+
+func (dc *DeviceClient) QueueCommandBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, command Command) (*CommandsResult, error) {
+	return QueueDevicesCommandBySelectorWithContext(ctx, dc.c, selector, command)
+}
+
+func (dc *DeviceClient) QueueCommandAndWait(deviceId int64, command Command, pollInterval time.Duration) (*CommandResult, error) {
+	return QueueDeviceCommandAndWait(dc.c, deviceId, command, pollInterval)
+}
+
+func (dc *DeviceClient) QueueCommandAndWaitWithContext(ctx context.Context, deviceId int64, command Command, pollInterval time.Duration) (*CommandResult, error) {
+	return QueueDeviceCommandAndWaitWithContext(ctx, dc.c, deviceId, command, pollInterval)
+}
+
+func (dc *DeviceClient) QueuedCommands(deviceId int64) ([]Command, error) {
+	return ListDeviceQueuedCommands(dc.c, deviceId)
+}
+
+func (dc *DeviceClient) QueuedCommandsWithContext(ctx context.Context, deviceId int64) ([]Command, error) {
+	return ListDeviceQueuedCommandsWithContext(ctx, dc.c, deviceId)
+}
+
+func (dc *DeviceClient) CancelCommand(deviceId int64, commandId int64) error {
+	return DeleteDeviceQueuedCommand(dc.c, deviceId, commandId)
+}
+
+func (dc *DeviceClient) CancelCommandWithContext(ctx context.Context, deviceId int64, commandId int64) error {
+	return DeleteDeviceQueuedCommandWithContext(ctx, dc.c, deviceId, commandId)
+}
+
+func (dc *DeviceClient) CommandResults(deviceId int64) ([]CommandResult, error) {
+	return ListDeviceCommandResults(dc.c, deviceId)
+}
+
+func (dc *DeviceClient) CommandResultsWithContext(ctx context.Context, deviceId int64) ([]CommandResult, error) {
+	return ListDeviceCommandResultsWithContext(ctx, dc.c, deviceId)
+}
+
+func (dc *DeviceClient) CommandResult(deviceId int64, commandId int64) (*CommandResult, error) {
+	return GetDeviceCommandResult(dc.c, deviceId, commandId)
+}
+
+func (dc *DeviceClient) CommandResultWithContext(ctx context.Context, deviceId int64, commandId int64) (*CommandResult, error) {
+	return GetDeviceCommandResultWithContext(ctx, dc.c, deviceId, commandId)
+}
+
+func (dc *DeviceClient) Settings(deviceId int64) ([]Setting, error) {
+	return ListDeviceSettings(dc.c, deviceId)
+}
+
+func (dc *DeviceClient) SettingsWithContext(ctx context.Context, deviceId int64) ([]Setting, error) {
+	return ListDeviceSettingsWithContext(ctx, dc.c, deviceId)
+}
+
+func (dc *DeviceClient) Setting(deviceId int64, name string) (*Setting, error) {
+	return GetDeviceSetting(dc.c, deviceId, name)
+}
+
+func (dc *DeviceClient) SettingWithContext(ctx context.Context, deviceId int64, name string) (*Setting, error) {
+	return GetDeviceSettingWithContext(ctx, dc.c, deviceId, name)
+}
+
+func (dc *DeviceClient) SetSetting(deviceId int64, name string, value interface{}) (*Setting, error) {
+	return SetDeviceSetting(dc.c, deviceId, name, value)
+}
+
+func (dc *DeviceClient) SetSettingWithContext(ctx context.Context, deviceId int64, name string, value interface{}) (*Setting, error) {
+	return SetDeviceSettingWithContext(ctx, dc.c, deviceId, name, value)
+}
+
+func (dc *DeviceClient) ResetSetting(deviceId int64, name string) error {
+	return ResetDeviceSetting(dc.c, deviceId, name)
+}
+
+func (dc *DeviceClient) ResetSettingWithContext(ctx context.Context, deviceId int64, name string) error {
+	return ResetDeviceSettingWithContext(ctx, dc.c, deviceId, name)
+}
+
+func (dc *DeviceClient) SetSettingBySelector(selector flespiapi.Selector, name string, value interface{}) (*SettingsResult, error) {
+	return SetDevicesSettingBySelector(dc.c, selector, name, value)
+}
+
+func (dc *DeviceClient) SetSettingBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, name string, value interface{}) (*SettingsResult, error) {
+	return SetDevicesSettingBySelectorWithContext(ctx, dc.c, selector, name, value)
+}
+
+func (dc *DeviceClient) ResetSettingBySelector(selector flespiapi.Selector, name string) error {
+	return ResetDevicesSettingBySelector(dc.c, selector, name)
+}
+
+func (dc *DeviceClient) ResetSettingBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, name string) error {
+	return ResetDevicesSettingBySelectorWithContext(ctx, dc.c, selector, name)
+}
+
+func (dc *DeviceClient) SettingsSchema(deviceTypeId int64) ([]SettingSchema, error) {
+	return GetDeviceTypeSettingsSchema(dc.c, deviceTypeId)
+}
+
+func (dc *DeviceClient) SettingsSchemaWithContext(ctx context.Context, deviceTypeId int64) ([]SettingSchema, error) {
+	return GetDeviceTypeSettingsSchemaWithContext(ctx, dc.c, deviceTypeId)
+}
+
+func (dc *DeviceClient) Media(deviceId int64, from, to time.Time) ([]Media, error) {
+	return ListDeviceMedia(dc.c, deviceId, from, to)
+}
+
+func (dc *DeviceClient) MediaWithContext(ctx context.Context, deviceId int64, from, to time.Time) ([]Media, error) {
+	return ListDeviceMediaWithContext(ctx, dc.c, deviceId, from, to)
+}
+
+func (dc *DeviceClient) MediaFile(deviceId int64, uuid string) (*Media, error) {
+	return GetDeviceMedia(dc.c, deviceId, uuid)
+}
+
+func (dc *DeviceClient) MediaFileWithContext(ctx context.Context, deviceId int64, uuid string) (*Media, error) {
+	return GetDeviceMediaWithContext(ctx, dc.c, deviceId, uuid)
+}
+
+func (dc *DeviceClient) DeleteMedia(deviceId int64, uuid string) error {
+	return DeleteDeviceMedia(dc.c, deviceId, uuid)
+}
+
+func (dc *DeviceClient) DeleteMediaWithContext(ctx context.Context, deviceId int64, uuid string) error {
+	return DeleteDeviceMediaWithContext(ctx, dc.c, deviceId, uuid)
+}
+
+func (dc *DeviceClient) DownloadMedia(deviceId int64, uuid string, w io.Writer) (int64, error) {
+	return DownloadDeviceMedia(dc.c, deviceId, uuid, w)
+}
+
+func (dc *DeviceClient) DownloadMediaWithContext(ctx context.Context, deviceId int64, uuid string, w io.Writer) (int64, error) {
+	return DownloadDeviceMediaWithContext(ctx, dc.c, deviceId, uuid, w)
+}
+
+func (dc *DeviceClient) DownloadMediaFrom(deviceId int64, uuid string, w io.Writer, offset int64) (int64, error) {
+	return DownloadDeviceMediaFrom(dc.c, deviceId, uuid, w, offset)
+}
+
+func (dc *DeviceClient) DownloadMediaFromWithContext(ctx context.Context, deviceId int64, uuid string, w io.Writer, offset int64) (int64, error) {
+	return DownloadDeviceMediaFromWithContext(ctx, dc.c, deviceId, uuid, w, offset)
+}
+
+func (dc *DeviceClient) UploadMedia(deviceId int64, name string, content io.Reader) (*Media, error) {
+	return UploadDeviceMedia(dc.c, deviceId, name, content)
+}
+
+func (dc *DeviceClient) UploadMediaWithContext(ctx context.Context, deviceId int64, name string, content io.Reader) (*Media, error) {
+	return UploadDeviceMediaWithContext(ctx, dc.c, deviceId, name, content)
+}
@@ -1,9 +1,11 @@
 package flespi_device
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/mixser/flespi-client/internal/testhelper"
 )
@@ -78,3 +80,26 @@ func TestDeviceClient_DeleteById(t *testing.T) {
 		t.Fatalf("DeleteById() error = %v", err)
 	}
 }
+
+func TestDeviceClient_GetWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	c := testhelper.New(server.URL)
+	dc := NewDeviceClient(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := dc.GetWithContext(ctx, 42); err == nil {
+		t.Fatalf("expected deadline error, got nil")
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("expected context deadline to be exceeded, got %v", ctx.Err())
+	}
+}
@@ -0,0 +1,278 @@
+package flespi_device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
+
+// defaultCommandPollInterval is how often QueueDeviceCommandAndWait checks for a result
+const defaultCommandPollInterval = 2 * time.Second
+
+// ErrCommandExpired is returned by QueueDeviceCommandAndWait when the command left
+// the queue without a result, e.g. because its TTL passed. It matches ErrNotFound.
+var ErrCommandExpired = fmt.Errorf("command left the queue without a result (%w)", flespiapi.ErrNotFound)
+
+// Command is a command for a device, e.g. {Name: "custom", Properties: {"text": "setdigout 1"}}.
+// The properties a command takes depend on the device type.
+type Command struct {
+	Id       int64 `json:"id,omitempty"`
+	DeviceId int64 `json:"device_id,omitempty"`
+
+	Name       string                 `json:"name"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+
+	// Address selects the connection the command is sent over, for devices with several
+	Address string `json:"address,omitempty"`
+
+	// TTL is how long, in seconds, a queued command waits for the device to connect
+	TTL int64 `json:"ttl,omitempty"`
+
+	// Priority orders queued commands; higher values are sent first
+	Priority int64 `json:"priority,omitempty"`
+
+	// Condition is a flespi expression the device's messages must match before a queued command is sent
+	Condition string `json:"condition,omitempty"`
+
+	// Timeout is how long, in seconds, an immediate command waits for the device's answer
+	Timeout int64 `json:"timeout,omitempty"`
+
+	// Timestamp is when the command was queued (unix time, set by flespi)
+	Timestamp float64 `json:"timestamp,omitempty"`
+}
+
+// CommandResult is the outcome of a command the device received
+type CommandResult struct {
+	Id       int64 `json:"id"`
+	DeviceId int64 `json:"device_id"`
+
+	Name       string                 `json:"name"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+
+	// Executed reports whether the device confirmed the command
+	Executed bool `json:"executed"`
+
+	// Response is the device's answer, if the protocol carries one
+	Response interface{} `json:"response,omitempty"`
+
+	// Timestamp is when the result was received (unix time)
+	Timestamp float64 `json:"timestamp"`
+}
+
+// Time returns when the result was received
+func (r *CommandResult) Time() time.Time {
+	return unixSeconds(r.Timestamp)
+}
+
+type commandsResponse struct {
+	Commands []Command               `json:"result"`
+	Errors   []flespiapi.ErrorDetail `json:"errors"`
+}
+
+type commandResultsResponse struct {
+	Results []CommandResult         `json:"result"`
+	Errors  []flespiapi.ErrorDetail `json:"errors"`
+}
+
+// CommandsResult holds the commands queued by a bulk request and the per-item errors reported by flespi.
+type CommandsResult = flespiapi.MultiResult[Command]
+
+// SendDeviceCommand sends a command to a connected device right away and
+// returns its result; set Command.Timeout to wait longer for the answer.
+func SendDeviceCommand(c flespiapi.APIRequester, deviceId int64, command Command) (*CommandResult, error) {
+	return SendDeviceCommandWithContext(context.Background(), c, deviceId, command)
+}
+
+func SendDeviceCommandWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, command Command) (*CommandResult, error) {
+	if err := validateCommand(deviceId, command); err != nil {
+		return nil, err
+	}
+
+	response := commandResultsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "POST", fmt.Sprintf("gw/devices/%d/commands", deviceId), []Command{command}, &response); err != nil {
+		return nil, err
+	}
+
+	return flespiapi.First(response.Results, response.Errors)
+}
+
+// QueueDeviceCommand puts a command in the device's queue; it is sent when the
+// device connects, or dropped when its TTL passes.
+func QueueDeviceCommand(c flespiapi.APIRequester, deviceId int64, command Command) (*Command, error) {
+	return QueueDeviceCommandWithContext(context.Background(), c, deviceId, command)
+}
+
+func QueueDeviceCommandWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, command Command) (*Command, error) {
+	if err := validateCommand(deviceId, command); err != nil {
+		return nil, err
+	}
+
+	response := commandsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "POST", fmt.Sprintf("gw/devices/%d/commands-queue", deviceId), []Command{command}, &response); err != nil {
+		return nil, err
+	}
+
+	return flespiapi.First(response.Commands, response.Errors)
+}
+
+// QueueDevicesCommandBySelector queues the same command for every device matched by selector,
+// e.g. a configuration push to all devices with metadata.fleet="north".
+func QueueDevicesCommandBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, command Command) (*CommandsResult, error) {
+	return QueueDevicesCommandBySelectorWithContext(context.Background(), c, selector, command)
+}
+
+func QueueDevicesCommandBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, command Command) (*CommandsResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+	if command.Name == "" {
+		return nil, flespiapi.Invalid("command name must be provided")
+	}
+
+	response := commandsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "POST", fmt.Sprintf("gw/devices/%s/commands-queue", path), []Command{command}, &response); err != nil {
+		return nil, err
+	}
+
+	result := &CommandsResult{}
+	result.Append(response.Commands, response.Errors, func(command Command) int64 { return command.Id })
+
+	return result, nil
+}
+
+// ListDeviceQueuedCommands returns the commands waiting in the device's queue
+func ListDeviceQueuedCommands(c flespiapi.APIRequester, deviceId int64) ([]Command, error) {
+	return ListDeviceQueuedCommandsWithContext(context.Background(), c, deviceId)
+}
+
+func ListDeviceQueuedCommandsWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64) ([]Command, error) {
+	response := commandsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", fmt.Sprintf("gw/devices/%d/commands-queue/all", deviceId), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Commands, nil
+}
+
+// GetDeviceQueuedCommand returns a queued command; it matches ErrNotFound once the command left the queue
+func GetDeviceQueuedCommand(c flespiapi.APIRequester, deviceId int64, commandId int64) (*Command, error) {
+	return GetDeviceQueuedCommandWithContext(context.Background(), c, deviceId, commandId)
+}
+
+func GetDeviceQueuedCommandWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, commandId int64) (*Command, error) {
+	response := commandsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", fmt.Sprintf("gw/devices/%d/commands-queue/%d", deviceId, commandId), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return flespiapi.First(response.Commands, response.Errors)
+}
+
+// DeleteDeviceQueuedCommand cancels a queued command
+func DeleteDeviceQueuedCommand(c flespiapi.APIRequester, deviceId int64, commandId int64) error {
+	return DeleteDeviceQueuedCommandWithContext(context.Background(), c, deviceId, commandId)
+}
+
+func DeleteDeviceQueuedCommandWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, commandId int64) error {
+	return c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("gw/devices/%d/commands-queue/%d", deviceId, commandId), nil, nil)
+}
+
+// ListDeviceCommandResults returns the results of the commands the device received
+func ListDeviceCommandResults(c flespiapi.APIRequester, deviceId int64) ([]CommandResult, error) {
+	return ListDeviceCommandResultsWithContext(context.Background(), c, deviceId)
+}
+
+func ListDeviceCommandResultsWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64) ([]CommandResult, error) {
+	response := commandResultsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", fmt.Sprintf("gw/devices/%d/commands-result/all", deviceId), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Results, nil
+}
+
+// GetDeviceCommandResult returns the result of a command; it matches ErrNotFound while there is none yet
+func GetDeviceCommandResult(c flespiapi.APIRequester, deviceId int64, commandId int64) (*CommandResult, error) {
+	return GetDeviceCommandResultWithContext(context.Background(), c, deviceId, commandId)
+}
+
+func GetDeviceCommandResultWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, commandId int64) (*CommandResult, error) {
+	response := commandResultsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", fmt.Sprintf("gw/devices/%d/commands-result/%d", deviceId, commandId), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return flespiapi.First(response.Results, response.Errors)
+}
+
+// QueueDeviceCommandAndWait queues a command and polls every pollInterval
+// (default: 2 seconds) until its result arrives. It returns ErrCommandExpired
+// if the command leaves the queue without a result, and the context's error
+// when ctx is done first; the command stays queued in that case.
+func QueueDeviceCommandAndWait(c flespiapi.APIRequester, deviceId int64, command Command, pollInterval time.Duration) (*CommandResult, error) {
+	return QueueDeviceCommandAndWaitWithContext(context.Background(), c, deviceId, command, pollInterval)
+}
+
+func QueueDeviceCommandAndWaitWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, command Command, pollInterval time.Duration) (*CommandResult, error) {
+	if pollInterval <= 0 {
+		pollInterval = defaultCommandPollInterval
+	}
+
+	queued, err := QueueDeviceCommandWithContext(ctx, c, deviceId, command)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for command %d: %w", queued.Id, ctx.Err())
+		case <-ticker.C:
+		}
+
+		result, err := GetDeviceCommandResultWithContext(ctx, c, deviceId, queued.Id)
+		if err == nil {
+			return result, nil
+		}
+		if !errors.Is(err, flespiapi.ErrNotFound) {
+			return nil, err
+		}
+
+		// no result yet: still queued, or dropped
+		if _, err := GetDeviceQueuedCommandWithContext(ctx, c, deviceId, queued.Id); err != nil {
+			if !errors.Is(err, flespiapi.ErrNotFound) {
+				return nil, err
+			}
+
+			// the result may have arrived between the two requests
+			if result, err := GetDeviceCommandResultWithContext(ctx, c, deviceId, queued.Id); err == nil {
+				return result, nil
+			}
+			return nil, ErrCommandExpired
+		}
+	}
+}
+
+func validateCommand(deviceId int64, command Command) error {
+	if deviceId == 0 {
+		return flespiapi.Invalid("device ID must be provided")
+	}
+	if command.Name == "" {
+		return flespiapi.Invalid("command name must be provided")
+	}
+	return nil
+}
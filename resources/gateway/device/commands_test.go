@@ -0,0 +1,128 @@
+package flespi_device
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+	"github.com/mixser/flespi-client/internal/testhelper"
+)
+
+func TestSendDeviceCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/gw/devices/7/commands" {
+			t.Errorf("Expected POST /gw/devices/7/commands, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var commands []Command
+		json.NewDecoder(r.Body).Decode(&commands)
+		if len(commands) != 1 || commands[0].Properties["text"] != "setdigout 1" || commands[0].Timeout != 10 {
+			t.Errorf("Expected the command in an array, got %+v", commands)
+		}
+
+		w.Write([]byte(`{"result": [{"id": 5, "device_id": 7, "name": "custom", "executed": true, "response": "DOUT1:1", "timestamp": 1700000000}]}`))
+	}))
+	defer server.Close()
+
+	result, err := SendDeviceCommand(testhelper.New(server.URL), 7, Command{
+		Name:       "custom",
+		Properties: map[string]interface{}{"text": "setdigout 1"},
+		Timeout:    10,
+	})
+	if err != nil {
+		t.Fatalf("SendDeviceCommand() error = %v", err)
+	}
+	if !result.Executed || result.Response != "DOUT1:1" || !result.Time().Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Expected the executed result, got %+v", result)
+	}
+
+	if _, err := SendDeviceCommand(testhelper.New(server.URL), 7, Command{}); !errors.Is(err, flespiapi.ErrValidation) {
+		t.Errorf("Expected a validation error without a name, got %v", err)
+	}
+}
+
+// commandQueueServer queues command 5 and reports its result after polls result requests
+func commandQueueServer(t *testing.T, polls int32, dropped bool) *httptest.Server {
+	var resultRequests int32
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/gw/devices/7/commands-queue":
+			w.Write([]byte(`{"result": [{"id": 5, "device_id": 7, "name": "custom"}]}`))
+		case r.URL.Path == "/gw/devices/7/commands-result/5":
+			if dropped || atomic.AddInt32(&resultRequests, 1) < polls {
+				w.Write([]byte(`{"result": []}`))
+				return
+			}
+			w.Write([]byte(`{"result": [{"id": 5, "device_id": 7, "name": "custom", "executed": true}]}`))
+		case r.URL.Path == "/gw/devices/7/commands-queue/5":
+			if dropped {
+				w.Write([]byte(`{"result": []}`))
+				return
+			}
+			w.Write([]byte(`{"result": [{"id": 5, "device_id": 7, "name": "custom"}]}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+}
+
+func TestQueueDeviceCommandAndWait(t *testing.T) {
+	server := commandQueueServer(t, 3, false)
+	defer server.Close()
+
+	result, err := QueueDeviceCommandAndWait(testhelper.New(server.URL), 7, Command{Name: "custom"}, time.Millisecond)
+	if err != nil {
+		t.Fatalf("QueueDeviceCommandAndWait() error = %v", err)
+	}
+	if result.Id != 5 || !result.Executed {
+		t.Errorf("Expected the result of command 5, got %+v", result)
+	}
+}
+
+func TestQueueDeviceCommandAndWait_Expired(t *testing.T) {
+	server := commandQueueServer(t, 0, true)
+	defer server.Close()
+
+	_, err := QueueDeviceCommandAndWait(testhelper.New(server.URL), 7, Command{Name: "custom"}, time.Millisecond)
+	if !errors.Is(err, ErrCommandExpired) {
+		t.Errorf("Expected ErrCommandExpired, got %v", err)
+	}
+}
+
+func TestQueueDeviceCommandAndWait_Deadline(t *testing.T) {
+	server := commandQueueServer(t, 1000, false)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := QueueDeviceCommandAndWaitWithContext(ctx, testhelper.New(server.URL), 7, Command{Name: "custom"}, time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to stop the wait, got %v", err)
+	}
+}
+
+func TestQueueDevicesCommandBySelector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gw/devices/7,8/commands-queue" {
+			t.Errorf("Expected path /gw/devices/7,8/commands-queue, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"result": [{"id": 5, "device_id": 7, "name": "custom"}], "errors": [{"id": 8, "reason": "device not found"}]}`))
+	}))
+	defer server.Close()
+
+	result, err := QueueDevicesCommandBySelector(testhelper.New(server.URL), flespiapi.SelectIds(7, 8), Command{Name: "custom", TTL: 3600})
+	if err != nil {
+		t.Fatalf("QueueDevicesCommandBySelector() error = %v", err)
+	}
+	if len(result.Ids) != 1 || !result.HasErrors() {
+		t.Errorf("Expected one queued command and one error, got %+v", result)
+	}
+}
@@ -0,0 +1,308 @@
+package flespi_device
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
+
+// maxMediaResumes is how many times an interrupted download is continued before giving up
+const maxMediaResumes = 3
+
+// ErrRangeIgnored is returned when a download should continue at an offset but
+// the server sent the whole file, and the writer cannot be rewound to take it.
+var ErrRangeIgnored = errors.New("server ignored the Range request and sent the whole file")
+
+// Media is a file a device attached to its messages, e.g. a dashcam clip or photo
+type Media struct {
+	Uuid     string `json:"uuid"`
+	DeviceId int64  `json:"device_id"`
+
+	Name string `json:"name,omitempty"`
+	Size int64  `json:"size,omitempty"`
+
+	// Timestamp is when the device recorded the file (unix time)
+	Timestamp float64 `json:"timestamp,omitempty"`
+}
+
+// Time returns when the device recorded the file
+func (m *Media) Time() time.Time {
+	return unixSeconds(m.Timestamp)
+}
+
+type mediaResponse struct {
+	Media  []Media                 `json:"result"`
+	Errors []flespiapi.ErrorDetail `json:"errors"`
+}
+
+// ListDeviceMedia returns the media files of a device recorded between from and
+// to, inclusive; zero values are open ends.
+func ListDeviceMedia(c flespiapi.APIRequester, deviceId int64, from, to time.Time) ([]Media, error) {
+	return ListDeviceMediaWithContext(context.Background(), c, deviceId, from, to)
+}
+
+func ListDeviceMediaWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, from, to time.Time) ([]Media, error) {
+	if deviceId == 0 {
+		return nil, flespiapi.Invalid("device ID must be provided")
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, flespiapi.Invalid("media range ends before it starts")
+	}
+
+	var conditions []string
+	if !from.IsZero() {
+		conditions = append(conditions, "timestamp>="+strconv.FormatFloat(seconds(from), 'f', -1, 64))
+	}
+	if !to.IsZero() {
+		conditions = append(conditions, "timestamp<="+strconv.FormatFloat(seconds(to), 'f', -1, 64))
+	}
+
+	selector := flespiapi.SelectAll()
+	if len(conditions) > 0 {
+		selector = flespiapi.SelectExpr(strings.Join(conditions, ","))
+	}
+
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	response := mediaResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", fmt.Sprintf("gw/devices/%d/media/%s", deviceId, path), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Media, nil
+}
+
+// GetDeviceMedia returns the metadata of a media file
+func GetDeviceMedia(c flespiapi.APIRequester, deviceId int64, uuid string) (*Media, error) {
+	return GetDeviceMediaWithContext(context.Background(), c, deviceId, uuid)
+}
+
+func GetDeviceMediaWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, uuid string) (*Media, error) {
+	if err := validateMedia(deviceId, uuid); err != nil {
+		return nil, err
+	}
+
+	response := mediaResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", mediaEndpoint(deviceId, uuid), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return flespiapi.First(response.Media, response.Errors)
+}
+
+// UploadDeviceMedia attaches a file to a device, e.g. a photo taken by another
+// system, and returns its metadata. The content is sent as the "file" field of
+// a multipart form and is read into memory first, so a retried request can send it again.
+func UploadDeviceMedia(c flespiapi.APIRequester, deviceId int64, name string, content io.Reader) (*Media, error) {
+	return UploadDeviceMediaWithContext(context.Background(), c, deviceId, name, content)
+}
+
+func UploadDeviceMediaWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, name string, content io.Reader) (*Media, error) {
+	if deviceId == 0 {
+		return nil, flespiapi.Invalid("device ID must be provided")
+	}
+	if name == "" {
+		return nil, flespiapi.Invalid("media name must be provided")
+	}
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+
+	part, err := writer.CreateFormFile("file", name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return nil, fmt.Errorf("reading media %s: %w", name, err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	payload := flespiapi.RawBody{ContentType: writer.FormDataContentType(), Data: form.Bytes()}
+	response := mediaResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "POST", fmt.Sprintf("gw/devices/%d/media", deviceId), payload, &response); err != nil {
+		return nil, err
+	}
+
+	return flespiapi.First(response.Media, response.Errors)
+}
+
+// DeleteDeviceMedia removes a media file from flespi's storage
+func DeleteDeviceMedia(c flespiapi.APIRequester, deviceId int64, uuid string) error {
+	return DeleteDeviceMediaWithContext(context.Background(), c, deviceId, uuid)
+}
+
+func DeleteDeviceMediaWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, uuid string) error {
+	if err := validateMedia(deviceId, uuid); err != nil {
+		return err
+	}
+
+	return c.RequestAPIWithContext(ctx, "DELETE", mediaEndpoint(deviceId, uuid), nil, nil)
+}
+
+// DownloadDeviceMedia streams the content of a media file to w and returns the
+// number of bytes written. The file is never held in memory; a transfer that
+// breaks off is continued with a Range request from the last byte written.
+// The requester must implement flespiapi.ResponseStreamer, as *flespi.Client does.
+func DownloadDeviceMedia(c flespiapi.APIRequester, deviceId int64, uuid string, w io.Writer) (int64, error) {
+	return DownloadDeviceMediaFromWithContext(context.Background(), c, deviceId, uuid, w, 0)
+}
+
+func DownloadDeviceMediaWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, uuid string, w io.Writer) (int64, error) {
+	return DownloadDeviceMediaFromWithContext(ctx, c, deviceId, uuid, w, 0)
+}
+
+// DownloadDeviceMediaFrom is DownloadDeviceMedia starting at offset, e.g. the
+// size of a partial file left on disk by an earlier attempt.
+//
+// A continuation is only accepted as 206 Partial Content starting at the
+// requested byte. If the server sends the whole file instead, a writer that can
+// be truncated and rewound, such as *os.File, is emptied and the download
+// restarts from the first byte; the count returned then covers the whole file.
+// Other writers get ErrRangeIgnored and are left as they were.
+func DownloadDeviceMediaFrom(c flespiapi.APIRequester, deviceId int64, uuid string, w io.Writer, offset int64) (int64, error) {
+	return DownloadDeviceMediaFromWithContext(context.Background(), c, deviceId, uuid, w, offset)
+}
+
+func DownloadDeviceMediaFromWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, uuid string, w io.Writer, offset int64) (int64, error) {
+	if err := validateMedia(deviceId, uuid); err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, flespiapi.Invalid("download offset must not be negative")
+	}
+
+	streamer, ok := c.(flespiapi.ResponseStreamer)
+	if !ok {
+		return 0, fmt.Errorf("downloading media %s: %T cannot stream responses", uuid, c)
+	}
+
+	endpoint := mediaEndpoint(deviceId, uuid) + "/data"
+	target := &mediaWriter{w: w}
+
+	for resumes := 0; ; resumes++ {
+		var headers map[string]string
+		position := offset + target.written
+		if position > 0 {
+			headers = map[string]string{"Range": fmt.Sprintf("bytes=%d-", position)}
+		}
+
+		res, err := streamer.RequestAPIResponse(ctx, "GET", endpoint, headers, nil)
+		if err != nil {
+			return target.written, err
+		}
+
+		if position > 0 {
+			restart, err := checkContinuation(res, position)
+			if err == nil && restart {
+				err = rewind(w)
+			}
+			if err != nil {
+				res.Body.Close()
+				return target.written, fmt.Errorf("downloading media %s: %w", uuid, err)
+			}
+			if restart {
+				offset, target.written = 0, 0
+			}
+		}
+
+		_, err = io.Copy(target, res.Body)
+		res.Body.Close()
+
+		if err == nil {
+			return target.written, nil
+		}
+
+		// only a broken response can be continued, not a failed write or a cancelled call
+		if target.err != nil || ctx.Err() != nil || resumes >= maxMediaResumes {
+			return target.written, fmt.Errorf("downloading media %s: %w", uuid, err)
+		}
+	}
+}
+
+// checkContinuation verifies that the answer to a Range request continues at
+// position; restart reports that the server sent the whole file instead
+func checkContinuation(res *flespiapi.StreamResponse, position int64) (restart bool, err error) {
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		var start int64
+		if _, err := fmt.Sscanf(res.Header.Get("Content-Range"), "bytes %d-", &start); err != nil {
+			return false, fmt.Errorf("unexpected Content-Range %q", res.Header.Get("Content-Range"))
+		}
+		if start != position {
+			return false, fmt.Errorf("requested byte %d, server continued at %d", position, start)
+		}
+		return false, nil
+	case http.StatusOK:
+		return true, nil
+	}
+
+	return false, fmt.Errorf("unexpected status %d for a Range request", res.StatusCode)
+}
+
+// rewind empties w so the file can be written again from the first byte
+func rewind(w io.Writer) error {
+	file, ok := w.(truncater)
+	if !ok {
+		return ErrRangeIgnored
+	}
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := file.Seek(0, io.SeekStart)
+	return err
+}
+
+// truncater is implemented by writers a download can restart on, like *os.File
+type truncater interface {
+	io.Seeker
+	Truncate(size int64) error
+}
+
+// mediaWriter counts the bytes written and remembers write errors, which io.Copy
+// does not tell apart from read errors
+type mediaWriter struct {
+	w       io.Writer
+	written int64
+	err     error
+}
+
+func (m *mediaWriter) Write(p []byte) (int, error) {
+	n, err := m.w.Write(p)
+	m.written += int64(n)
+	if err != nil {
+		m.err = err
+	}
+	return n, err
+}
+
+func mediaEndpoint(deviceId int64, uuid string) string {
+	return fmt.Sprintf("gw/devices/%d/media/%s", deviceId, url.PathEscape(uuid))
+}
+
+func validateMedia(deviceId int64, uuid string) error {
+	if deviceId == 0 {
+		return flespiapi.Invalid("device ID must be provided")
+	}
+	if uuid == "" {
+		return flespiapi.Invalid("media uuid must be provided")
+	}
+	return nil
+}
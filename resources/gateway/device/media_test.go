@@ -0,0 +1,229 @@
+package flespi_device
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+	"github.com/mixser/flespi-client/internal/testhelper"
+)
+
+// mediaServer serves content for media "clip" honouring Range headers; the first
+// breaks responses are cut off halfway
+func mediaServer(t *testing.T, content []byte, breaks int, ranges *[]string) *httptest.Server {
+	return mediaServerWith(t, content, mediaBehaviour{breaks: breaks}, ranges)
+}
+
+// mediaBehaviour makes mediaServerWith misbehave like a broken server or proxy
+type mediaBehaviour struct {
+	breaks      int
+	ignoreRange bool
+	shift       int
+}
+
+func mediaServerWith(t *testing.T, content []byte, behaviour mediaBehaviour, ranges *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gw/devices/7/media/clip/data" {
+			t.Errorf("Expected path /gw/devices/7/media/clip/data, got %s", r.URL.Path)
+		}
+		*ranges = append(*ranges, r.Header.Get("Range"))
+
+		start := 0
+		if header := r.Header.Get("Range"); header != "" && !behaviour.ignoreRange {
+			start, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(header, "bytes="), "-"))
+			start += behaviour.shift
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)-start))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		}
+
+		part := content[start:]
+		if behaviour.breaks > 0 {
+			behaviour.breaks--
+			w.Write(part[:len(part)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		w.Write(part)
+	}))
+}
+
+func TestDownloadDeviceMedia_Resume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+
+	var ranges []string
+	server := mediaServer(t, content, 2, &ranges)
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	written, err := DownloadDeviceMedia(testhelper.New(server.URL), 7, "clip", &buffer)
+	if err != nil {
+		t.Fatalf("DownloadDeviceMedia() error = %v", err)
+	}
+
+	if written != int64(len(content)) || !bytes.Equal(buffer.Bytes(), content) {
+		t.Errorf("Expected the whole file (%d bytes), got %d", len(content), written)
+	}
+	if want := []string{"", "bytes=5000-", "bytes=7500-"}; strings.Join(ranges, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected ranges %q, got %q", want, ranges)
+	}
+}
+
+func TestDownloadDeviceMedia_GivesUp(t *testing.T) {
+	var ranges []string
+	server := mediaServer(t, bytes.Repeat([]byte("x"), 1024), maxMediaResumes+1, &ranges)
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	written, err := DownloadDeviceMedia(testhelper.New(server.URL), 7, "clip", &buffer)
+	if err == nil {
+		t.Fatal("Expected an error once the resumes are used up")
+	}
+	if written != int64(buffer.Len()) || len(ranges) != maxMediaResumes+1 {
+		t.Errorf("Expected %d requests and the bytes received so far, got %d requests and %d bytes", maxMediaResumes+1, len(ranges), written)
+	}
+}
+
+func TestDownloadDeviceMediaFrom(t *testing.T) {
+	content := []byte("partial file content")
+
+	var ranges []string
+	server := mediaServer(t, content, 0, &ranges)
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	if _, err := DownloadDeviceMediaFrom(testhelper.New(server.URL), 7, "clip", &buffer, 8); err != nil {
+		t.Fatalf("DownloadDeviceMediaFrom() error = %v", err)
+	}
+	if buffer.String() != "file content" || ranges[0] != "bytes=8-" {
+		t.Errorf("Expected the rest of the file from byte 8, got %q with range %q", buffer.String(), ranges[0])
+	}
+}
+
+func TestDownloadDeviceMedia_RangeIgnored(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100)
+
+	var ranges []string
+	server := mediaServerWith(t, content, mediaBehaviour{breaks: 1, ignoreRange: true}, &ranges)
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	written, err := DownloadDeviceMedia(testhelper.New(server.URL), 7, "clip", &buffer)
+	if !errors.Is(err, ErrRangeIgnored) {
+		t.Fatalf("Expected ErrRangeIgnored, got %v", err)
+	}
+	if written != 500 || !bytes.Equal(buffer.Bytes(), content[:500]) {
+		t.Errorf("Expected the first 500 bytes left untouched, got %d bytes", buffer.Len())
+	}
+}
+
+func TestDownloadDeviceMediaFrom_RangeIgnoredRestarts(t *testing.T) {
+	content := []byte("partial file content")
+
+	var ranges []string
+	server := mediaServerWith(t, content, mediaBehaviour{ignoreRange: true}, &ranges)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "clip")
+	if err := os.WriteFile(path, content[:8], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	written, err := DownloadDeviceMediaFrom(testhelper.New(server.URL), 7, "clip", file, 8)
+	if err != nil {
+		t.Fatalf("DownloadDeviceMediaFrom() error = %v", err)
+	}
+
+	saved, _ := os.ReadFile(path)
+	if written != int64(len(content)) || !bytes.Equal(saved, content) {
+		t.Errorf("Expected the file rewritten from the first byte, got %q (%d bytes)", saved, written)
+	}
+}
+
+func TestDownloadDeviceMediaFrom_WrongContentRange(t *testing.T) {
+	var ranges []string
+	server := mediaServerWith(t, []byte("partial file content"), mediaBehaviour{shift: 2}, &ranges)
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	if _, err := DownloadDeviceMediaFrom(testhelper.New(server.URL), 7, "clip", &buffer, 8); err == nil {
+		t.Error("Expected an error when the server continues at another byte")
+	}
+	if buffer.Len() != 0 {
+		t.Errorf("Expected nothing written, got %q", buffer.String())
+	}
+}
+
+func TestUploadDeviceMedia(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/gw/devices/7/media" {
+			t.Errorf("Expected POST /gw/devices/7/media, got %s %s", r.Method, r.URL.Path)
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("Expected a multipart file, got %v", err)
+			http.Error(w, `{"errors": [{"reason": "no file"}]}`, http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		if header.Filename != "photo.jpg" || string(content) != "jpeg bytes" {
+			t.Errorf("Expected photo.jpg with its content, got %s %q", header.Filename, content)
+		}
+
+		w.Write([]byte(`{"result": [{"uuid": "photo", "device_id": 7, "name": "photo.jpg", "size": 10}]}`))
+	}))
+	defer server.Close()
+
+	media, err := UploadDeviceMedia(testhelper.New(server.URL), 7, "photo.jpg", strings.NewReader("jpeg bytes"))
+	if err != nil {
+		t.Fatalf("UploadDeviceMedia() error = %v", err)
+	}
+	if media.Uuid != "photo" || media.Size != 10 {
+		t.Errorf("Expected the uploaded file, got %+v", media)
+	}
+
+	if _, err := UploadDeviceMedia(testhelper.New(server.URL), 7, "", strings.NewReader("")); !errors.Is(err, flespiapi.ErrValidation) {
+		t.Errorf("Expected a validation error without a name, got %v", err)
+	}
+}
+
+func TestListDeviceMedia(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if want := "/gw/devices/7/media/{timestamp>=1700000000,timestamp<=1700003600.5}"; r.URL.Path != want {
+			t.Errorf("Expected path %s, got %s", want, r.URL.Path)
+		}
+		w.Write([]byte(`{"result": [{"uuid": "clip", "device_id": 7, "name": "clip.mp4", "size": 10000, "timestamp": 1700000100}]}`))
+	}))
+	defer server.Close()
+
+	from := time.Unix(1700000000, 0)
+	media, err := ListDeviceMedia(testhelper.New(server.URL), 7, from, from.Add(time.Hour+500*time.Millisecond))
+	if err != nil {
+		t.Fatalf("ListDeviceMedia() error = %v", err)
+	}
+	if len(media) != 1 || media[0].Size != 10000 || !media[0].Time().Equal(time.Unix(1700000100, 0)) {
+		t.Errorf("Expected the clip, got %+v", media)
+	}
+
+	if _, err := ListDeviceMedia(testhelper.New(server.URL), 7, from, from.Add(-time.Hour)); !errors.Is(err, flespiapi.ErrValidation) {
+		t.Errorf("Expected a validation error for an inverted range, got %v", err)
+	}
+}
@@ -0,0 +1,402 @@
+package flespi_device
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
+
+// defaultMessagesPageSize is the number of messages requested at once when a
+// range is read in several requests
+const defaultMessagesPageSize = 10000
+
+// Message is a device message: a flat map of parameter names such as
+// "position.latitude" to their values. Numbers are decoded as float64.
+type Message map[string]interface{}
+
+// Timestamp returns the time the message was generated by the device
+func (m Message) Timestamp() time.Time {
+	seconds, _ := m.Float("timestamp")
+	return unixSeconds(seconds)
+}
+
+// ServerTimestamp returns the time flespi received the message
+func (m Message) ServerTimestamp() time.Time {
+	seconds, _ := m.Float("server.timestamp")
+	return unixSeconds(seconds)
+}
+
+// DeviceId returns the id of the device the message belongs to
+func (m Message) DeviceId() int64 {
+	id, _ := m.Int("device.id")
+	return id
+}
+
+// Has reports whether the message contains the parameter
+func (m Message) Has(name string) bool {
+	_, ok := m[name]
+	return ok
+}
+
+// Float returns a numeric parameter
+func (m Message) Float(name string) (float64, bool) {
+	return toFloat(m[name])
+}
+
+// Int returns a numeric parameter truncated to an integer
+func (m Message) Int(name string) (int64, bool) {
+	value, ok := toFloat(m[name])
+	return int64(value), ok
+}
+
+// String returns a string parameter
+func (m Message) String(name string) (string, bool) {
+	value, ok := m[name].(string)
+	return value, ok
+}
+
+// Bool returns a boolean parameter
+func (m Message) Bool(name string) (bool, bool) {
+	value, ok := m[name].(bool)
+	return value, ok
+}
+
+// MessagesQuery holds the values collected from MessagesOption functions
+type MessagesQuery struct {
+	// From and To limit the range of message timestamps; zero values are open ends
+	From time.Time
+	To   time.Time
+
+	// Count limits the total number of messages returned; 0 means no limit
+	Count int
+
+	// Reverse returns the newest messages first
+	Reverse bool
+
+	// Filter is a flespi expression messages must match, e.g. "position.speed>90"
+	Filter string
+
+	// Fields limits the parameters returned per message; timestamp is always included
+	Fields []string
+
+	// PageSize is the number of messages read per request (default: 10000); it is
+	// doubled when more messages share one timestamp than a page holds
+	PageSize int
+}
+
+// MessagesOption tunes a device messages request
+type MessagesOption func(*MessagesQuery)
+
+// MessagesFrom returns messages generated at or after from
+func MessagesFrom(from time.Time) MessagesOption {
+	return func(q *MessagesQuery) {
+		q.From = from
+	}
+}
+
+// MessagesTo returns messages generated at or before to
+func MessagesTo(to time.Time) MessagesOption {
+	return func(q *MessagesQuery) {
+		q.To = to
+	}
+}
+
+// MessagesInRange returns messages generated between from and to, inclusive
+func MessagesInRange(from, to time.Time) MessagesOption {
+	return func(q *MessagesQuery) {
+		q.From = from
+		q.To = to
+	}
+}
+
+// MessagesLimit returns at most count messages
+func MessagesLimit(count int) MessagesOption {
+	return func(q *MessagesQuery) {
+		q.Count = count
+	}
+}
+
+// MessagesReverse returns the newest messages first; with MessagesLimit it reads the latest ones
+func MessagesReverse() MessagesOption {
+	return func(q *MessagesQuery) {
+		q.Reverse = true
+	}
+}
+
+// MessagesFilter returns only the messages matching a flespi expression, e.g. "position.speed>90"
+func MessagesFilter(expr string) MessagesOption {
+	return func(q *MessagesQuery) {
+		q.Filter = expr
+	}
+}
+
+// MessagesFields returns only the given parameters of each message
+func MessagesFields(fields ...string) MessagesOption {
+	return func(q *MessagesQuery) {
+		q.Fields = append(q.Fields, fields...)
+	}
+}
+
+// MessagesPageSize sets how many messages are read per request over large ranges
+func MessagesPageSize(size int) MessagesOption {
+	return func(q *MessagesQuery) {
+		q.PageSize = size
+	}
+}
+
+// GetDeviceMessages reads the messages of a device. Large ranges are read in
+// pages of MessagesPageSize messages, continuing from the last timestamp, so
+// the result is not truncated by flespi's per-request limits. If more messages
+// share one timestamp than a page holds, the page is doubled until they fit. Use
+// IterateDeviceMessages to process the messages without holding them all.
+func GetDeviceMessages(c flespiapi.APIRequester, deviceId int64, options ...MessagesOption) ([]Message, error) {
+	return GetDeviceMessagesWithContext(context.Background(), c, deviceId, options...)
+}
+
+func GetDeviceMessagesWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, options ...MessagesOption) ([]Message, error) {
+	it := IterateDeviceMessagesWithContext(ctx, c, deviceId, options...)
+	defer it.Close()
+
+	var messages []Message
+	for it.Next() {
+		messages = append(messages, it.Value())
+	}
+
+	if err := it.Err(); err != nil {
+		return messages, err
+	}
+
+	return messages, nil
+}
+
+// IterateDeviceMessages streams the messages of a device, decoding one message
+// per Next call and requesting the next page when one is exhausted. Close the
+// iterator when done.
+func IterateDeviceMessages(c flespiapi.APIRequester, deviceId int64, options ...MessagesOption) *MessageIterator {
+	return IterateDeviceMessagesWithContext(context.Background(), c, deviceId, options...)
+}
+
+func IterateDeviceMessagesWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, options ...MessagesOption) *MessageIterator {
+	query := MessagesQuery{}
+	for _, opt := range options {
+		opt(&query)
+	}
+
+	it := &MessageIterator{ctx: ctx, c: c, deviceId: deviceId, query: query}
+
+	switch {
+	case deviceId == 0:
+		it.fail(flespiapi.Invalid("device ID must be provided"))
+	case !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From):
+		it.fail(flespiapi.Invalid("messages range ends before it starts"))
+	}
+
+	if it.query.PageSize <= 0 {
+		it.query.PageSize = defaultMessagesPageSize
+	}
+
+	return it
+}
+
+// MessageIterator decodes device messages one at a time across as many
+// requests as the range needs, see IterateDeviceMessages
+type MessageIterator struct {
+	ctx      context.Context
+	c        flespiapi.APIRequester
+	deviceId int64
+	query    MessagesQuery
+
+	page      *flespiapi.Iterator[Message]
+	requested int
+	pageCount int
+
+	// boundary tracks the messages sharing the last timestamp, which the next
+	// page starts with again and must skip
+	boundary      float64
+	boundaryCount int
+	skip          int
+
+	received int
+	current  Message
+	err      error
+	done     bool
+}
+
+// Next advances to the next message and reports whether there is one
+func (it *MessageIterator) Next() bool {
+	for !it.done {
+		if it.page == nil {
+			it.page = flespiapi.NewIterator[Message](it.ctx, it.c, it.endpoint(), nil)
+			it.pageCount = 0
+		}
+
+		if !it.page.Next() {
+			if err := it.page.Err(); err != nil {
+				it.fail(err)
+				return false
+			}
+			it.page.Close()
+			it.page = nil
+
+			if it.pageCount < it.requested {
+				it.done = true
+				return false
+			}
+
+			it.advance()
+			continue
+		}
+
+		message := it.page.Value()
+		it.pageCount++
+
+		timestamp, _ := message.Float("timestamp")
+		if it.skip > 0 && timestamp == it.boundary {
+			it.skip--
+			continue
+		}
+		it.skip = 0
+
+		if timestamp == it.boundary {
+			it.boundaryCount++
+		} else {
+			it.boundary = timestamp
+			it.boundaryCount = 1
+		}
+
+		it.received++
+		if it.query.Count > 0 && it.received >= it.query.Count {
+			it.Close()
+		}
+
+		it.current = message
+		return true
+	}
+
+	return false
+}
+
+// Value returns the message Next advanced to
+func (it *MessageIterator) Value() Message {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *MessageIterator) Err() error {
+	return it.err
+}
+
+// Close releases the current response; it is safe to call more than once
+func (it *MessageIterator) Close() error {
+	it.done = true
+	if it.page == nil {
+		return nil
+	}
+
+	page := it.page
+	it.page = nil
+	return page.Close()
+}
+
+func (it *MessageIterator) fail(err error) {
+	it.err = err
+	it.Close()
+}
+
+// advance moves the range past the messages received so far
+func (it *MessageIterator) advance() {
+	boundary := unixSeconds(it.boundary)
+
+	// when a whole page shares one timestamp there may be more of them than a
+	// page holds; ask again with a larger page instead of stepping over them
+	if it.boundaryCount >= it.pageCount {
+		it.query.PageSize = it.pageCount * 2
+	}
+	it.skip = it.boundaryCount
+
+	if it.query.Reverse {
+		it.query.To = boundary
+	} else {
+		it.query.From = boundary
+	}
+}
+
+// endpoint builds the request for the next page; flespi takes the query as JSON in the data parameter
+func (it *MessageIterator) endpoint() string {
+	count := it.query.PageSize
+	if it.query.Count > 0 {
+		if remaining := it.query.Count - it.received + it.skip; remaining < count {
+			count = remaining
+		}
+	}
+	it.requested = count
+
+	data := map[string]interface{}{"count": count}
+
+	if !it.query.From.IsZero() {
+		data["from"] = seconds(it.query.From)
+	}
+	if !it.query.To.IsZero() {
+		data["to"] = seconds(it.query.To)
+	}
+	if it.query.Reverse {
+		data["reverse"] = true
+	}
+	if it.query.Filter != "" {
+		data["filter"] = it.query.Filter
+	}
+	if len(it.query.Fields) > 0 {
+		fields := it.query.Fields
+		if !containsString(fields, "timestamp") {
+			fields = append([]string{"timestamp"}, fields...)
+		}
+		data["fields"] = strings.Join(fields, ",")
+	}
+
+	encoded, _ := json.Marshal(data)
+
+	return fmt.Sprintf("gw/devices/%d/messages?data=%s", it.deviceId, url.QueryEscape(string(encoded)))
+}
+
+// seconds converts t to the fractional unix time flespi uses
+func seconds(t time.Time) float64 {
+	return float64(t.UnixMicro()) / 1e6
+}
+
+func unixSeconds(value float64) time.Time {
+	if value == 0 {
+		return time.Time{}
+	}
+	whole, fraction := math.Modf(value)
+	return time.Unix(int64(whole), int64(math.Round(fraction*1e6))*int64(time.Microsecond))
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
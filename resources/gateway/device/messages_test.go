@@ -0,0 +1,192 @@
+package flespi_device
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mixser/flespi-client/internal/testhelper"
+)
+
+// messagesServer answers messages requests from a fixed set of timestamps,
+// honouring from, to, count and reverse like flespi does
+func messagesServer(t *testing.T, timestamps []float64, requests *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gw/devices/7/messages" {
+			t.Errorf("Expected path /gw/devices/7/messages, got %s", r.URL.Path)
+		}
+
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("data")), &data); err != nil {
+			t.Errorf("Expected JSON in the data parameter: %v", err)
+			http.Error(w, `{"errors": [{"reason": "invalid data"}]}`, http.StatusBadRequest)
+			return
+		}
+		*requests = append(*requests, data)
+
+		from, hasFrom := data["from"].(float64)
+		to, hasTo := data["to"].(float64)
+		count := int(data["count"].(float64))
+		reverse, _ := data["reverse"].(bool)
+
+		var selected []float64
+		for _, ts := range timestamps {
+			if (!hasFrom || ts >= from) && (!hasTo || ts <= to) {
+				selected = append(selected, ts)
+			}
+		}
+		if reverse {
+			sort.Sort(sort.Reverse(sort.Float64Slice(selected)))
+		}
+		if len(selected) > count {
+			selected = selected[:count]
+		}
+
+		result := make([]Message, 0, len(selected))
+		for i, ts := range selected {
+			result = append(result, Message{"timestamp": ts, "device.id": 7, "seq": i})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+	}))
+}
+
+func TestGetDeviceMessages_Continuation(t *testing.T) {
+	// a page boundary falls between messages sharing timestamp 3
+	timestamps := []float64{1, 2, 3, 3, 3, 4, 5, 6, 7}
+
+	var requests []map[string]interface{}
+	server := messagesServer(t, timestamps, &requests)
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	messages, err := GetDeviceMessages(client, 7, MessagesPageSize(4), MessagesFrom(time.Unix(1, 0)))
+	if err != nil {
+		t.Fatalf("GetDeviceMessages() error = %v", err)
+	}
+
+	if len(messages) != len(timestamps) {
+		t.Fatalf("Expected %d messages, got %d", len(timestamps), len(messages))
+	}
+	for i, message := range messages {
+		if ts, _ := message.Float("timestamp"); ts != timestamps[i] {
+			t.Errorf("Expected message %d at %v, got %v", i, timestamps[i], ts)
+		}
+	}
+	// the last full page needs one more request to find the end
+	if len(requests) != 4 {
+		t.Errorf("Expected 4 requests, got %d", len(requests))
+	}
+	if messages[0].DeviceId() != 7 || !messages[0].Timestamp().Equal(time.Unix(1, 0)) {
+		t.Errorf("Expected device 7 at 1s, got %d at %v", messages[0].DeviceId(), messages[0].Timestamp())
+	}
+}
+
+func TestGetDeviceMessages_TimestampLargerThanPage(t *testing.T) {
+	// six messages share timestamp 2, twice the page size
+	timestamps := []float64{1, 2, 2, 2, 2, 2, 2, 3}
+
+	for _, reverse := range []bool{false, true} {
+		var requests []map[string]interface{}
+		server := messagesServer(t, timestamps, &requests)
+
+		options := []MessagesOption{MessagesPageSize(3)}
+		if reverse {
+			options = append(options, MessagesReverse())
+		}
+
+		messages, err := GetDeviceMessages(testhelper.New(server.URL), 7, options...)
+		server.Close()
+		if err != nil {
+			t.Fatalf("GetDeviceMessages() error = %v", err)
+		}
+
+		if len(messages) != len(timestamps) {
+			t.Fatalf("Expected all %d messages (reverse %v), got %d", len(timestamps), reverse, len(messages))
+		}
+		for i, message := range messages {
+			want := timestamps[i]
+			if reverse {
+				want = timestamps[len(timestamps)-1-i]
+			}
+			if ts, _ := message.Float("timestamp"); ts != want {
+				t.Errorf("Expected message %d at %v (reverse %v), got %v", i, want, reverse, ts)
+			}
+		}
+	}
+}
+
+func TestGetDeviceMessages_LimitAndReverse(t *testing.T) {
+	timestamps := []float64{1, 2, 3, 4, 5, 6, 7}
+
+	var requests []map[string]interface{}
+	server := messagesServer(t, timestamps, &requests)
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	messages, err := GetDeviceMessages(client, 7, MessagesReverse(), MessagesLimit(5), MessagesPageSize(3))
+	if err != nil {
+		t.Fatalf("GetDeviceMessages() error = %v", err)
+	}
+
+	want := []float64{7, 6, 5, 4, 3}
+	if len(messages) != len(want) {
+		t.Fatalf("Expected %d messages, got %d", len(want), len(messages))
+	}
+	for i, message := range messages {
+		if ts, _ := message.Float("timestamp"); ts != want[i] {
+			t.Errorf("Expected message %d at %v, got %v", i, want[i], ts)
+		}
+	}
+
+	// 2 remaining messages plus the one at 5 that is received again
+	if last := requests[len(requests)-1]; last["count"].(float64) != 3 || last["to"].(float64) != 5 {
+		t.Errorf("Expected the last page to ask for 3 messages up to 5, got %v", last)
+	}
+}
+
+func TestGetDeviceMessages_Query(t *testing.T) {
+	var requests []map[string]interface{}
+	server := messagesServer(t, nil, &requests)
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	from := time.Unix(1700000000, 500000000)
+	_, err := GetDeviceMessages(client, 7,
+		MessagesInRange(from, from.Add(time.Hour)),
+		MessagesFilter("position.speed>90"),
+		MessagesFields("position.speed"),
+	)
+	if err != nil {
+		t.Fatalf("GetDeviceMessages() error = %v", err)
+	}
+
+	data := requests[0]
+	if data["from"].(float64) != 1700000000.5 || data["to"].(float64) != 1700003600.5 {
+		t.Errorf("Expected fractional unix times, got from %v to %v", data["from"], data["to"])
+	}
+	if data["filter"] != "position.speed>90" {
+		t.Errorf("Expected the filter to be sent, got %v", data["filter"])
+	}
+	if data["fields"] != "timestamp,position.speed" {
+		t.Errorf("Expected timestamp to be added to the fields, got %v", data["fields"])
+	}
+}
+
+func TestGetDeviceMessages_InvalidArguments(t *testing.T) {
+	client := testhelper.New("http://127.0.0.1:0")
+
+	if _, err := GetDeviceMessages(client, 0); err == nil {
+		t.Error("Expected an error without a device ID")
+	}
+
+	now := time.Now()
+	if _, err := GetDeviceMessages(client, 7, MessagesInRange(now, now.Add(-time.Hour))); err == nil {
+		t.Error("Expected an error for an inverted range")
+	}
+}
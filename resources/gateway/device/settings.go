@@ -0,0 +1,226 @@
+package flespi_device
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
+
+// Setting is a configuration value stored on a device, e.g. its reporting interval
+type Setting struct {
+	DeviceId int64  `json:"device_id"`
+	Name     string `json:"name"`
+
+	// Current is the value last confirmed by the device
+	Current interface{} `json:"current,omitempty"`
+
+	// Pending is a value that was set but not yet delivered to the device
+	Pending interface{} `json:"pending,omitempty"`
+
+	// Address selects the connection the setting is sent over, for devices with several
+	Address string `json:"address,omitempty"`
+
+	// Updated is when the current value was last read from or confirmed by the device (unix time)
+	Updated float64 `json:"updated,omitempty"`
+}
+
+// IsPending reports whether a new value waits to be delivered to the device
+func (s *Setting) IsPending() bool {
+	return s.Pending != nil
+}
+
+// UpdatedAt returns when the current value was last confirmed
+func (s *Setting) UpdatedAt() time.Time {
+	return unixSeconds(s.Updated)
+}
+
+// SettingSchema describes a setting a device type supports
+type SettingSchema struct {
+	Name  string `json:"name"`
+	Title string `json:"title,omitempty"`
+
+	// Schema is the JSON schema values of the setting must follow
+	Schema json.RawMessage `json:"schema,omitempty"`
+}
+
+type deviceType struct {
+	Id       int64           `json:"id"`
+	Settings []SettingSchema `json:"settings"`
+}
+
+type settingsResponse struct {
+	Settings []Setting               `json:"result"`
+	Errors   []flespiapi.ErrorDetail `json:"errors"`
+}
+
+// settingUpdate is the body flespi expects when a setting is changed
+type settingUpdate struct {
+	Value interface{} `json:"value"`
+}
+
+// SettingsResult holds the settings changed by a bulk request and the per-device errors reported by flespi.
+// Ids are device ids.
+type SettingsResult = flespiapi.MultiResult[Setting]
+
+// ListDeviceSettings returns every setting of a device with its current and pending values
+func ListDeviceSettings(c flespiapi.APIRequester, deviceId int64) ([]Setting, error) {
+	return ListDeviceSettingsWithContext(context.Background(), c, deviceId)
+}
+
+func ListDeviceSettingsWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64) ([]Setting, error) {
+	if deviceId == 0 {
+		return nil, flespiapi.Invalid("device ID must be provided")
+	}
+
+	response := settingsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", fmt.Sprintf("gw/devices/%d/settings/all", deviceId), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Settings, nil
+}
+
+// GetDeviceSetting returns a single setting of a device
+func GetDeviceSetting(c flespiapi.APIRequester, deviceId int64, name string) (*Setting, error) {
+	return GetDeviceSettingWithContext(context.Background(), c, deviceId, name)
+}
+
+func GetDeviceSettingWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, name string) (*Setting, error) {
+	if err := validateSetting(deviceId, name); err != nil {
+		return nil, err
+	}
+
+	response := settingsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", settingEndpoint(fmt.Sprintf("%d", deviceId), name), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return flespiapi.First(response.Settings, response.Errors)
+}
+
+// SetDeviceSetting changes a setting of a device. The value stays pending
+// until the device connects and confirms it.
+func SetDeviceSetting(c flespiapi.APIRequester, deviceId int64, name string, value interface{}) (*Setting, error) {
+	return SetDeviceSettingWithContext(context.Background(), c, deviceId, name, value)
+}
+
+func SetDeviceSettingWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, name string, value interface{}) (*Setting, error) {
+	if err := validateSetting(deviceId, name); err != nil {
+		return nil, err
+	}
+
+	response := settingsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "PUT", settingEndpoint(fmt.Sprintf("%d", deviceId), name), settingUpdate{Value: value}, &response); err != nil {
+		return nil, err
+	}
+
+	return flespiapi.First(response.Settings, response.Errors)
+}
+
+// ResetDeviceSetting drops a pending value and restores the setting to the device's default
+func ResetDeviceSetting(c flespiapi.APIRequester, deviceId int64, name string) error {
+	return ResetDeviceSettingWithContext(context.Background(), c, deviceId, name)
+}
+
+func ResetDeviceSettingWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, name string) error {
+	if err := validateSetting(deviceId, name); err != nil {
+		return err
+	}
+
+	return c.RequestAPIWithContext(ctx, "DELETE", settingEndpoint(fmt.Sprintf("%d", deviceId), name), nil, nil)
+}
+
+// SetDevicesSettingBySelector changes a setting on every device matched by selector,
+// e.g. a new reporting interval for all devices with metadata.fleet="north".
+func SetDevicesSettingBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, name string, value interface{}) (*SettingsResult, error) {
+	return SetDevicesSettingBySelectorWithContext(context.Background(), c, selector, name, value)
+}
+
+func SetDevicesSettingBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, name string, value interface{}) (*SettingsResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, flespiapi.Invalid("setting name must be provided")
+	}
+
+	response := settingsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "PUT", settingEndpoint(path, name), settingUpdate{Value: value}, &response); err != nil {
+		return nil, err
+	}
+
+	result := &SettingsResult{}
+	result.Append(response.Settings, response.Errors, func(setting Setting) int64 { return setting.DeviceId })
+
+	return result, nil
+}
+
+// ResetDevicesSettingBySelector resets a setting on every device matched by selector
+func ResetDevicesSettingBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, name string) error {
+	return ResetDevicesSettingBySelectorWithContext(context.Background(), c, selector, name)
+}
+
+func ResetDevicesSettingBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, name string) error {
+	path, err := selector.Path()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return flespiapi.Invalid("setting name must be provided")
+	}
+
+	return c.RequestAPIWithContext(ctx, "DELETE", settingEndpoint(path, name), nil, nil)
+}
+
+// GetDeviceTypeSettingsSchema returns the settings a device type supports and the schema of their values
+func GetDeviceTypeSettingsSchema(c flespiapi.APIRequester, deviceTypeId int64) ([]SettingSchema, error) {
+	return GetDeviceTypeSettingsSchemaWithContext(context.Background(), c, deviceTypeId)
+}
+
+func GetDeviceTypeSettingsSchemaWithContext(ctx context.Context, c flespiapi.APIRequester, deviceTypeId int64) ([]SettingSchema, error) {
+	if deviceTypeId == 0 {
+		return nil, flespiapi.Invalid("device type ID must be provided")
+	}
+
+	response := struct {
+		DeviceTypes []deviceType            `json:"result"`
+		Errors      []flespiapi.ErrorDetail `json:"errors"`
+	}{}
+
+	// device type ids are unique across protocols, so any protocol matches
+	endpoint := fmt.Sprintf("gw/channel-protocols/all/device-types/%d?fields=id,settings", deviceTypeId)
+
+	if err := c.RequestAPIWithContext(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	result, err := flespiapi.First(response.DeviceTypes, response.Errors)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Settings, nil
+}
+
+func settingEndpoint(devices string, name string) string {
+	return fmt.Sprintf("gw/devices/%s/settings/%s", devices, url.PathEscape(name))
+}
+
+func validateSetting(deviceId int64, name string) error {
+	if deviceId == 0 {
+		return flespiapi.Invalid("device ID must be provided")
+	}
+	if name == "" {
+		return flespiapi.Invalid("setting name must be provided")
+	}
+	return nil
+}
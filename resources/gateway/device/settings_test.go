@@ -0,0 +1,111 @@
+package flespi_device
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+	"github.com/mixser/flespi-client/internal/testhelper"
+)
+
+func TestSetDeviceSetting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/gw/devices/7/settings/report_interval" {
+			t.Errorf("Expected PUT /gw/devices/7/settings/report_interval, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["value"] != float64(60) {
+			t.Errorf("Expected the value in the body, got %v", body)
+		}
+
+		w.Write([]byte(`{"result": [{"device_id": 7, "name": "report_interval", "current": 30, "pending": 60, "updated": 1700000000}]}`))
+	}))
+	defer server.Close()
+
+	setting, err := SetDeviceSetting(testhelper.New(server.URL), 7, "report_interval", 60)
+	if err != nil {
+		t.Fatalf("SetDeviceSetting() error = %v", err)
+	}
+	if !setting.IsPending() || setting.Current != float64(30) || setting.UpdatedAt().Unix() != 1700000000 {
+		t.Errorf("Expected a pending change from 30, got %+v", setting)
+	}
+
+	if _, err := SetDeviceSetting(testhelper.New(server.URL), 7, "", 60); !errors.Is(err, flespiapi.ErrValidation) {
+		t.Errorf("Expected a validation error without a name, got %v", err)
+	}
+}
+
+func TestListAndResetDeviceSettings(t *testing.T) {
+	var reset bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/gw/devices/7/settings/all":
+			w.Write([]byte(`{"result": [{"device_id": 7, "name": "report_interval", "current": 30}, {"device_id": 7, "name": "apn"}]}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/gw/devices/7/settings/apn":
+			reset = true
+			w.Write([]byte(`{"result": []}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	settings, err := ListDeviceSettings(client, 7)
+	if err != nil {
+		t.Fatalf("ListDeviceSettings() error = %v", err)
+	}
+	if len(settings) != 2 || settings[0].IsPending() {
+		t.Errorf("Expected 2 settings without pending values, got %+v", settings)
+	}
+
+	if err := ResetDeviceSetting(client, 7, "apn"); err != nil || !reset {
+		t.Errorf("Expected the setting to be reset, got %v", err)
+	}
+}
+
+func TestSetDevicesSettingBySelector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gw/devices/7,8/settings/report_interval" {
+			t.Errorf("Expected path /gw/devices/7,8/settings/report_interval, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"result": [{"device_id": 7, "name": "report_interval", "pending": 60}], "errors": [{"id": 8, "reason": "setting not supported"}]}`))
+	}))
+	defer server.Close()
+
+	result, err := SetDevicesSettingBySelector(testhelper.New(server.URL), flespiapi.SelectIds(7, 8), "report_interval", 60)
+	if err != nil {
+		t.Fatalf("SetDevicesSettingBySelector() error = %v", err)
+	}
+	if len(result.Ids) != 1 || result.Ids[0] != 7 || !result.HasErrors() {
+		t.Errorf("Expected device 7 changed and one error, got %+v", result)
+	}
+}
+
+func TestGetDeviceTypeSettingsSchema(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gw/channel-protocols/all/device-types/12" {
+			t.Errorf("Expected path /gw/channel-protocols/all/device-types/12, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("fields") != "id,settings" {
+			t.Errorf("Expected fields=id,settings, got %q", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"result": [{"id": 12, "settings": [{"name": "report_interval", "title": "Reporting interval", "schema": {"type": "integer"}}]}]}`))
+	}))
+	defer server.Close()
+
+	schema, err := GetDeviceTypeSettingsSchema(testhelper.New(server.URL), 12)
+	if err != nil {
+		t.Fatalf("GetDeviceTypeSettingsSchema() error = %v", err)
+	}
+	if len(schema) != 1 || schema[0].Name != "report_interval" || string(schema[0].Schema) != `{"type": "integer"}` {
+		t.Errorf("Expected the report_interval schema, got %+v", schema)
+	}
+}
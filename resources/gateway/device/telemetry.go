@@ -0,0 +1,184 @@
+package flespi_device
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
+
+// Common telemetry and message parameter names
+const (
+	ParamLatitude       = "position.latitude"
+	ParamLongitude      = "position.longitude"
+	ParamAltitude       = "position.altitude"
+	ParamSpeed          = "position.speed"
+	ParamDirection      = "position.direction"
+	ParamSatellites     = "position.satellites"
+	ParamPositionValid  = "position.valid"
+	ParamBatteryVoltage = "battery.voltage"
+	ParamBatteryLevel   = "battery.level"
+	ParamExternalPower  = "external.powersource.voltage"
+	ParamIgnition       = "engine.ignition.status"
+	ParamIdent          = "ident"
+)
+
+// TelemetryValue is the latest value of a parameter and when it was reported
+type TelemetryValue struct {
+	Value interface{} `json:"value"`
+
+	// Ts is the unix time of the message that reported the value
+	Ts float64 `json:"ts"`
+}
+
+// Time returns when the value was reported
+func (v TelemetryValue) Time() time.Time {
+	return unixSeconds(v.Ts)
+}
+
+// Float returns the value if it is a number
+func (v TelemetryValue) Float() (float64, bool) {
+	return toFloat(v.Value)
+}
+
+// Int returns the value truncated to an integer if it is a number
+func (v TelemetryValue) Int() (int64, bool) {
+	value, ok := toFloat(v.Value)
+	return int64(value), ok
+}
+
+// Text returns the value if it is a string
+func (v TelemetryValue) Text() (string, bool) {
+	value, ok := v.Value.(string)
+	return value, ok
+}
+
+// Bool returns the value if it is a boolean
+func (v TelemetryValue) Bool() (bool, bool) {
+	value, ok := v.Value.(bool)
+	return value, ok
+}
+
+// Telemetry is the latest known value of each parameter of a device
+type Telemetry struct {
+	DeviceId   int64                     `json:"id"`
+	Parameters map[string]TelemetryValue `json:"telemetry"`
+}
+
+// Get returns a parameter
+func (t *Telemetry) Get(name string) (TelemetryValue, bool) {
+	value, ok := t.Parameters[name]
+	return value, ok
+}
+
+// Float returns a numeric parameter
+func (t *Telemetry) Float(name string) (float64, bool) {
+	value, ok := t.Parameters[name]
+	if !ok {
+		return 0, false
+	}
+	return value.Float()
+}
+
+// Position returns the last reported coordinates
+func (t *Telemetry) Position() (latitude, longitude float64, ok bool) {
+	latitude, okLat := t.Float(ParamLatitude)
+	longitude, okLon := t.Float(ParamLongitude)
+	return latitude, longitude, okLat && okLon
+}
+
+// Speed returns the last reported speed in km/h
+func (t *Telemetry) Speed() (float64, bool) {
+	return t.Float(ParamSpeed)
+}
+
+// BatteryVoltage returns the last reported internal battery voltage
+func (t *Telemetry) BatteryVoltage() (float64, bool) {
+	return t.Float(ParamBatteryVoltage)
+}
+
+// ExternalPowerVoltage returns the last reported external power source voltage
+func (t *Telemetry) ExternalPowerVoltage() (float64, bool) {
+	return t.Float(ParamExternalPower)
+}
+
+// Ignition returns the last reported ignition state
+func (t *Telemetry) Ignition() (bool, bool) {
+	value, ok := t.Parameters[ParamIgnition]
+	if !ok {
+		return false, false
+	}
+	return value.Bool()
+}
+
+// LastSeen returns the time of the most recent parameter update
+func (t *Telemetry) LastSeen() time.Time {
+	var latest float64
+	for _, value := range t.Parameters {
+		if value.Ts > latest {
+			latest = value.Ts
+		}
+	}
+	return unixSeconds(latest)
+}
+
+type telemetryResponse struct {
+	Telemetry []Telemetry             `json:"result"`
+	Errors    []flespiapi.ErrorDetail `json:"errors"`
+}
+
+// GetDeviceTelemetry returns the latest values of a device's parameters; with
+// no params the whole snapshot is returned.
+func GetDeviceTelemetry(c flespiapi.APIRequester, deviceId int64, params ...string) (*Telemetry, error) {
+	return GetDeviceTelemetryWithContext(context.Background(), c, deviceId, params...)
+}
+
+func GetDeviceTelemetryWithContext(ctx context.Context, c flespiapi.APIRequester, deviceId int64, params ...string) (*Telemetry, error) {
+	if deviceId == 0 {
+		return nil, flespiapi.Invalid("device ID must be provided")
+	}
+
+	response := telemetryResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", telemetryEndpoint(fmt.Sprintf("%d", deviceId), params), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return flespiapi.First(response.Telemetry, response.Errors)
+}
+
+// ListDevicesTelemetryBySelector returns the telemetry of every device matched by selector
+func ListDevicesTelemetryBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, params ...string) ([]Telemetry, error) {
+	return ListDevicesTelemetryBySelectorWithContext(context.Background(), c, selector, params...)
+}
+
+func ListDevicesTelemetryBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, params ...string) ([]Telemetry, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	response := telemetryResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", telemetryEndpoint(path, params), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Telemetry, nil
+}
+
+func telemetryEndpoint(devices string, params []string) string {
+	if len(params) == 0 {
+		return fmt.Sprintf("gw/devices/%s/telemetry/all", devices)
+	}
+
+	escaped := make([]string, 0, len(params))
+	for _, param := range params {
+		escaped = append(escaped, url.PathEscape(param))
+	}
+
+	return fmt.Sprintf("gw/devices/%s/telemetry/%s", devices, strings.Join(escaped, ","))
+}
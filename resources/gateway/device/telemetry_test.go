@@ -0,0 +1,74 @@
+package flespi_device
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+	"github.com/mixser/flespi-client/internal/testhelper"
+)
+
+const telemetryBody = `{"result": [{"id": 7, "telemetry": {
+	"position.latitude": {"value": 52.5, "ts": 1700000000},
+	"position.longitude": {"value": 13.4, "ts": 1700000000},
+	"position.speed": {"value": 42, "ts": 1700000000},
+	"battery.voltage": {"value": 3.9, "ts": 1700000100.25},
+	"engine.ignition.status": {"value": true, "ts": 1700000000},
+	"ident": {"value": "353000000000000", "ts": 1700000000}
+}}]}`
+
+func TestGetDeviceTelemetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gw/devices/7/telemetry/all" {
+			t.Errorf("Expected path /gw/devices/7/telemetry/all, got %s", r.URL.Path)
+		}
+		w.Write([]byte(telemetryBody))
+	}))
+	defer server.Close()
+
+	telemetry, err := GetDeviceTelemetry(testhelper.New(server.URL), 7)
+	if err != nil {
+		t.Fatalf("GetDeviceTelemetry() error = %v", err)
+	}
+
+	if lat, lon, ok := telemetry.Position(); !ok || lat != 52.5 || lon != 13.4 {
+		t.Errorf("Expected position 52.5, 13.4, got %v, %v (%v)", lat, lon, ok)
+	}
+	if speed, _ := telemetry.Speed(); speed != 42 {
+		t.Errorf("Expected speed 42, got %v", speed)
+	}
+	if ignition, ok := telemetry.Ignition(); !ok || !ignition {
+		t.Error("Expected ignition on")
+	}
+	if ident, _ := telemetry.Parameters[ParamIdent].Text(); ident != "353000000000000" {
+		t.Errorf("Expected the ident, got %q", ident)
+	}
+
+	voltage, _ := telemetry.Get(ParamBatteryVoltage)
+	if want := time.Unix(1700000100, 250000000); !voltage.Time().Equal(want) || !telemetry.LastSeen().Equal(want) {
+		t.Errorf("Expected the voltage timestamp %v, got %v", want, voltage.Time())
+	}
+	if _, ok := telemetry.ExternalPowerVoltage(); ok {
+		t.Error("Expected no external power voltage")
+	}
+}
+
+func TestListDevicesTelemetryBySelector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gw/devices/7,8/telemetry/position.latitude,position.longitude" {
+			t.Errorf("Expected the parameters in the path, got %s", r.URL.Path)
+		}
+		w.Write([]byte(telemetryBody))
+	}))
+	defer server.Close()
+
+	telemetry, err := ListDevicesTelemetryBySelector(testhelper.New(server.URL), flespiapi.SelectIds(7, 8), ParamLatitude, ParamLongitude)
+	if err != nil {
+		t.Fatalf("ListDevicesTelemetryBySelector() error = %v", err)
+	}
+	if len(telemetry) != 1 || telemetry[0].DeviceId != 7 {
+		t.Errorf("Expected the telemetry of device 7, got %+v", telemetry)
+	}
+}
@@ -1,5 +1,7 @@
 package flespi_device
 
+import "github.com/mixser/flespi-client/internal/flespiapi"
+
 type Device struct {
 	Id   int64  `json:"id,omitempty"`
 	Name string `json:"name"`
@@ -54,5 +56,12 @@ func WithAccountId(accountId int64) CreateDeviceOption {
 }
 
 type devicesResponse struct {
-	Devices []Device `json:"result"`
+	Devices []Device                `json:"result"`
+	Errors  []flespiapi.ErrorDetail `json:"errors"`
 }
+
+// DevicesResult holds the devices returned by a bulk request and the per-item errors reported by flespi.
+type DevicesResult = flespiapi.MultiResult[Device]
+
+// DeviceIterator decodes devices from a list response one at a time, see IterateDevices.
+type DeviceIterator = flespiapi.Iterator[Device]
@@ -1,35 +1,78 @@
 package flespi_geofence
 
 import (
+	"context"
 	"fmt"
 	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
-func ListGeofences(c flespiapi.APIRequester) ([]Geofence, error) {
-	response := geofencesResponse{}
+// geofenceFields are requested for geofences unless a flespiapi.WithFields option overrides them.
+var geofenceFields = []string{"id", "name", "enabled", "priority", "geometry", "cid"}
 
-	err := c.RequestAPI("GET", "gw/geofences/all?fields=id,name,enabled,priority,geometry,cid", nil, &response)
+func ListGeofences(c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Geofence, error) {
+	return ListGeofencesWithContext(context.Background(), c, options...)
+}
 
+func ListGeofencesWithContext(ctx context.Context, c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Geofence, error) {
+	return ListGeofencesBySelectorWithContext(ctx, c, flespiapi.SelectAll(), options...)
+}
+
+// ListGeofencesBySelector returns the geofences matched by selector, e.g. {name=depot*}.
+func ListGeofencesBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Geofence, error) {
+	return ListGeofencesBySelectorWithContext(context.Background(), c, selector, options...)
+}
+
+func ListGeofencesBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Geofence, error) {
+	path, err := selector.Path()
 	if err != nil {
 		return nil, err
 	}
 
+	response := geofencesResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("gw/geofences/%s", path), geofenceFields, options...), nil, &response); err != nil {
+		return nil, err
+	}
+
 	return response.Geofences, nil
 }
 
-func GetGeofence(c flespiapi.APIRequester, geofenceId int64) (*Geofence, error) {
+// IterateGeofences streams the geofences matched by selector; large polygons are decoded one at a time.
+// Close the iterator when done.
+func IterateGeofences(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *GeofenceIterator {
+	return IterateGeofencesWithContext(context.Background(), c, selector, options...)
+}
+
+func IterateGeofencesWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *GeofenceIterator {
+	path, err := selector.Path()
+	if err != nil {
+		return flespiapi.FailedIterator[Geofence](err)
+	}
+
+	return flespiapi.NewIterator[Geofence](ctx, c, flespiapi.Endpoint(fmt.Sprintf("gw/geofences/%s", path), geofenceFields, options...), nil)
+}
+
+func GetGeofence(c flespiapi.APIRequester, geofenceId int64, options ...flespiapi.RequestOption) (*Geofence, error) {
+	return GetGeofenceWithContext(context.Background(), c, geofenceId, options...)
+}
+
+func GetGeofenceWithContext(ctx context.Context, c flespiapi.APIRequester, geofenceId int64, options ...flespiapi.RequestOption) (*Geofence, error) {
 	response := geofencesResponse{}
 
-	err := c.RequestAPI("GET", fmt.Sprintf("gw/geofences/%d?fields=id,name,enabled,priority,geometry,cid", geofenceId), nil, &response)
+	err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("gw/geofences/%d", geofenceId), geofenceFields, options...), nil, &response)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.Geofences[0], nil
+	return flespiapi.First(response.Geofences, response.Errors)
 }
 
 func NewGeofence(c flespiapi.APIRequester, name string, options ...CreateGeofenceOption) (*Geofence, error) {
+	return NewGeofenceWithContext(context.Background(), c, name, options...)
+}
+
+func NewGeofenceWithContext(ctx context.Context, c flespiapi.APIRequester, name string, options ...CreateGeofenceOption) (*Geofence, error) {
 	geofence := Geofence{Name: name}
 
 	for _, opt := range options {
@@ -49,14 +92,60 @@ func NewGeofence(c flespiapi.APIRequester, name string, options ...CreateGeofenc
 
 	response := geofencesResponse{}
 
-	if err := c.RequestAPIWithHeaders("POST", "gw/geofences?fields=id,name,enabled,priority,geometry,cid", headers, []Geofence{geofence}, &response); err != nil {
+	if err := c.RequestAPIWithContextAndHeaders(ctx, "POST", flespiapi.Endpoint("gw/geofences", geofenceFields), headers, []Geofence{geofence}, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Geofences[0], nil
+	return flespiapi.First(response.Geofences, response.Errors)
+}
+
+// NewGeofences creates many geofences at once, grouped into one request per subaccount.
+func NewGeofences(c flespiapi.APIRequester, geofences []Geofence) (*GeofencesResult, error) {
+	return NewGeofencesWithContext(context.Background(), c, geofences)
+}
+
+func NewGeofencesWithContext(ctx context.Context, c flespiapi.APIRequester, geofences []Geofence) (*GeofencesResult, error) {
+	result := &GeofencesResult{}
+
+	if len(geofences) == 0 {
+		return result, nil
+	}
+
+	accountIds, groups := flespiapi.GroupByAccount(geofences, func(geofence Geofence) int64 { return geofence.AccountId })
+
+	for _, accountId := range accountIds {
+		var headers map[string]string
+		if accountId != 0 {
+			headers = map[string]string{
+				"x-flespi-cid": fmt.Sprintf("%d", accountId),
+			}
+		}
+
+		// Id is assigned by flespi and AccountId (cid) is conveyed via header;
+		// the groups are copies, so clearing them is safe
+		batch := groups[accountId]
+		for i := range batch {
+			batch[i].Id = 0
+			batch[i].AccountId = 0
+		}
+
+		response := geofencesResponse{}
+
+		if err := c.RequestAPIWithContextAndHeaders(ctx, "POST", "gw/geofences", headers, batch, &response); err != nil {
+			return result, err
+		}
+
+		result.Append(response.Geofences, response.Errors, func(geofence Geofence) int64 { return geofence.Id })
+	}
+
+	return result, nil
 }
 
 func UpdateGeofence(c flespiapi.APIRequester, geofence Geofence) (*Geofence, error) {
+	return UpdateGeofenceWithContext(context.Background(), c, geofence)
+}
+
+func UpdateGeofenceWithContext(ctx context.Context, c flespiapi.APIRequester, geofence Geofence) (*Geofence, error) {
 	response := geofencesResponse{}
 
 	geofenceId := geofence.Id
@@ -77,17 +166,68 @@ func UpdateGeofence(c flespiapi.APIRequester, geofence Geofence) (*Geofence, err
 		}
 	}
 
-	if err := c.RequestAPIWithHeaders("PUT", fmt.Sprintf("gw/geofences/%d", geofenceId), headers, geofence, &response); err != nil {
+	if err := c.RequestAPIWithContextAndHeaders(ctx, "PUT", fmt.Sprintf("gw/geofences/%d", geofenceId), headers, geofence, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Geofences[0], nil
+	return flespiapi.First(response.Geofences, response.Errors)
 }
 
 func DeleteGeofence(c flespiapi.APIRequester, geofence Geofence) error {
-	return DeleteGeofenceById(c, geofence.Id)
+	return DeleteGeofenceWithContext(context.Background(), c, geofence)
+}
+
+func DeleteGeofenceWithContext(ctx context.Context, c flespiapi.APIRequester, geofence Geofence) error {
+	return DeleteGeofenceByIdWithContext(ctx, c, geofence.Id)
 }
 
 func DeleteGeofenceById(c flespiapi.APIRequester, geofenceId int64) error {
-	return c.RequestAPI("DELETE", fmt.Sprintf("gw/geofences/%d", geofenceId), nil, nil)
+	return DeleteGeofenceByIdWithContext(context.Background(), c, geofenceId)
+}
+
+func DeleteGeofenceByIdWithContext(ctx context.Context, c flespiapi.APIRequester, geofenceId int64) error {
+	return c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("gw/geofences/%d", geofenceId), nil, nil)
+}
+
+// UpdateGeofencesBySelector applies changes, e.g. {"priority": 5}, to all matched geofences.
+func UpdateGeofencesBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*GeofencesResult, error) {
+	return UpdateGeofencesBySelectorWithContext(context.Background(), c, selector, changes)
+}
+
+func UpdateGeofencesBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*GeofencesResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	response := geofencesResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("gw/geofences/%s", path), changes, &response); err != nil {
+		return nil, err
+	}
+
+	result := &GeofencesResult{}
+	result.Append(response.Geofences, response.Errors, func(geofence Geofence) int64 { return geofence.Id })
+
+	return result, nil
+}
+
+// DeleteGeofencesBySelector deletes a set of geofences in one request.
+func DeleteGeofencesBySelector(c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteGeofencesBySelectorWithContext(context.Background(), c, selector)
+}
+
+func DeleteGeofencesBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &flespiapi.DeleteResult{}
+
+	if err := c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("gw/geofences/%s", path), nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
@@ -1,9 +1,14 @@
 package flespi_geofence
 
-import "github.com/mixser/flespi-client/internal/flespiapi"
+import (
+	"context"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
 
 // GeofenceClient provides receiver-based methods for managing Flespi geofences.
 // Access it via Client.Geofences after creating a flespi.Client.
+// Every method has a ...WithContext variant that takes a context.Context first.
 type GeofenceClient struct {
 	c flespiapi.APIRequester
 }
@@ -17,22 +22,86 @@ func (gc *GeofenceClient) Create(name string, options ...CreateGeofenceOption) (
 	return NewGeofence(gc.c, name, options...)
 }
 
-func (gc *GeofenceClient) List() ([]Geofence, error) {
-	return ListGeofences(gc.c)
+func (gc *GeofenceClient) CreateWithContext(ctx context.Context, name string, options ...CreateGeofenceOption) (*Geofence, error) {
+	return NewGeofenceWithContext(ctx, gc.c, name, options...)
+}
+
+func (gc *GeofenceClient) CreateMany(geofences []Geofence) (*GeofencesResult, error) {
+	return NewGeofences(gc.c, geofences)
+}
+
+func (gc *GeofenceClient) CreateManyWithContext(ctx context.Context, geofences []Geofence) (*GeofencesResult, error) {
+	return NewGeofencesWithContext(ctx, gc.c, geofences)
+}
+
+func (gc *GeofenceClient) List(options ...flespiapi.RequestOption) ([]Geofence, error) {
+	return ListGeofences(gc.c, options...)
+}
+
+func (gc *GeofenceClient) ListWithContext(ctx context.Context, options ...flespiapi.RequestOption) ([]Geofence, error) {
+	return ListGeofencesWithContext(ctx, gc.c, options...)
+}
+
+func (gc *GeofenceClient) ListBySelector(selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Geofence, error) {
+	return ListGeofencesBySelector(gc.c, selector, options...)
+}
+
+func (gc *GeofenceClient) ListBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Geofence, error) {
+	return ListGeofencesBySelectorWithContext(ctx, gc.c, selector, options...)
+}
+
+func (gc *GeofenceClient) Iterate(selector flespiapi.Selector, options ...flespiapi.RequestOption) *GeofenceIterator {
+	return IterateGeofences(gc.c, selector, options...)
+}
+
+func (gc *GeofenceClient) IterateWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) *GeofenceIterator {
+	return IterateGeofencesWithContext(ctx, gc.c, selector, options...)
 }
 
-func (gc *GeofenceClient) GetById(geofenceId int64) (*Geofence, error) {
-	return GetGeofence(gc.c, geofenceId)
+func (gc *GeofenceClient) GetById(geofenceId int64, options ...flespiapi.RequestOption) (*Geofence, error) {
+	return GetGeofence(gc.c, geofenceId, options...)
+}
+
+func (gc *GeofenceClient) GetByIdWithContext(ctx context.Context, geofenceId int64, options ...flespiapi.RequestOption) (*Geofence, error) {
+	return GetGeofenceWithContext(ctx, gc.c, geofenceId, options...)
 }
 
 func (gc *GeofenceClient) Update(geofence Geofence) (*Geofence, error) {
 	return UpdateGeofence(gc.c, geofence)
 }
 
+func (gc *GeofenceClient) UpdateWithContext(ctx context.Context, geofence Geofence) (*Geofence, error) {
+	return UpdateGeofenceWithContext(ctx, gc.c, geofence)
+}
+
 func (gc *GeofenceClient) Delete(geofence Geofence) error {
 	return DeleteGeofence(gc.c, geofence)
 }
 
+func (gc *GeofenceClient) DeleteWithContext(ctx context.Context, geofence Geofence) error {
+	return DeleteGeofenceWithContext(ctx, gc.c, geofence)
+}
+
 func (gc *GeofenceClient) DeleteById(geofenceId int64) error {
 	return DeleteGeofenceById(gc.c, geofenceId)
 }
+
+func (gc *GeofenceClient) DeleteByIdWithContext(ctx context.Context, geofenceId int64) error {
+	return DeleteGeofenceByIdWithContext(ctx, gc.c, geofenceId)
+}
+
+func (gc *GeofenceClient) UpdateBySelector(selector flespiapi.Selector, changes map[string]interface{}) (*GeofencesResult, error) {
+	return UpdateGeofencesBySelector(gc.c, selector, changes)
+}
+
+func (gc *GeofenceClient) UpdateBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, changes map[string]interface{}) (*GeofencesResult, error) {
+	return UpdateGeofencesBySelectorWithContext(ctx, gc.c, selector, changes)
+}
+
+func (gc *GeofenceClient) DeleteBySelector(selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteGeofencesBySelector(gc.c, selector)
+}
+
+func (gc *GeofenceClient) DeleteBySelectorWithContext(ctx context.Context, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteGeofencesBySelectorWithContext(ctx, gc.c, selector)
+}
@@ -1,6 +1,10 @@
 package flespi_geofence
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
 
 type GeofenceGeometry interface {
 	GetType() string
@@ -100,9 +104,16 @@ func (p *Corridor) GetType() string {
 }
 
 type geofencesResponse struct {
-	Geofences []Geofence `json:"result"`
+	Geofences []Geofence              `json:"result"`
+	Errors    []flespiapi.ErrorDetail `json:"errors"`
 }
 
+// GeofencesResult holds the geofences returned by a bulk request and the per-item errors reported by flespi.
+type GeofencesResult = flespiapi.MultiResult[Geofence]
+
+// GeofenceIterator decodes geofences from a list response one at a time, see IterateGeofences.
+type GeofenceIterator = flespiapi.Iterator[Geofence]
+
 type CreateGeofenceOption func(*Geofence)
 
 func WithStatus(enabled bool) CreateGeofenceOption {
@@ -3,6 +3,10 @@ package flespi_geofence
 import "encoding/json"
 
 func UnmarshalGeometry(rawValue json.RawMessage) (GeofenceGeometry, error) {
+	if len(rawValue) == 0 || string(rawValue) == "null" {
+		return nil, nil
+	}
+
 	var circle Circle
 
 	if err := json.Unmarshal(rawValue, &circle); err == nil {
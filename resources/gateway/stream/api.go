@@ -1,12 +1,20 @@
 package flespi_stream
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
+// streamFields are requested by GetStream unless a flespiapi.WithFields option overrides them.
+var streamFields = []string{"id", "name", "protocol_id", "enabled", "queue_ttl", "validate_message", "configuration", "metadata", "cid"}
+
 func NewStream(c flespiapi.APIRequester, name string, protocolId int64, options ...CreateStreamOption) (*Stream, error) {
+	return NewStreamWithContext(context.Background(), c, name, protocolId, options...)
+}
+
+func NewStreamWithContext(ctx context.Context, c flespiapi.APIRequester, name string, protocolId int64, options ...CreateStreamOption) (*Stream, error) {
 	stream := Stream{
 		Name:          name,
 		ProtocolId:    protocolId,
@@ -30,40 +38,122 @@ func NewStream(c flespiapi.APIRequester, name string, protocolId int64, options
 	stream.AccountId = 0
 	defer func() { stream.AccountId = accountId }()
 
-	if err := c.RequestAPIWithHeaders("POST", "gw/streams", headers, []Stream{stream}, &response); err != nil {
+	if err := c.RequestAPIWithContextAndHeaders(ctx, "POST", "gw/streams", headers, []Stream{stream}, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Streams[0], nil
+	return flespiapi.First(response.Streams, response.Errors)
+}
+
+func GetStream(c flespiapi.APIRequester, streamId int64, options ...flespiapi.RequestOption) (*Stream, error) {
+	return GetStreamWithContext(context.Background(), c, streamId, options...)
 }
 
-func GetStream(c flespiapi.APIRequester, streamId int64) (*Stream, error) {
+func GetStreamWithContext(ctx context.Context, c flespiapi.APIRequester, streamId int64, options ...flespiapi.RequestOption) (*Stream, error) {
 	response := streamsResponse{}
 
-	err := c.RequestAPI("GET", fmt.Sprintf("gw/streams/%d?fields=id,name,protocol_id,enabled,queue_ttl,validate_message,configuration,metadata,cid", streamId), nil, &response)
+	err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("gw/streams/%d", streamId), streamFields, options...), nil, &response)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.Streams[0], nil
+	return flespiapi.First(response.Streams, response.Errors)
 }
 
-func ListStreams(c flespiapi.APIRequester) ([]Stream, error) {
-	response := streamsResponse{}
+// NewStreams creates all streams in one request per AccountId. Streams that flespi
+// rejected are listed in the result's Errors.
+func NewStreams(c flespiapi.APIRequester, streams []Stream) (*StreamsResult, error) {
+	return NewStreamsWithContext(context.Background(), c, streams)
+}
+
+func NewStreamsWithContext(ctx context.Context, c flespiapi.APIRequester, streams []Stream) (*StreamsResult, error) {
+	result := &StreamsResult{}
+
+	if len(streams) == 0 {
+		return result, nil
+	}
+
+	accountIds, groups := flespiapi.GroupByAccount(streams, func(stream Stream) int64 { return stream.AccountId })
+
+	for _, accountId := range accountIds {
+		var headers map[string]string
+		if accountId != 0 {
+			headers = map[string]string{
+				"x-flespi-cid": fmt.Sprintf("%d", accountId),
+			}
+		}
+
+		// Id is assigned by flespi and AccountId (cid) is conveyed via header;
+		// the groups are copies, so clearing them is safe
+		batch := groups[accountId]
+		for i := range batch {
+			batch[i].Id = 0
+			batch[i].AccountId = 0
+		}
+
+		response := streamsResponse{}
+
+		if err := c.RequestAPIWithContextAndHeaders(ctx, "POST", "gw/streams", headers, batch, &response); err != nil {
+			return result, err
+		}
 
-	err := c.RequestAPI("GET", "gw/streams/all", nil, &response)
+		result.Append(response.Streams, response.Errors, func(stream Stream) int64 { return stream.Id })
+	}
+
+	return result, nil
+}
+
+func ListStreams(c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Stream, error) {
+	return ListStreamsWithContext(context.Background(), c, options...)
+}
+
+func ListStreamsWithContext(ctx context.Context, c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Stream, error) {
+	return ListStreamsBySelectorWithContext(ctx, c, flespiapi.SelectAll(), options...)
+}
+
+// ListStreamsBySelector returns the streams matched by selector, e.g. {enabled=false}.
+func ListStreamsBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Stream, error) {
+	return ListStreamsBySelectorWithContext(context.Background(), c, selector, options...)
+}
 
+func ListStreamsBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Stream, error) {
+	path, err := selector.Path()
 	if err != nil {
 		return nil, err
 	}
 
+	response := streamsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("gw/streams/%s", path), nil, options...), nil, &response); err != nil {
+		return nil, err
+	}
+
 	return response.Streams, nil
 }
 
+// IterateStreams walks the streams matched by selector one at a time.
+// Close the iterator when done.
+func IterateStreams(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *StreamIterator {
+	return IterateStreamsWithContext(context.Background(), c, selector, options...)
+}
+
+func IterateStreamsWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *StreamIterator {
+	path, err := selector.Path()
+	if err != nil {
+		return flespiapi.FailedIterator[Stream](err)
+	}
+
+	return flespiapi.NewIterator[Stream](ctx, c, flespiapi.Endpoint(fmt.Sprintf("gw/streams/%s", path), nil, options...), nil)
+}
+
 func UpdateStream(c flespiapi.APIRequester, stream Stream) (*Stream, error) {
+	return UpdateStreamWithContext(context.Background(), c, stream)
+}
+
+func UpdateStreamWithContext(ctx context.Context, c flespiapi.APIRequester, stream Stream) (*Stream, error) {
 	if stream.Id == 0 {
-		return nil, fmt.Errorf("ID must be provided")
+		return nil, flespiapi.Invalid("ID must be provided")
 	}
 
 	streamId := stream.Id
@@ -86,21 +176,72 @@ func UpdateStream(c flespiapi.APIRequester, stream Stream) (*Stream, error) {
 
 	response := streamsResponse{}
 
-	if err := c.RequestAPIWithHeaders("PUT", fmt.Sprintf("gw/streams/%d", streamId), headers, stream, &response); err != nil {
+	if err := c.RequestAPIWithContextAndHeaders(ctx, "PUT", fmt.Sprintf("gw/streams/%d", streamId), headers, stream, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Streams[0], nil
+	return flespiapi.First(response.Streams, response.Errors)
 }
 
 func DeleteStreamById(c flespiapi.APIRequester, streamId int64) error {
-	return c.RequestAPI("DELETE", fmt.Sprintf("gw/streams/%d", streamId), nil, nil)
+	return DeleteStreamByIdWithContext(context.Background(), c, streamId)
+}
+
+func DeleteStreamByIdWithContext(ctx context.Context, c flespiapi.APIRequester, streamId int64) error {
+	return c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("gw/streams/%d", streamId), nil, nil)
 }
 
 func DeleteStream(c flespiapi.APIRequester, stream Stream) error {
+	return DeleteStreamWithContext(context.Background(), c, stream)
+}
+
+func DeleteStreamWithContext(ctx context.Context, c flespiapi.APIRequester, stream Stream) error {
 	if stream.Id == 0 {
-		return fmt.Errorf("ID must be provided")
+		return flespiapi.Invalid("ID must be provided")
+	}
+
+	return DeleteStreamByIdWithContext(ctx, c, stream.Id)
+}
+
+// UpdateStreamsBySelector updates the listed fields of all streams matched by selector.
+func UpdateStreamsBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*StreamsResult, error) {
+	return UpdateStreamsBySelectorWithContext(context.Background(), c, selector, changes)
+}
+
+func UpdateStreamsBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*StreamsResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	response := streamsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("gw/streams/%s", path), changes, &response); err != nil {
+		return nil, err
+	}
+
+	result := &StreamsResult{}
+	result.Append(response.Streams, response.Errors, func(stream Stream) int64 { return stream.Id })
+
+	return result, nil
+}
+
+// DeleteStreamsBySelector deletes the streams matched by selector and returns their ids.
+func DeleteStreamsBySelector(c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteStreamsBySelectorWithContext(context.Background(), c, selector)
+}
+
+func DeleteStreamsBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &flespiapi.DeleteResult{}
+
+	if err := c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("gw/streams/%s", path), nil, result); err != nil {
+		return nil, err
 	}
 
-	return DeleteStreamById(c, stream.Id)
+	return result, nil
 }
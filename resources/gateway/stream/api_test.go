@@ -1,10 +1,12 @@
 package flespi_stream
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/mixser/flespi-client/internal/flespiapi"
 	"github.com/mixser/flespi-client/internal/testhelper"
 )
 
@@ -197,3 +199,23 @@ func TestUpdateStream_MissingID(t *testing.T) {
 		t.Errorf("Expected error for stream without ID, got nil")
 	}
 }
+
+func TestNewStreams(t *testing.T) {
+	testhelper.CheckBulkCreate(t, func(c flespiapi.APIRequester, ids []int64) (int, []int64, error) {
+		streams := make([]Stream, len(ids))
+		for i, id := range ids {
+			streams[i] = Stream{Id: id, Name: fmt.Sprintf("streams-%d", i)}
+		}
+
+		result, err := NewStreams(c, streams)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		inputIds := make([]int64, len(streams))
+		for i, item := range streams {
+			inputIds[i] = item.Id
+		}
+		return len(result.Items), inputIds, nil
+	})
+}
@@ -1,9 +1,14 @@
 package flespi_stream
 
-import "github.com/mixser/flespi-client/internal/flespiapi"
+import (
+	"context"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
 
 // StreamClient provides receiver-based methods for managing Flespi streams.
 // Access it via Client.Streams after creating a flespi.Client.
+// Every method has a ...WithContext variant that takes a context.Context first.
 type StreamClient struct {
 	c flespiapi.APIRequester
 }
@@ -17,22 +22,86 @@ func (sc *StreamClient) Create(name string, protocolId int64, options ...CreateS
 	return NewStream(sc.c, name, protocolId, options...)
 }
 
-func (sc *StreamClient) List() ([]Stream, error) {
-	return ListStreams(sc.c)
+func (sc *StreamClient) CreateWithContext(ctx context.Context, name string, protocolId int64, options ...CreateStreamOption) (*Stream, error) {
+	return NewStreamWithContext(ctx, sc.c, name, protocolId, options...)
+}
+
+func (sc *StreamClient) CreateMany(streams []Stream) (*StreamsResult, error) {
+	return NewStreams(sc.c, streams)
+}
+
+func (sc *StreamClient) CreateManyWithContext(ctx context.Context, streams []Stream) (*StreamsResult, error) {
+	return NewStreamsWithContext(ctx, sc.c, streams)
+}
+
+func (sc *StreamClient) List(options ...flespiapi.RequestOption) ([]Stream, error) {
+	return ListStreams(sc.c, options...)
+}
+
+func (sc *StreamClient) ListWithContext(ctx context.Context, options ...flespiapi.RequestOption) ([]Stream, error) {
+	return ListStreamsWithContext(ctx, sc.c, options...)
+}
+
+func (sc *StreamClient) ListBySelector(selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Stream, error) {
+	return ListStreamsBySelector(sc.c, selector, options...)
+}
+
+func (sc *StreamClient) ListBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Stream, error) {
+	return ListStreamsBySelectorWithContext(ctx, sc.c, selector, options...)
+}
+
+func (sc *StreamClient) Iterate(selector flespiapi.Selector, options ...flespiapi.RequestOption) *StreamIterator {
+	return IterateStreams(sc.c, selector, options...)
+}
+
+func (sc *StreamClient) IterateWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) *StreamIterator {
+	return IterateStreamsWithContext(ctx, sc.c, selector, options...)
 }
 
-func (sc *StreamClient) Get(streamId int64) (*Stream, error) {
-	return GetStream(sc.c, streamId)
+func (sc *StreamClient) Get(streamId int64, options ...flespiapi.RequestOption) (*Stream, error) {
+	return GetStream(sc.c, streamId, options...)
+}
+
+func (sc *StreamClient) GetWithContext(ctx context.Context, streamId int64, options ...flespiapi.RequestOption) (*Stream, error) {
+	return GetStreamWithContext(ctx, sc.c, streamId, options...)
 }
 
 func (sc *StreamClient) Update(stream Stream) (*Stream, error) {
 	return UpdateStream(sc.c, stream)
 }
 
+func (sc *StreamClient) UpdateWithContext(ctx context.Context, stream Stream) (*Stream, error) {
+	return UpdateStreamWithContext(ctx, sc.c, stream)
+}
+
 func (sc *StreamClient) Delete(stream Stream) error {
 	return DeleteStream(sc.c, stream)
 }
 
+func (sc *StreamClient) DeleteWithContext(ctx context.Context, stream Stream) error {
+	return DeleteStreamWithContext(ctx, sc.c, stream)
+}
+
 func (sc *StreamClient) DeleteById(streamId int64) error {
 	return DeleteStreamById(sc.c, streamId)
 }
+
+func (sc *StreamClient) DeleteByIdWithContext(ctx context.Context, streamId int64) error {
+	return DeleteStreamByIdWithContext(ctx, sc.c, streamId)
+}
+
+func (sc *StreamClient) UpdateBySelector(selector flespiapi.Selector, changes map[string]interface{}) (*StreamsResult, error) {
+	return UpdateStreamsBySelector(sc.c, selector, changes)
+}
+
+func (sc *StreamClient) UpdateBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, changes map[string]interface{}) (*StreamsResult, error) {
+	return UpdateStreamsBySelectorWithContext(ctx, sc.c, selector, changes)
+}
+
+func (sc *StreamClient) DeleteBySelector(selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteStreamsBySelector(sc.c, selector)
+}
+
+func (sc *StreamClient) DeleteBySelectorWithContext(ctx context.Context, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteStreamsBySelectorWithContext(ctx, sc.c, selector)
+}
@@ -1,5 +1,7 @@
 package flespi_stream
 
+import "github.com/mixser/flespi-client/internal/flespiapi"
+
 type Stream struct {
 	Id int64 `json:"id,omitempty"`
 
@@ -67,5 +69,12 @@ func WithAccountId(accountId int64) CreateStreamOption {
 }
 
 type streamsResponse struct {
-	Streams []Stream `json:"result"`
+	Streams []Stream                `json:"result"`
+	Errors  []flespiapi.ErrorDetail `json:"errors"`
 }
+
+// StreamsResult holds the streams returned by a bulk request and the per-item errors reported by flespi.
+type StreamsResult = flespiapi.MultiResult[Stream]
+
+// StreamIterator decodes streams from a list response one at a time, see IterateStreams.
+type StreamIterator = flespiapi.Iterator[Stream]
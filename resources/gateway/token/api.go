@@ -1,12 +1,17 @@
 package flespi_token
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
 func NewToken(c flespiapi.APIRequester, info string, options ...CreateTokenOption) (*Token, error) {
+	return NewTokenWithContext(context.Background(), c, info, options...)
+}
+
+func NewTokenWithContext(ctx context.Context, c flespiapi.APIRequester, info string, options ...CreateTokenOption) (*Token, error) {
 	token := Token{Info: info}
 
 	for _, opt := range options {
@@ -28,38 +33,119 @@ func NewToken(c flespiapi.APIRequester, info string, options ...CreateTokenOptio
 
 	response := tokensResponse{}
 
-	if err := c.RequestAPIWithHeaders("POST", "platform/tokens", headers, []Token{token}, &response); err != nil {
+	if err := c.RequestAPIWithContextAndHeaders(ctx, "POST", "platform/tokens", headers, []Token{token}, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Tokens[0], nil
+	return flespiapi.First(response.Tokens, response.Errors)
 }
 
-func ListTokens(c flespiapi.APIRequester) ([]Token, error) {
-	response := tokensResponse{}
+// NewTokens issues several tokens at once; tokens of different subaccounts go in separate requests.
+func NewTokens(c flespiapi.APIRequester, tokens []Token) (*TokensResult, error) {
+	return NewTokensWithContext(context.Background(), c, tokens)
+}
+
+func NewTokensWithContext(ctx context.Context, c flespiapi.APIRequester, tokens []Token) (*TokensResult, error) {
+	result := &TokensResult{}
+
+	if len(tokens) == 0 {
+		return result, nil
+	}
+
+	accountIds, groups := flespiapi.GroupByAccount(tokens, func(token Token) int64 { return token.AccountId })
+
+	for _, accountId := range accountIds {
+		var headers map[string]string
+		if accountId != 0 {
+			headers = map[string]string{
+				"x-flespi-cid": fmt.Sprintf("%d", accountId),
+			}
+		}
+
+		// Id is assigned by flespi and AccountId (cid) is conveyed via header;
+		// the groups are copies, so clearing them is safe
+		batch := groups[accountId]
+		for i := range batch {
+			batch[i].Id = 0
+			batch[i].AccountId = 0
+		}
 
-	err := c.RequestAPI("GET", "platform/tokens/all", nil, &response)
+		response := tokensResponse{}
+
+		if err := c.RequestAPIWithContextAndHeaders(ctx, "POST", "platform/tokens", headers, batch, &response); err != nil {
+			return result, err
+		}
+
+		result.Append(response.Tokens, response.Errors, func(token Token) int64 { return token.Id })
+	}
+
+	return result, nil
+}
 
+func ListTokens(c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Token, error) {
+	return ListTokensWithContext(context.Background(), c, options...)
+}
+
+func ListTokensWithContext(ctx context.Context, c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Token, error) {
+	return ListTokensBySelectorWithContext(ctx, c, flespiapi.SelectAll(), options...)
+}
+
+// ListTokensBySelector returns the tokens matched by selector, e.g. {info=ci-*}.
+func ListTokensBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Token, error) {
+	return ListTokensBySelectorWithContext(context.Background(), c, selector, options...)
+}
+
+func ListTokensBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Token, error) {
+	path, err := selector.Path()
 	if err != nil {
 		return nil, err
 	}
 
+	response := tokensResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("platform/tokens/%s", path), nil, options...), nil, &response); err != nil {
+		return nil, err
+	}
+
 	return response.Tokens, nil
 }
 
-func GetToken(c flespiapi.APIRequester, tokenId int64) (*Token, error) {
+// IterateTokens walks the tokens matched by selector one at a time.
+// Close the iterator when done.
+func IterateTokens(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *TokenIterator {
+	return IterateTokensWithContext(context.Background(), c, selector, options...)
+}
+
+func IterateTokensWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *TokenIterator {
+	path, err := selector.Path()
+	if err != nil {
+		return flespiapi.FailedIterator[Token](err)
+	}
+
+	return flespiapi.NewIterator[Token](ctx, c, flespiapi.Endpoint(fmt.Sprintf("platform/tokens/%s", path), nil, options...), nil)
+}
+
+func GetToken(c flespiapi.APIRequester, tokenId int64, options ...flespiapi.RequestOption) (*Token, error) {
+	return GetTokenWithContext(context.Background(), c, tokenId, options...)
+}
+
+func GetTokenWithContext(ctx context.Context, c flespiapi.APIRequester, tokenId int64, options ...flespiapi.RequestOption) (*Token, error) {
 	response := tokensResponse{}
 
-	err := c.RequestAPI("GET", fmt.Sprintf("platform/tokens/%d", tokenId), nil, &response)
+	err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("platform/tokens/%d", tokenId), nil, options...), nil, &response)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.Tokens[0], nil
+	return flespiapi.First(response.Tokens, response.Errors)
 }
 
 func UpdateToken(c flespiapi.APIRequester, token Token) (*Token, error) {
+	return UpdateTokenWithContext(context.Background(), c, token)
+}
+
+func UpdateTokenWithContext(ctx context.Context, c flespiapi.APIRequester, token Token) (*Token, error) {
 	response := tokensResponse{}
 
 	tokenId := token.Id
@@ -76,19 +162,71 @@ func UpdateToken(c flespiapi.APIRequester, token Token) (*Token, error) {
 		token.AccountId = tokenAccountId
 	}()
 
-	err := c.RequestAPI("PUT", fmt.Sprintf("platform/tokens/%d", tokenId), token, &response)
+	err := c.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("platform/tokens/%d", tokenId), token, &response)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.Tokens[0], nil
+	return flespiapi.First(response.Tokens, response.Errors)
 }
 
 func DeleteToken(c flespiapi.APIRequester, token Token) error {
-	return DeleteTokenById(c, token.Id)
+	return DeleteTokenWithContext(context.Background(), c, token)
+}
+
+func DeleteTokenWithContext(ctx context.Context, c flespiapi.APIRequester, token Token) error {
+	return DeleteTokenByIdWithContext(ctx, c, token.Id)
 }
 
 func DeleteTokenById(c flespiapi.APIRequester, tokenId int64) error {
-	return c.RequestAPI("DELETE", fmt.Sprintf("platform/tokens/%d", tokenId), nil, nil)
+	return DeleteTokenByIdWithContext(context.Background(), c, tokenId)
+}
+
+func DeleteTokenByIdWithContext(ctx context.Context, c flespiapi.APIRequester, tokenId int64) error {
+	return c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("platform/tokens/%d", tokenId), nil, nil)
+}
+
+// UpdateTokensBySelector updates the given fields of every token matched by selector,
+// e.g. {"enabled": false} to revoke a group of tokens at once.
+func UpdateTokensBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*TokensResult, error) {
+	return UpdateTokensBySelectorWithContext(context.Background(), c, selector, changes)
+}
+
+func UpdateTokensBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*TokensResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	response := tokensResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("platform/tokens/%s", path), changes, &response); err != nil {
+		return nil, err
+	}
+
+	result := &TokensResult{}
+	result.Append(response.Tokens, response.Errors, func(token Token) int64 { return token.Id })
+
+	return result, nil
+}
+
+// DeleteTokensBySelector deletes every token matched by selector.
+func DeleteTokensBySelector(c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteTokensBySelectorWithContext(context.Background(), c, selector)
+}
+
+func DeleteTokensBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &flespiapi.DeleteResult{}
+
+	if err := c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("platform/tokens/%s", path), nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
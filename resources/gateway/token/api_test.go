@@ -1,10 +1,16 @@
 package flespi_token
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/mixser/flespi-client/internal/flespiapi"
 	"github.com/mixser/flespi-client/internal/testhelper"
 )
 
@@ -123,3 +129,338 @@ func TestDeleteToken(t *testing.T) {
 		t.Errorf("DeleteTokenById() error = %v", err)
 	}
 }
+
+// tokenServer issues tokens key-1, key-2, ... and records deletions
+type tokenServer struct {
+	mu      sync.Mutex
+	issued  int64
+	updates int
+	deleted []int64
+
+	// failIssue and failDelete make token creation or deletion answer 500
+	failIssue  bool
+	failDelete bool
+
+	// block holds token creation until it is closed
+	block chan struct{}
+}
+
+func (ts *tokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if ts.block != nil && r.Method == http.MethodPost {
+		<-ts.block
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if (ts.failIssue && r.Method == http.MethodPost) || (ts.failDelete && r.Method == http.MethodDelete) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"errors": [{"reason": "service unavailable"}]}`))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var tokens []Token
+		json.NewDecoder(r.Body).Decode(&tokens)
+		ts.issued++
+		tokens[0].Id = ts.issued
+		tokens[0].Key = fmt.Sprintf("key-%d", ts.issued)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": tokens})
+	case http.MethodPut:
+		var token Token
+		json.NewDecoder(r.Body).Decode(&token)
+		ts.updates++
+		fmt.Sscanf(r.URL.Path, "/platform/tokens/%d", &token.Id)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": []Token{token}})
+	case http.MethodDelete:
+		var id int64
+		fmt.Sscanf(r.URL.Path, "/platform/tokens/%d", &id)
+		ts.deleted = append(ts.deleted, id)
+		w.Write([]byte(`{"result": []}`))
+	}
+}
+
+func TestTokenManager(t *testing.T) {
+	ts := &tokenServer{}
+	server := httptest.NewServer(ts)
+	defer server.Close()
+
+	now := time.Unix(1700000000, 0)
+	manager := NewTokenManager(testhelper.New(server.URL), ManagerConfig{Lifetime: time.Hour})
+	manager.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	key, err := manager.Token(ctx)
+	if err != nil || key != "key-1" {
+		t.Fatalf("Expected key-1, got %q (%v)", key, err)
+	}
+	if current := manager.Current(); !current.Enabled || current.Expire != now.Add(time.Hour).Unix() {
+		t.Errorf("Expected an enabled token expiring in an hour, got %+v", current)
+	}
+
+	now = now.Add(40 * time.Minute)
+	if key, _ := manager.Token(ctx); key != "key-1" {
+		t.Errorf("Expected key-1 to be reused before the renewal window, got %q", key)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if key, _ := manager.Token(ctx); key != "key-2" {
+		t.Errorf("Expected key-2 after renewal, got %q", key)
+	}
+	if len(ts.deleted) != 0 {
+		t.Errorf("Expected the superseded token to be kept until the next renewal, deleted %v", ts.deleted)
+	}
+
+	now = now.Add(time.Hour)
+	if key, _ := manager.Token(ctx); key != "key-3" {
+		t.Errorf("Expected key-3 after renewal, got %q", key)
+	}
+	if len(ts.deleted) != 1 || ts.deleted[0] != 1 {
+		t.Errorf("Expected token 1 to be deleted, got %v", ts.deleted)
+	}
+
+	if err := manager.Close(ctx); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if len(ts.deleted) != 3 {
+		t.Errorf("Expected all tokens to be deleted on Close, got %v", ts.deleted)
+	}
+}
+
+func TestTokenManager_Extend(t *testing.T) {
+	ts := &tokenServer{}
+	server := httptest.NewServer(ts)
+	defer server.Close()
+
+	now := time.Unix(1700000000, 0)
+	manager := NewTokenManager(testhelper.New(server.URL), ManagerConfig{Lifetime: time.Hour, Extend: true})
+	manager.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	manager.Token(ctx)
+
+	now = now.Add(55 * time.Minute)
+	key, err := manager.Token(ctx)
+	if err != nil || key != "key-1" {
+		t.Fatalf("Expected the extended token to keep its key, got %q (%v)", key, err)
+	}
+	if ts.updates != 1 || ts.issued != 1 {
+		t.Errorf("Expected 1 update and 1 issued token, got %d and %d", ts.updates, ts.issued)
+	}
+	if want := now.Add(time.Hour); !manager.ExpiresAt().Equal(want) {
+		t.Errorf("Expected expiry %v, got %v", want, manager.ExpiresAt())
+	}
+}
+
+func TestTokenManager_Refresh(t *testing.T) {
+	ts := &tokenServer{}
+	server := httptest.NewServer(ts)
+	defer server.Close()
+
+	now := time.Unix(1700000000, 0)
+	manager := NewTokenManager(testhelper.New(server.URL), ManagerConfig{})
+	manager.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	manager.Token(ctx)
+
+	// concurrent 401s right after issuing must not issue again
+	manager.Refresh(ctx)
+	if ts.issued != 1 {
+		t.Errorf("Expected no new token within the refresh interval, issued %d", ts.issued)
+	}
+
+	now = now.Add(time.Minute)
+	manager.Refresh(ctx)
+	if key, _ := manager.Token(ctx); key != "key-2" {
+		t.Errorf("Expected key-2 after refresh, got %q", key)
+	}
+}
+
+// testClock is a time source tests can move while the manager is in use
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestTokenManager_RenewalFailure(t *testing.T) {
+	ts := &tokenServer{}
+	server := httptest.NewServer(ts)
+	defer server.Close()
+
+	var reported []error
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	manager := NewTokenManager(testhelper.New(server.URL), ManagerConfig{
+		Lifetime: time.Hour,
+		OnError:  func(err error) { reported = append(reported, err) },
+	})
+	manager.now = clock.Now
+
+	ctx := context.Background()
+	manager.Token(ctx)
+
+	ts.mu.Lock()
+	ts.failIssue = true
+	ts.mu.Unlock()
+
+	// inside the renewal window the current token is still valid
+	clock.Add(50 * time.Minute)
+	key, err := manager.Token(ctx)
+	if err != nil || key != "key-1" {
+		t.Errorf("Expected key-1 while it is valid, got %q (%v)", key, err)
+	}
+	if len(reported) != 1 {
+		t.Errorf("Expected the failed renewal to be reported, got %v", reported)
+	}
+
+	// no new attempt until the retry pause is over
+	manager.Token(ctx)
+	if len(reported) != 1 {
+		t.Errorf("Expected no renewal within the retry pause, got %v", reported)
+	}
+
+	clock.Add(11 * time.Minute)
+	if _, err := manager.Token(ctx); err == nil {
+		t.Error("Expected an error once the token has expired")
+	}
+}
+
+func TestTokenManager_RenewalDoesNotBlock(t *testing.T) {
+	ts := &tokenServer{}
+	server := httptest.NewServer(ts)
+	defer server.Close()
+
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	manager := NewTokenManager(testhelper.New(server.URL), ManagerConfig{Lifetime: time.Hour})
+	manager.now = clock.Now
+
+	ctx := context.Background()
+	manager.Token(ctx)
+
+	ts.block = make(chan struct{})
+	clock.Add(50 * time.Minute)
+
+	renewed := make(chan string)
+	go func() {
+		key, _ := manager.Token(ctx)
+		renewed <- key
+	}()
+
+	// wait until the renewal is in flight
+	for {
+		manager.mu.Lock()
+		renewing := manager.renewing != nil
+		manager.mu.Unlock()
+		if renewing {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if key, err := manager.Token(ctx); err != nil || key != "key-1" {
+		t.Errorf("Expected key-1 during the renewal, got %q (%v)", key, err)
+	}
+
+	close(ts.block)
+	if key := <-renewed; key != "key-2" {
+		t.Errorf("Expected key-2 from the renewal, got %q", key)
+	}
+}
+
+func TestTokenManager_TTL(t *testing.T) {
+	ts := &tokenServer{}
+	server := httptest.NewServer(ts)
+	defer server.Close()
+
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	manager := NewTokenManager(testhelper.New(server.URL), ManagerConfig{
+		Lifetime: time.Hour,
+		Options:  []CreateTokenOption{WithTTL(60)},
+	})
+	manager.now = clock.Now
+
+	ctx := context.Background()
+	manager.Token(ctx)
+
+	// each use keeps the token alive
+	for i := 0; i < 3; i++ {
+		clock.Add(50 * time.Second)
+		if key, _ := manager.Token(ctx); key != "key-1" {
+			t.Errorf("Expected key-1 while it is in use, got %q", key)
+		}
+	}
+
+	clock.Add(2 * time.Minute)
+	if key, _ := manager.Token(ctx); key != "key-2" {
+		t.Errorf("Expected a new token after the TTL passed unused, got %q", key)
+	}
+}
+
+func TestTokenManager_CleanupError(t *testing.T) {
+	ts := &tokenServer{failDelete: true}
+	server := httptest.NewServer(ts)
+	defer server.Close()
+
+	var reported []error
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	manager := NewTokenManager(testhelper.New(server.URL), ManagerConfig{
+		Lifetime: time.Hour,
+		OnError:  func(err error) { reported = append(reported, err) },
+	})
+	manager.now = clock.Now
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		manager.Token(ctx)
+		clock.Add(time.Hour)
+	}
+
+	if len(reported) != 1 {
+		t.Errorf("Expected the failed deletion of token 1 to be reported, got %v", reported)
+	}
+
+	ts.mu.Lock()
+	ts.failDelete = false
+	ts.mu.Unlock()
+
+	if err := manager.Close(ctx); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if len(ts.deleted) != 3 {
+		t.Errorf("Expected the kept token to be deleted on Close, got %v", ts.deleted)
+	}
+}
+
+func TestNewTokens(t *testing.T) {
+	testhelper.CheckBulkCreate(t, func(c flespiapi.APIRequester, ids []int64) (int, []int64, error) {
+		tokens := make([]Token, len(ids))
+		for i, id := range ids {
+			tokens[i] = Token{Id: id, Info: fmt.Sprintf("tokens-%d", i)}
+		}
+
+		result, err := NewTokens(c, tokens)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		inputIds := make([]int64, len(tokens))
+		for i, item := range tokens {
+			inputIds[i] = item.Id
+		}
+		return len(result.Items), inputIds, nil
+	})
+}
@@ -1,9 +1,14 @@
 package flespi_token
 
-import "github.com/mixser/flespi-client/internal/flespiapi"
+import (
+	"context"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
 
 // TokenClient provides receiver-based methods for managing Flespi tokens.
 // Access it via Client.Tokens after creating a flespi.Client.
+// Every method has a ...WithContext variant that takes a context.Context first.
 type TokenClient struct {
 	c flespiapi.APIRequester
 }
@@ -17,22 +22,91 @@ func (tc *TokenClient) Create(info string, options ...CreateTokenOption) (*Token
 	return NewToken(tc.c, info, options...)
 }
 
-func (tc *TokenClient) List() ([]Token, error) {
-	return ListTokens(tc.c)
+func (tc *TokenClient) CreateWithContext(ctx context.Context, info string, options ...CreateTokenOption) (*Token, error) {
+	return NewTokenWithContext(ctx, tc.c, info, options...)
+}
+
+func (tc *TokenClient) CreateMany(tokens []Token) (*TokensResult, error) {
+	return NewTokens(tc.c, tokens)
+}
+
+func (tc *TokenClient) CreateManyWithContext(ctx context.Context, tokens []Token) (*TokensResult, error) {
+	return NewTokensWithContext(ctx, tc.c, tokens)
+}
+
+func (tc *TokenClient) List(options ...flespiapi.RequestOption) ([]Token, error) {
+	return ListTokens(tc.c, options...)
+}
+
+func (tc *TokenClient) ListWithContext(ctx context.Context, options ...flespiapi.RequestOption) ([]Token, error) {
+	return ListTokensWithContext(ctx, tc.c, options...)
 }
 
-func (tc *TokenClient) Get(tokenId int64) (*Token, error) {
-	return GetToken(tc.c, tokenId)
+func (tc *TokenClient) ListBySelector(selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Token, error) {
+	return ListTokensBySelector(tc.c, selector, options...)
+}
+
+func (tc *TokenClient) ListBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Token, error) {
+	return ListTokensBySelectorWithContext(ctx, tc.c, selector, options...)
+}
+
+func (tc *TokenClient) Iterate(selector flespiapi.Selector, options ...flespiapi.RequestOption) *TokenIterator {
+	return IterateTokens(tc.c, selector, options...)
+}
+
+func (tc *TokenClient) IterateWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) *TokenIterator {
+	return IterateTokensWithContext(ctx, tc.c, selector, options...)
+}
+
+func (tc *TokenClient) Get(tokenId int64, options ...flespiapi.RequestOption) (*Token, error) {
+	return GetToken(tc.c, tokenId, options...)
+}
+
+func (tc *TokenClient) GetWithContext(ctx context.Context, tokenId int64, options ...flespiapi.RequestOption) (*Token, error) {
+	return GetTokenWithContext(ctx, tc.c, tokenId, options...)
 }
 
 func (tc *TokenClient) Update(token Token) (*Token, error) {
 	return UpdateToken(tc.c, token)
 }
 
+func (tc *TokenClient) UpdateWithContext(ctx context.Context, token Token) (*Token, error) {
+	return UpdateTokenWithContext(ctx, tc.c, token)
+}
+
 func (tc *TokenClient) Delete(token Token) error {
 	return DeleteToken(tc.c, token)
 }
 
+func (tc *TokenClient) DeleteWithContext(ctx context.Context, token Token) error {
+	return DeleteTokenWithContext(ctx, tc.c, token)
+}
+
 func (tc *TokenClient) DeleteById(tokenId int64) error {
 	return DeleteTokenById(tc.c, tokenId)
 }
+
+func (tc *TokenClient) DeleteByIdWithContext(ctx context.Context, tokenId int64) error {
+	return DeleteTokenByIdWithContext(ctx, tc.c, tokenId)
+}
+
+func (tc *TokenClient) UpdateBySelector(selector flespiapi.Selector, changes map[string]interface{}) (*TokensResult, error) {
+	return UpdateTokensBySelector(tc.c, selector, changes)
+}
+
+func (tc *TokenClient) UpdateBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, changes map[string]interface{}) (*TokensResult, error) {
+	return UpdateTokensBySelectorWithContext(ctx, tc.c, selector, changes)
+}
+
+func (tc *TokenClient) DeleteBySelector(selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteTokensBySelector(tc.c, selector)
+}
+
+func (tc *TokenClient) DeleteBySelectorWithContext(ctx context.Context, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteTokensBySelectorWithContext(ctx, tc.c, selector)
+}
+
+// NewManager returns a TokenManager issuing tokens with this client, see NewTokenManager.
+func (tc *TokenClient) NewManager(config ManagerConfig) *TokenManager {
+	return NewTokenManager(tc.c, config)
+}
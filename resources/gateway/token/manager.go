@@ -0,0 +1,353 @@
+package flespi_token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
+
+// ManagerConfig defines the tokens a TokenManager issues and when it renews them
+type ManagerConfig struct {
+	// Info is stored on every issued token (default: "flespi-client managed token")
+	Info string
+
+	// Lifetime sets the expire time of issued tokens (default: 1 hour)
+	Lifetime time.Duration
+
+	// RenewBefore is how long before expiry the token is renewed (default: a fifth of Lifetime)
+	RenewBefore time.Duration
+
+	// Extend moves the expire time of the current token forward instead of
+	// issuing a new one; a new token is still issued after a 401 or a failed update
+	Extend bool
+
+	// Options configure issued tokens, e.g. WithAccess or WithTTL. A token with a
+	// TTL that has not been used for that long is treated as expired. For tokens of a
+	// subaccount pass a client from ForAccount to NewTokenManager instead of
+	// WithAccountId, so that renewals and deletions run in the same account.
+	Options []CreateTokenOption
+
+	// OnError receives the errors no caller sees: a renewal that failed while the
+	// current token was still valid, and superseded tokens that could not be
+	// deleted, which are retried on the next renewal and by Close
+	OnError func(err error)
+}
+
+// TokenManager issues short-lived tokens with a master token and renews them
+// before they expire. It implements the credential provider interfaces of the
+// flespi package, so it can be handed to flespi.WithCredentials:
+//
+//	master, _ := flespi.NewClient("https://flespi.io", masterToken)
+//	manager := flespi_token.NewTokenManager(master, flespi_token.ManagerConfig{Lifetime: time.Hour})
+//	defer manager.Close(context.Background())
+//
+//	client, _ := flespi.NewClient("https://flespi.io", "", flespi.WithCredentials(manager))
+//
+// Superseded tokens stay valid until the next renewal, so requests in flight can
+// finish, and are deleted then; Close deletes the remaining ones.
+//
+// One caller renews at a time, without holding up the others: they keep getting
+// the current token while it is valid, and only wait for the renewal once it has expired.
+type TokenManager struct {
+	c      flespiapi.APIRequester
+	config ManagerConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	current   *Token
+	issuedAt  time.Time
+	expiresAt time.Time
+	lastUsed  time.Time
+	retryAt   time.Time
+	retired   []int64
+	renewing  *renewal
+}
+
+// renewal is a renewal in progress; err is set before done is closed
+type renewal struct {
+	done chan struct{}
+	err  error
+}
+
+func (r *renewal) wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// refreshInterval keeps concurrent 401s from issuing a token each; a token
+// issued this recently is assumed to be the one they are waiting for. It is
+// also the pause after a failed renewal while the current token is still valid.
+const refreshInterval = 5 * time.Second
+
+// errClosed is returned to callers waiting for a renewal that Close overtook
+var errClosed = errors.New("token manager was closed")
+
+// NewTokenManager creates a manager issuing tokens through c, which must be
+// authorized with a token allowed to create tokens. No token is issued until
+// the first call to Token.
+func NewTokenManager(c flespiapi.APIRequester, config ManagerConfig) *TokenManager {
+	if config.Info == "" {
+		config.Info = "flespi-client managed token"
+	}
+	if config.Lifetime <= 0 {
+		config.Lifetime = time.Hour
+	}
+	if config.RenewBefore <= 0 || config.RenewBefore >= config.Lifetime {
+		config.RenewBefore = config.Lifetime / 5
+	}
+
+	return &TokenManager{c: c, config: config, now: time.Now}
+}
+
+// Token returns the key of the current token, renewing it first when it is
+// about to expire. If the renewal fails, the current token is returned as long
+// as it is valid; the error is reported to OnError.
+func (m *TokenManager) Token(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	now := m.now()
+	if m.valid(now) && (m.renewing != nil || now.Before(m.renewAt()) || now.Before(m.retryAt)) {
+		m.lastUsed = now
+		key := m.current.Key
+		m.mu.Unlock()
+		return key, nil
+	}
+	r, leader := m.begin()
+	m.mu.Unlock()
+
+	if leader {
+		m.run(ctx, r, false)
+	}
+	err := r.wait(ctx)
+
+	m.mu.Lock()
+	now = m.now()
+	if !m.valid(now) {
+		m.mu.Unlock()
+		if err == nil {
+			err = errClosed
+		}
+		return "", err
+	}
+	m.lastUsed = now
+	key := m.current.Key
+	m.mu.Unlock()
+
+	if err != nil && leader {
+		m.report(err)
+	}
+	return key, nil
+}
+
+// Refresh issues a new token regardless of the current one's expiry, e.g. after
+// it has been deleted or disabled
+func (m *TokenManager) Refresh(ctx context.Context) error {
+	m.mu.Lock()
+	if m.renewing == nil && m.current != nil && m.now().Sub(m.issuedAt) < refreshInterval {
+		m.mu.Unlock()
+		return nil
+	}
+	r, leader := m.begin()
+	m.mu.Unlock()
+
+	if leader {
+		m.run(ctx, r, true)
+	}
+	return r.wait(ctx)
+}
+
+// Current returns a copy of the token in use, or nil before the first call to Token
+func (m *TokenManager) Current() *Token {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.current == nil {
+		return nil
+	}
+
+	token := *m.current
+	return &token
+}
+
+// ExpiresAt returns when the token in use expires
+func (m *TokenManager) ExpiresAt() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.expiresAt
+}
+
+// Close deletes the current and all superseded tokens. The manager issues a new
+// token if it is used afterwards.
+func (m *TokenManager) Close(ctx context.Context) error {
+	m.mu.Lock()
+	r := m.renewing
+	m.mu.Unlock()
+
+	// let a running renewal finish, so the token it issues is deleted too
+	if r != nil {
+		r.wait(ctx)
+	}
+
+	m.mu.Lock()
+	if m.current != nil {
+		m.retired = append(m.retired, m.current.Id)
+		m.current = nil
+		m.expiresAt = time.Time{}
+	}
+	m.mu.Unlock()
+
+	return m.cleanup(ctx)
+}
+
+// valid reports whether the current token can still be used at now; the caller holds mu
+func (m *TokenManager) valid(now time.Time) bool {
+	if m.current == nil {
+		return false
+	}
+
+	until := m.expiresAt
+	if m.current.TTL > 0 {
+		// flespi drops a token that has not been used for its TTL
+		if idle := m.lastUsed.Add(time.Duration(m.current.TTL) * time.Second); idle.Before(until) {
+			until = idle
+		}
+	}
+
+	return now.Before(until)
+}
+
+// renewAt returns when the current token is due for renewal; the caller holds mu
+func (m *TokenManager) renewAt() time.Time {
+	return m.expiresAt.Add(-m.config.RenewBefore)
+}
+
+// begin joins the renewal in progress or starts one, in which case the caller
+// is the leader and must run it; the caller holds mu
+func (m *TokenManager) begin() (*renewal, bool) {
+	if m.renewing != nil {
+		return m.renewing, false
+	}
+
+	m.renewing = &renewal{done: make(chan struct{})}
+	return m.renewing, true
+}
+
+// run performs renewal r and wakes up the callers waiting for it
+func (m *TokenManager) run(ctx context.Context, r *renewal, force bool) {
+	err := m.renew(ctx, force)
+
+	m.mu.Lock()
+	m.renewing = nil
+	if err != nil {
+		m.retryAt = m.now().Add(refreshInterval)
+	}
+	m.mu.Unlock()
+
+	r.err = err
+	close(r.done)
+}
+
+// renew extends or replaces the current token; mu is only held to read and
+// install the token, not during the requests
+func (m *TokenManager) renew(ctx context.Context, force bool) error {
+	m.mu.Lock()
+	current := m.current
+	expire := m.now().Add(m.config.Lifetime)
+	m.mu.Unlock()
+
+	if m.config.Extend && !force && current != nil {
+		extended := *current
+		extended.Expire = expire.Unix()
+
+		if updated, err := UpdateTokenWithContext(ctx, m.c, extended); err == nil {
+			m.mu.Lock()
+			m.use(updated, expire)
+			m.mu.Unlock()
+			return nil
+		}
+		// the token may be gone already, fall back to issuing a new one
+	}
+
+	options := append([]CreateTokenOption{WithStatus(true)}, m.config.Options...)
+	options = append(options, WithExpire(expire.Unix()))
+
+	token, err := NewTokenWithContext(ctx, m.c, m.config.Info, options...)
+	if err != nil {
+		return fmt.Errorf("failed to issue token: %w", err)
+	}
+
+	// tokens retired by the previous renewal have had a full period to drain
+	if err := m.cleanup(ctx); err != nil {
+		m.report(err)
+	}
+
+	m.mu.Lock()
+	if m.current != nil {
+		m.retired = append(m.retired, m.current.Id)
+	}
+	m.use(token, expire)
+	m.issuedAt = m.now()
+	m.mu.Unlock()
+
+	return nil
+}
+
+// report hands an error no caller receives to OnError
+func (m *TokenManager) report(err error) {
+	if m.config.OnError != nil {
+		m.config.OnError(err)
+	}
+}
+
+// use makes token current; the caller holds mu
+func (m *TokenManager) use(token *Token, expire time.Time) {
+	// keep the key, flespi does not return it on update
+	if token.Key == "" && m.current != nil && m.current.Id == token.Id {
+		token.Key = m.current.Key
+	}
+
+	m.current = token
+	m.lastUsed = m.now()
+	m.expiresAt = expire
+	if token.Expire > 0 {
+		m.expiresAt = time.Unix(token.Expire, 0)
+	}
+}
+
+// cleanup deletes retired tokens and keeps those that could not be deleted for
+// the next attempt; mu is not held during the requests
+func (m *TokenManager) cleanup(ctx context.Context) error {
+	m.mu.Lock()
+	retired := m.retired
+	m.retired = nil
+	m.mu.Unlock()
+
+	var firstErr error
+	var remaining []int64
+
+	for _, id := range retired {
+		err := DeleteTokenByIdWithContext(ctx, m.c, id)
+		if err == nil || errors.Is(err, flespiapi.ErrNotFound) {
+			continue
+		}
+
+		remaining = append(remaining, id)
+		if firstErr == nil {
+			firstErr = fmt.Errorf("failed to delete token %d: %w", id, err)
+		}
+	}
+
+	m.mu.Lock()
+	m.retired = append(remaining, m.retired...)
+	m.mu.Unlock()
+
+	return firstErr
+}
@@ -1,5 +1,7 @@
 package flespi_token
 
+import "github.com/mixser/flespi-client/internal/flespiapi"
+
 type Token struct {
 	Id  int64  `json:"id,omitempty"`
 	Key string `json:"key,omitempty"`
@@ -55,5 +57,12 @@ func WithAccess(access TokenAccess) CreateTokenOption {
 }
 
 type tokensResponse struct {
-	Tokens []Token `json:"result"`
+	Tokens []Token                 `json:"result"`
+	Errors []flespiapi.ErrorDetail `json:"errors"`
 }
+
+// TokensResult holds the tokens returned by a bulk request and the per-item errors reported by flespi.
+type TokensResult = flespiapi.MultiResult[Token]
+
+// TokenIterator decodes tokens from a list response one at a time, see IterateTokens.
+type TokenIterator = flespiapi.Iterator[Token]
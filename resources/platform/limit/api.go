@@ -1,12 +1,20 @@
 package flespi_limit
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
+// limitFields are requested by GetLimit unless a flespiapi.WithFields option overrides them.
+var limitFields = []string{"id", "name", "description", "blocking_duration", "api_calls", "api_traffic", "channels_count", "channel_messages", "channel_storage", "channel_traffic", "channel_connections", "containers_count", "container_storage", "cdns_count", "cdn_storage", "cdn_traffic", "devices_count", "device_storage", "device_media_traffic", "device_media_storage", "streams_count", "stream_storage", "stream_traffic", "modems_count", "mqtt_sessions", "mqtt_messages", "mqtt_session_storage", "mqtt_retained_storage", "mqtt_subscriptions", "sms_count", "tokens_count", "subaccounts_count", "limits_count", "realms_count", "calcs_count", "calcs_storage", "plugins_count", "plugin_traffic", "plugin_buffered_messages", "groups_count", "webhooks_count", "webhook_storage", "webhook_traffic", "grants_count", "identity_providers_count", "cid"}
+
 func NewLimit(c flespiapi.APIRequester, name string, options ...CreateLimitOption) (*Limit, error) {
+	return NewLimitWithContext(context.Background(), c, name, options...)
+}
+
+func NewLimitWithContext(ctx context.Context, c flespiapi.APIRequester, name string, options ...CreateLimitOption) (*Limit, error) {
 	limit := Limit{Name: name}
 
 	for _, opt := range options {
@@ -26,40 +34,121 @@ func NewLimit(c flespiapi.APIRequester, name string, options ...CreateLimitOptio
 
 	response := limitsResponse{}
 
-	if err := c.RequestAPIWithHeaders("POST", "platform/limits", headers, []Limit{limit}, &response); err != nil {
+	if err := c.RequestAPIWithContextAndHeaders(ctx, "POST", "platform/limits", headers, []Limit{limit}, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Limits[0], nil
+	return flespiapi.First(response.Limits, response.Errors)
 }
 
-func ListLimits(c flespiapi.APIRequester) ([]Limit, error) {
-	response := limitsResponse{}
+// NewLimits creates several limits, one request per owning subaccount.
+func NewLimits(c flespiapi.APIRequester, limits []Limit) (*LimitsResult, error) {
+	return NewLimitsWithContext(context.Background(), c, limits)
+}
+
+func NewLimitsWithContext(ctx context.Context, c flespiapi.APIRequester, limits []Limit) (*LimitsResult, error) {
+	result := &LimitsResult{}
+
+	if len(limits) == 0 {
+		return result, nil
+	}
+
+	accountIds, groups := flespiapi.GroupByAccount(limits, func(limit Limit) int64 { return limit.AccountId })
+
+	for _, accountId := range accountIds {
+		var headers map[string]string
+		if accountId != 0 {
+			headers = map[string]string{
+				"x-flespi-cid": fmt.Sprintf("%d", accountId),
+			}
+		}
+
+		// Id is assigned by flespi and AccountId (cid) is conveyed via header;
+		// the groups are copies, so clearing them is safe
+		batch := groups[accountId]
+		for i := range batch {
+			batch[i].Id = 0
+			batch[i].AccountId = 0
+		}
+
+		response := limitsResponse{}
+
+		if err := c.RequestAPIWithContextAndHeaders(ctx, "POST", "platform/limits", headers, batch, &response); err != nil {
+			return result, err
+		}
+
+		result.Append(response.Limits, response.Errors, func(limit Limit) int64 { return limit.Id })
+	}
 
-	err := c.RequestAPI("GET", "platform/limits/all", nil, &response)
+	return result, nil
+}
+
+func ListLimits(c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Limit, error) {
+	return ListLimitsWithContext(context.Background(), c, options...)
+}
+
+func ListLimitsWithContext(ctx context.Context, c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Limit, error) {
+	return ListLimitsBySelectorWithContext(ctx, c, flespiapi.SelectAll(), options...)
+}
 
+// ListLimitsBySelector returns the limits matched by selector.
+func ListLimitsBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Limit, error) {
+	return ListLimitsBySelectorWithContext(context.Background(), c, selector, options...)
+}
+
+func ListLimitsBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Limit, error) {
+	path, err := selector.Path()
 	if err != nil {
 		return nil, err
 	}
 
+	response := limitsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("platform/limits/%s", path), nil, options...), nil, &response); err != nil {
+		return nil, err
+	}
+
 	return response.Limits, nil
 }
 
-func GetLimit(c flespiapi.APIRequester, limitId int64) (*Limit, error) {
+// IterateLimits walks the limits matched by selector one at a time.
+// Close the iterator when done.
+func IterateLimits(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *LimitIterator {
+	return IterateLimitsWithContext(context.Background(), c, selector, options...)
+}
+
+func IterateLimitsWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *LimitIterator {
+	path, err := selector.Path()
+	if err != nil {
+		return flespiapi.FailedIterator[Limit](err)
+	}
+
+	return flespiapi.NewIterator[Limit](ctx, c, flespiapi.Endpoint(fmt.Sprintf("platform/limits/%s", path), nil, options...), nil)
+}
+
+func GetLimit(c flespiapi.APIRequester, limitId int64, options ...flespiapi.RequestOption) (*Limit, error) {
+	return GetLimitWithContext(context.Background(), c, limitId, options...)
+}
+
+func GetLimitWithContext(ctx context.Context, c flespiapi.APIRequester, limitId int64, options ...flespiapi.RequestOption) (*Limit, error) {
 	response := limitsResponse{}
 
-	err := c.RequestAPI("GET", fmt.Sprintf("platform/limits/%d?fields=id,name,description,blocking_duration,api_calls,api_traffic,channels_count,channel_messages,channel_storage,channel_traffic,channel_connections,containers_count,container_storage,cdns_count,cdn_storage,cdn_traffic,devices_count,device_storage,device_media_traffic,device_media_storage,streams_count,stream_storage,stream_traffic,modems_count,mqtt_sessions,mqtt_messages,mqtt_session_storage,mqtt_retained_storage,mqtt_subscriptions,sms_count,tokens_count,subaccounts_count,limits_count,realms_count,calcs_count,calcs_storage,plugins_count,plugin_traffic,plugin_buffered_messages,groups_count,webhooks_count,webhook_storage,webhook_traffic,grants_count,identity_providers_count,cid", limitId), nil, &response)
+	err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("platform/limits/%d", limitId), limitFields, options...), nil, &response)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.Limits[0], nil
+	return flespiapi.First(response.Limits, response.Errors)
 }
 
 func UpdateLimit(c flespiapi.APIRequester, limit Limit) (*Limit, error) {
+	return UpdateLimitWithContext(context.Background(), c, limit)
+}
+
+func UpdateLimitWithContext(ctx context.Context, c flespiapi.APIRequester, limit Limit) (*Limit, error) {
 	if limit.Id == 0 {
-		return nil, fmt.Errorf("ID must be provided")
+		return nil, flespiapi.Invalid("ID must be provided")
 	}
 
 	limitId := limit.Id
@@ -82,23 +171,31 @@ func UpdateLimit(c flespiapi.APIRequester, limit Limit) (*Limit, error) {
 
 	response := limitsResponse{}
 
-	if err := c.RequestAPIWithHeaders("PUT", fmt.Sprintf("platform/limits/%d", limitId), headers, limit, &response); err != nil {
+	if err := c.RequestAPIWithContextAndHeaders(ctx, "PUT", fmt.Sprintf("platform/limits/%d", limitId), headers, limit, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Limits[0], nil
+	return flespiapi.First(response.Limits, response.Errors)
 }
 
 func DeleteLimit(c flespiapi.APIRequester, limit Limit) error {
+	return DeleteLimitWithContext(context.Background(), c, limit)
+}
+
+func DeleteLimitWithContext(ctx context.Context, c flespiapi.APIRequester, limit Limit) error {
 	if limit.Id == 0 {
-		return fmt.Errorf("ID must be provided")
+		return flespiapi.Invalid("ID must be provided")
 	}
 
-	return DeleteLimitById(c, limit.Id)
+	return DeleteLimitByIdWithContext(ctx, c, limit.Id)
 }
 
 func DeleteLimitById(c flespiapi.APIRequester, limitId int64) error {
-	err := c.RequestAPI("DELETE", fmt.Sprintf("platform/limits/%d", limitId), nil, nil)
+	return DeleteLimitByIdWithContext(context.Background(), c, limitId)
+}
+
+func DeleteLimitByIdWithContext(ctx context.Context, c flespiapi.APIRequester, limitId int64) error {
+	err := c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("platform/limits/%d", limitId), nil, nil)
 
 	if err != nil {
 		return err
@@ -106,3 +203,46 @@ func DeleteLimitById(c flespiapi.APIRequester, limitId int64) error {
 
 	return nil
 }
+
+// UpdateLimitsBySelector updates the given fields of every limit matched by selector.
+func UpdateLimitsBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*LimitsResult, error) {
+	return UpdateLimitsBySelectorWithContext(context.Background(), c, selector, changes)
+}
+
+func UpdateLimitsBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*LimitsResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	response := limitsResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("platform/limits/%s", path), changes, &response); err != nil {
+		return nil, err
+	}
+
+	result := &LimitsResult{}
+	result.Append(response.Limits, response.Errors, func(limit Limit) int64 { return limit.Id })
+
+	return result, nil
+}
+
+// DeleteLimitsBySelector deletes every limit matched by selector.
+func DeleteLimitsBySelector(c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteLimitsBySelectorWithContext(context.Background(), c, selector)
+}
+
+func DeleteLimitsBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &flespiapi.DeleteResult{}
+
+	if err := c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("platform/limits/%s", path), nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
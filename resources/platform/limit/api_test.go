@@ -1,10 +1,12 @@
 package flespi_limit
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/mixser/flespi-client/internal/flespiapi"
 	"github.com/mixser/flespi-client/internal/testhelper"
 )
 
@@ -72,3 +74,23 @@ func TestListLimits(t *testing.T) {
 		t.Errorf("Expected 2 limits, got %d", len(limits))
 	}
 }
+
+func TestNewLimits(t *testing.T) {
+	testhelper.CheckBulkCreate(t, func(c flespiapi.APIRequester, ids []int64) (int, []int64, error) {
+		limits := make([]Limit, len(ids))
+		for i, id := range ids {
+			limits[i] = Limit{Id: id, Name: fmt.Sprintf("limits-%d", i)}
+		}
+
+		result, err := NewLimits(c, limits)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		inputIds := make([]int64, len(limits))
+		for i, item := range limits {
+			inputIds[i] = item.Id
+		}
+		return len(result.Items), inputIds, nil
+	})
+}
@@ -1,9 +1,14 @@
 package flespi_limit
 
-import "github.com/mixser/flespi-client/internal/flespiapi"
+import (
+	"context"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
 
 // LimitClient provides receiver-based methods for managing Flespi limits.
 // Access it via Client.Limits after creating a flespi.Client.
+// Every method has a ...WithContext variant that takes a context.Context first.
 type LimitClient struct {
 	c flespiapi.APIRequester
 }
@@ -17,22 +22,86 @@ func (lc *LimitClient) Create(name string, options ...CreateLimitOption) (*Limit
 	return NewLimit(lc.c, name, options...)
 }
 
-func (lc *LimitClient) List() ([]Limit, error) {
-	return ListLimits(lc.c)
+func (lc *LimitClient) CreateWithContext(ctx context.Context, name string, options ...CreateLimitOption) (*Limit, error) {
+	return NewLimitWithContext(ctx, lc.c, name, options...)
+}
+
+func (lc *LimitClient) CreateMany(limits []Limit) (*LimitsResult, error) {
+	return NewLimits(lc.c, limits)
+}
+
+func (lc *LimitClient) CreateManyWithContext(ctx context.Context, limits []Limit) (*LimitsResult, error) {
+	return NewLimitsWithContext(ctx, lc.c, limits)
+}
+
+func (lc *LimitClient) List(options ...flespiapi.RequestOption) ([]Limit, error) {
+	return ListLimits(lc.c, options...)
+}
+
+func (lc *LimitClient) ListWithContext(ctx context.Context, options ...flespiapi.RequestOption) ([]Limit, error) {
+	return ListLimitsWithContext(ctx, lc.c, options...)
+}
+
+func (lc *LimitClient) ListBySelector(selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Limit, error) {
+	return ListLimitsBySelector(lc.c, selector, options...)
+}
+
+func (lc *LimitClient) ListBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Limit, error) {
+	return ListLimitsBySelectorWithContext(ctx, lc.c, selector, options...)
+}
+
+func (lc *LimitClient) Iterate(selector flespiapi.Selector, options ...flespiapi.RequestOption) *LimitIterator {
+	return IterateLimits(lc.c, selector, options...)
+}
+
+func (lc *LimitClient) IterateWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) *LimitIterator {
+	return IterateLimitsWithContext(ctx, lc.c, selector, options...)
 }
 
-func (lc *LimitClient) Get(limitId int64) (*Limit, error) {
-	return GetLimit(lc.c, limitId)
+func (lc *LimitClient) Get(limitId int64, options ...flespiapi.RequestOption) (*Limit, error) {
+	return GetLimit(lc.c, limitId, options...)
+}
+
+func (lc *LimitClient) GetWithContext(ctx context.Context, limitId int64, options ...flespiapi.RequestOption) (*Limit, error) {
+	return GetLimitWithContext(ctx, lc.c, limitId, options...)
 }
 
 func (lc *LimitClient) Update(limit Limit) (*Limit, error) {
 	return UpdateLimit(lc.c, limit)
 }
 
+func (lc *LimitClient) UpdateWithContext(ctx context.Context, limit Limit) (*Limit, error) {
+	return UpdateLimitWithContext(ctx, lc.c, limit)
+}
+
 func (lc *LimitClient) Delete(limit Limit) error {
 	return DeleteLimit(lc.c, limit)
 }
 
+func (lc *LimitClient) DeleteWithContext(ctx context.Context, limit Limit) error {
+	return DeleteLimitWithContext(ctx, lc.c, limit)
+}
+
 func (lc *LimitClient) DeleteById(limitId int64) error {
 	return DeleteLimitById(lc.c, limitId)
 }
+
+func (lc *LimitClient) DeleteByIdWithContext(ctx context.Context, limitId int64) error {
+	return DeleteLimitByIdWithContext(ctx, lc.c, limitId)
+}
+
+func (lc *LimitClient) UpdateBySelector(selector flespiapi.Selector, changes map[string]interface{}) (*LimitsResult, error) {
+	return UpdateLimitsBySelector(lc.c, selector, changes)
+}
+
+func (lc *LimitClient) UpdateBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, changes map[string]interface{}) (*LimitsResult, error) {
+	return UpdateLimitsBySelectorWithContext(ctx, lc.c, selector, changes)
+}
+
+func (lc *LimitClient) DeleteBySelector(selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteLimitsBySelector(lc.c, selector)
+}
+
+func (lc *LimitClient) DeleteBySelectorWithContext(ctx context.Context, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteLimitsBySelectorWithContext(ctx, lc.c, selector)
+}
@@ -1,5 +1,7 @@
 package flespi_limit
 
+import "github.com/mixser/flespi-client/internal/flespiapi"
+
 type Limit struct {
 	Id               int64  `json:"id,omitempty"`
 	Name             string `json:"name"`
@@ -223,5 +225,12 @@ func WithAccountId(accountId int64) CreateLimitOption {
 }
 
 type limitsResponse struct {
-	Limits []Limit `json:"result"`
+	Limits []Limit                 `json:"result"`
+	Errors []flespiapi.ErrorDetail `json:"errors"`
 }
+
+// LimitsResult holds the limits returned by a bulk request and the per-item errors reported by flespi.
+type LimitsResult = flespiapi.MultiResult[Limit]
+
+// LimitIterator decodes limits from a list response one at a time, see IterateLimits.
+type LimitIterator = flespiapi.Iterator[Limit]
@@ -1,12 +1,20 @@
 package flespi_subaccount
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
+// subaccountFields are requested by GetSubaccount unless a flespiapi.WithFields option overrides them.
+var subaccountFields = []string{"id", "name", "limit_id", "metadata", "cid"}
+
 func NewSubaccount(client flespiapi.APIRequester, name string, options ...CreateSubaccountOption) (*Subaccount, error) {
+	return NewSubaccountWithContext(context.Background(), client, name, options...)
+}
+
+func NewSubaccountWithContext(ctx context.Context, client flespiapi.APIRequester, name string, options ...CreateSubaccountOption) (*Subaccount, error) {
 	subaccount := Subaccount{Name: name}
 
 	for _, opt := range options {
@@ -26,41 +34,123 @@ func NewSubaccount(client flespiapi.APIRequester, name string, options ...Create
 
 	response := subaccountsResponse{}
 
-	if err := client.RequestAPIWithHeaders("POST", "platform/subaccounts", headers, []Subaccount{subaccount}, &response); err != nil {
+	if err := client.RequestAPIWithContextAndHeaders(ctx, "POST", "platform/subaccounts", headers, []Subaccount{subaccount}, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Subaccounts[0], nil
+	return flespiapi.First(response.Subaccounts, response.Errors)
 
 }
 
-func ListSubaccounts(client flespiapi.APIRequester) ([]Subaccount, error) {
-	response := subaccountsResponse{}
+// NewSubaccounts creates subaccounts in bulk, one request per parent account.
+func NewSubaccounts(client flespiapi.APIRequester, subaccounts []Subaccount) (*SubaccountsResult, error) {
+	return NewSubaccountsWithContext(context.Background(), client, subaccounts)
+}
+
+func NewSubaccountsWithContext(ctx context.Context, client flespiapi.APIRequester, subaccounts []Subaccount) (*SubaccountsResult, error) {
+	result := &SubaccountsResult{}
+
+	if len(subaccounts) == 0 {
+		return result, nil
+	}
+
+	accountIds, groups := flespiapi.GroupByAccount(subaccounts, func(subaccount Subaccount) int64 { return subaccount.AccountId })
+
+	for _, accountId := range accountIds {
+		var headers map[string]string
+		if accountId != 0 {
+			headers = map[string]string{
+				"x-flespi-cid": fmt.Sprintf("%d", accountId),
+			}
+		}
+
+		// Id is assigned by flespi and AccountId (cid) is conveyed via header;
+		// the groups are copies, so clearing them is safe
+		batch := groups[accountId]
+		for i := range batch {
+			batch[i].Id = 0
+			batch[i].AccountId = 0
+		}
+
+		response := subaccountsResponse{}
+
+		if err := client.RequestAPIWithContextAndHeaders(ctx, "POST", "platform/subaccounts", headers, batch, &response); err != nil {
+			return result, err
+		}
+
+		result.Append(response.Subaccounts, response.Errors, func(subaccount Subaccount) int64 { return subaccount.Id })
+	}
 
-	err := client.RequestAPI("GET", "platform/subaccounts/all", nil, &response)
+	return result, nil
+}
+
+func ListSubaccounts(client flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Subaccount, error) {
+	return ListSubaccountsWithContext(context.Background(), client, options...)
+}
+
+func ListSubaccountsWithContext(ctx context.Context, client flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Subaccount, error) {
+	return ListSubaccountsBySelectorWithContext(ctx, client, flespiapi.SelectAll(), options...)
+}
 
+// ListSubaccountsBySelector returns the subaccounts matched by selector,
+// e.g. {metadata.customer="acme"}.
+func ListSubaccountsBySelector(client flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Subaccount, error) {
+	return ListSubaccountsBySelectorWithContext(context.Background(), client, selector, options...)
+}
+
+func ListSubaccountsBySelectorWithContext(ctx context.Context, client flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Subaccount, error) {
+	path, err := selector.Path()
 	if err != nil {
 		return nil, err
 	}
 
+	response := subaccountsResponse{}
+
+	if err := client.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("platform/subaccounts/%s", path), nil, options...), nil, &response); err != nil {
+		return nil, err
+	}
+
 	return response.Subaccounts, nil
 }
 
-func GetSubaccount(client flespiapi.APIRequester, subaccountId int64) (*Subaccount, error) {
+// IterateSubaccounts streams the subaccounts matched by selector, useful for resellers with large customer bases.
+// Close the iterator when done.
+func IterateSubaccounts(client flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *SubaccountIterator {
+	return IterateSubaccountsWithContext(context.Background(), client, selector, options...)
+}
+
+func IterateSubaccountsWithContext(ctx context.Context, client flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *SubaccountIterator {
+	path, err := selector.Path()
+	if err != nil {
+		return flespiapi.FailedIterator[Subaccount](err)
+	}
+
+	return flespiapi.NewIterator[Subaccount](ctx, client, flespiapi.Endpoint(fmt.Sprintf("platform/subaccounts/%s", path), nil, options...), nil)
+}
+
+func GetSubaccount(client flespiapi.APIRequester, subaccountId int64, options ...flespiapi.RequestOption) (*Subaccount, error) {
+	return GetSubaccountWithContext(context.Background(), client, subaccountId, options...)
+}
+
+func GetSubaccountWithContext(ctx context.Context, client flespiapi.APIRequester, subaccountId int64, options ...flespiapi.RequestOption) (*Subaccount, error) {
 	response := subaccountsResponse{}
 
-	err := client.RequestAPI("GET", fmt.Sprintf("platform/subaccounts/%d?fields=id,name,limit_id,metadata,cid", subaccountId), nil, &response)
+	err := client.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("platform/subaccounts/%d", subaccountId), subaccountFields, options...), nil, &response)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.Subaccounts[0], nil
+	return flespiapi.First(response.Subaccounts, response.Errors)
 }
 
 func UpdateSubaccount(client flespiapi.APIRequester, subaccount Subaccount) (*Subaccount, error) {
+	return UpdateSubaccountWithContext(context.Background(), client, subaccount)
+}
+
+func UpdateSubaccountWithContext(ctx context.Context, client flespiapi.APIRequester, subaccount Subaccount) (*Subaccount, error) {
 	if subaccount.Id == 0 {
-		return nil, fmt.Errorf("id should be defined before update")
+		return nil, flespiapi.Invalid("id should be defined before update")
 	}
 
 	subaccountId := subaccount.Id
@@ -83,23 +173,31 @@ func UpdateSubaccount(client flespiapi.APIRequester, subaccount Subaccount) (*Su
 
 	response := subaccountsResponse{}
 
-	if err := client.RequestAPIWithHeaders("PUT", fmt.Sprintf("platform/subaccounts/%d", subaccountId), headers, subaccount, &response); err != nil {
+	if err := client.RequestAPIWithContextAndHeaders(ctx, "PUT", fmt.Sprintf("platform/subaccounts/%d", subaccountId), headers, subaccount, &response); err != nil {
 		return nil, err
 	}
 
-	return &response.Subaccounts[0], nil
+	return flespiapi.First(response.Subaccounts, response.Errors)
 }
 
 func DeleteSubaccount(client flespiapi.APIRequester, subaccount Subaccount) error {
+	return DeleteSubaccountWithContext(context.Background(), client, subaccount)
+}
+
+func DeleteSubaccountWithContext(ctx context.Context, client flespiapi.APIRequester, subaccount Subaccount) error {
 	if subaccount.Id == 0 {
-		return fmt.Errorf("id should be defined before delete")
+		return flespiapi.Invalid("id should be defined before delete")
 	}
 
-	return DeleteSubaccountById(client, subaccount.Id)
+	return DeleteSubaccountByIdWithContext(ctx, client, subaccount.Id)
 }
 
 func DeleteSubaccountById(client flespiapi.APIRequester, subaccountId int64) error {
-	err := client.RequestAPI("DELETE", fmt.Sprintf("platform/subaccounts/%d", subaccountId), nil, nil)
+	return DeleteSubaccountByIdWithContext(context.Background(), client, subaccountId)
+}
+
+func DeleteSubaccountByIdWithContext(ctx context.Context, client flespiapi.APIRequester, subaccountId int64) error {
+	err := client.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("platform/subaccounts/%d", subaccountId), nil, nil)
 
 	if err != nil {
 		return err
@@ -107,3 +205,46 @@ func DeleteSubaccountById(client flespiapi.APIRequester, subaccountId int64) err
 
 	return nil
 }
+
+// UpdateSubaccountsBySelector changes the given fields of all matched subaccounts, e.g. {"limit_id": 7}.
+func UpdateSubaccountsBySelector(client flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*SubaccountsResult, error) {
+	return UpdateSubaccountsBySelectorWithContext(context.Background(), client, selector, changes)
+}
+
+func UpdateSubaccountsBySelectorWithContext(ctx context.Context, client flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*SubaccountsResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	response := subaccountsResponse{}
+
+	if err := client.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("platform/subaccounts/%s", path), changes, &response); err != nil {
+		return nil, err
+	}
+
+	result := &SubaccountsResult{}
+	result.Append(response.Subaccounts, response.Errors, func(subaccount Subaccount) int64 { return subaccount.Id })
+
+	return result, nil
+}
+
+// DeleteSubaccountsBySelector deletes every subaccount matched by selector.
+func DeleteSubaccountsBySelector(client flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteSubaccountsBySelectorWithContext(context.Background(), client, selector)
+}
+
+func DeleteSubaccountsBySelectorWithContext(ctx context.Context, client flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &flespiapi.DeleteResult{}
+
+	if err := client.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("platform/subaccounts/%s", path), nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
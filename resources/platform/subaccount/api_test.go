@@ -1,10 +1,12 @@
 package flespi_subaccount
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/mixser/flespi-client/internal/flespiapi"
 	"github.com/mixser/flespi-client/internal/testhelper"
 )
 
@@ -123,3 +125,23 @@ func TestDeleteSubaccount(t *testing.T) {
 		t.Errorf("DeleteSubaccountById() error = %v", err)
 	}
 }
+
+func TestNewSubaccounts(t *testing.T) {
+	testhelper.CheckBulkCreate(t, func(c flespiapi.APIRequester, ids []int64) (int, []int64, error) {
+		subaccounts := make([]Subaccount, len(ids))
+		for i, id := range ids {
+			subaccounts[i] = Subaccount{Id: id, Name: fmt.Sprintf("subaccounts-%d", i)}
+		}
+
+		result, err := NewSubaccounts(c, subaccounts)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		inputIds := make([]int64, len(subaccounts))
+		for i, item := range subaccounts {
+			inputIds[i] = item.Id
+		}
+		return len(result.Items), inputIds, nil
+	})
+}
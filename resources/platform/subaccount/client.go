@@ -1,9 +1,14 @@
 package flespi_subaccount
 
-import "github.com/mixser/flespi-client/internal/flespiapi"
+import (
+	"context"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
 
 // SubaccountClient provides receiver-based methods for managing Flespi subaccounts.
 // Access it via Client.Subaccounts after creating a flespi.Client.
+// Every method has a ...WithContext variant that takes a context.Context first.
 type SubaccountClient struct {
 	c flespiapi.APIRequester
 }
@@ -17,22 +22,86 @@ func (sc *SubaccountClient) Create(name string, options ...CreateSubaccountOptio
 	return NewSubaccount(sc.c, name, options...)
 }
 
-func (sc *SubaccountClient) List() ([]Subaccount, error) {
-	return ListSubaccounts(sc.c)
+func (sc *SubaccountClient) CreateWithContext(ctx context.Context, name string, options ...CreateSubaccountOption) (*Subaccount, error) {
+	return NewSubaccountWithContext(ctx, sc.c, name, options...)
+}
+
+func (sc *SubaccountClient) CreateMany(subaccounts []Subaccount) (*SubaccountsResult, error) {
+	return NewSubaccounts(sc.c, subaccounts)
+}
+
+func (sc *SubaccountClient) CreateManyWithContext(ctx context.Context, subaccounts []Subaccount) (*SubaccountsResult, error) {
+	return NewSubaccountsWithContext(ctx, sc.c, subaccounts)
+}
+
+func (sc *SubaccountClient) List(options ...flespiapi.RequestOption) ([]Subaccount, error) {
+	return ListSubaccounts(sc.c, options...)
+}
+
+func (sc *SubaccountClient) ListWithContext(ctx context.Context, options ...flespiapi.RequestOption) ([]Subaccount, error) {
+	return ListSubaccountsWithContext(ctx, sc.c, options...)
+}
+
+func (sc *SubaccountClient) ListBySelector(selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Subaccount, error) {
+	return ListSubaccountsBySelector(sc.c, selector, options...)
+}
+
+func (sc *SubaccountClient) ListBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Subaccount, error) {
+	return ListSubaccountsBySelectorWithContext(ctx, sc.c, selector, options...)
+}
+
+func (sc *SubaccountClient) Iterate(selector flespiapi.Selector, options ...flespiapi.RequestOption) *SubaccountIterator {
+	return IterateSubaccounts(sc.c, selector, options...)
+}
+
+func (sc *SubaccountClient) IterateWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) *SubaccountIterator {
+	return IterateSubaccountsWithContext(ctx, sc.c, selector, options...)
 }
 
-func (sc *SubaccountClient) Get(subaccountId int64) (*Subaccount, error) {
-	return GetSubaccount(sc.c, subaccountId)
+func (sc *SubaccountClient) Get(subaccountId int64, options ...flespiapi.RequestOption) (*Subaccount, error) {
+	return GetSubaccount(sc.c, subaccountId, options...)
+}
+
+func (sc *SubaccountClient) GetWithContext(ctx context.Context, subaccountId int64, options ...flespiapi.RequestOption) (*Subaccount, error) {
+	return GetSubaccountWithContext(ctx, sc.c, subaccountId, options...)
 }
 
 func (sc *SubaccountClient) Update(subaccount Subaccount) (*Subaccount, error) {
 	return UpdateSubaccount(sc.c, subaccount)
 }
 
+func (sc *SubaccountClient) UpdateWithContext(ctx context.Context, subaccount Subaccount) (*Subaccount, error) {
+	return UpdateSubaccountWithContext(ctx, sc.c, subaccount)
+}
+
 func (sc *SubaccountClient) Delete(subaccount Subaccount) error {
 	return DeleteSubaccount(sc.c, subaccount)
 }
 
+func (sc *SubaccountClient) DeleteWithContext(ctx context.Context, subaccount Subaccount) error {
+	return DeleteSubaccountWithContext(ctx, sc.c, subaccount)
+}
+
 func (sc *SubaccountClient) DeleteById(subaccountId int64) error {
 	return DeleteSubaccountById(sc.c, subaccountId)
 }
+
+func (sc *SubaccountClient) DeleteByIdWithContext(ctx context.Context, subaccountId int64) error {
+	return DeleteSubaccountByIdWithContext(ctx, sc.c, subaccountId)
+}
+
+func (sc *SubaccountClient) UpdateBySelector(selector flespiapi.Selector, changes map[string]interface{}) (*SubaccountsResult, error) {
+	return UpdateSubaccountsBySelector(sc.c, selector, changes)
+}
+
+func (sc *SubaccountClient) UpdateBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, changes map[string]interface{}) (*SubaccountsResult, error) {
+	return UpdateSubaccountsBySelectorWithContext(ctx, sc.c, selector, changes)
+}
+
+func (sc *SubaccountClient) DeleteBySelector(selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteSubaccountsBySelector(sc.c, selector)
+}
+
+func (sc *SubaccountClient) DeleteBySelectorWithContext(ctx context.Context, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteSubaccountsBySelectorWithContext(ctx, sc.c, selector)
+}
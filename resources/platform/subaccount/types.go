@@ -1,5 +1,7 @@
 package flespi_subaccount
 
+import "github.com/mixser/flespi-client/internal/flespiapi"
+
 type Subaccount struct {
 	Id      int64  `json:"id,omitempty"`
 	Name    string `json:"name"`
@@ -27,5 +29,12 @@ func WithAccountId(accountId int64) CreateSubaccountOption {
 }
 
 type subaccountsResponse struct {
-	Subaccounts []Subaccount `json:"result"`
+	Subaccounts []Subaccount            `json:"result"`
+	Errors      []flespiapi.ErrorDetail `json:"errors"`
 }
+
+// SubaccountsResult holds the subaccounts returned by a bulk request and the per-item errors reported by flespi.
+type SubaccountsResult = flespiapi.MultiResult[Subaccount]
+
+// SubaccountIterator decodes subaccounts from a list response one at a time, see IterateSubaccounts.
+type SubaccountIterator = flespiapi.Iterator[Subaccount]
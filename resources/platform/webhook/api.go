@@ -1,18 +1,23 @@
 package flespi_webhook
 
 import (
+	"context"
 	"fmt"
 	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
 func NewSingleWebhook(c flespiapi.APIRequester, name string, options ...CreateSingleWebhookOption) (*SingleWebhook, error) {
+	return NewSingleWebhookWithContext(context.Background(), c, name, options...)
+}
+
+func NewSingleWebhookWithContext(ctx context.Context, c flespiapi.APIRequester, name string, options ...CreateSingleWebhookOption) (*SingleWebhook, error) {
 	webhook := SingleWebhook{Name: name}
 
 	for _, opt := range options {
 		opt(&webhook)
 	}
 
-	result, err := newWebhook(c, &webhook)
+	result, err := newWebhook(ctx, c, &webhook)
 
 	if err != nil {
 		return nil, err
@@ -22,13 +27,17 @@ func NewSingleWebhook(c flespiapi.APIRequester, name string, options ...CreateSi
 }
 
 func NewChainedWebhook(c flespiapi.APIRequester, name string, options ...CreateChainedWebhookOption) (*ChainedWebhook, error) {
+	return NewChainedWebhookWithContext(context.Background(), c, name, options...)
+}
+
+func NewChainedWebhookWithContext(ctx context.Context, c flespiapi.APIRequester, name string, options ...CreateChainedWebhookOption) (*ChainedWebhook, error) {
 	webhook := ChainedWebhook{Name: name}
 
 	for _, opt := range options {
 		opt(&webhook)
 	}
 
-	result, err := newWebhook(c, &webhook)
+	result, err := newWebhook(ctx, c, &webhook)
 
 	if err != nil {
 		return nil, err
@@ -37,10 +46,10 @@ func NewChainedWebhook(c flespiapi.APIRequester, name string, options ...CreateC
 	return result.(*ChainedWebhook), nil
 }
 
-func newWebhook(c flespiapi.APIRequester, webhook Webhook) (Webhook, error) {
+func newWebhook(ctx context.Context, c flespiapi.APIRequester, webhook Webhook) (Webhook, error) {
 	response := webhookResponse{}
 
-	err := c.RequestAPI("POST", "platform/webhooks", []Webhook{webhook}, &response)
+	err := c.RequestAPIWithContext(ctx, "POST", "platform/webhooks", []Webhook{webhook}, &response)
 
 	if err != nil {
 		return nil, err
@@ -52,13 +61,67 @@ func newWebhook(c flespiapi.APIRequester, webhook Webhook) (Webhook, error) {
 		return nil, err
 	}
 
+	if len(webhooks) == 0 {
+		return nil, flespiapi.EmptyResultError(response.Errors)
+	}
+
 	return webhooks[0], nil
 }
 
-func GetWebhook(c flespiapi.APIRequester, webhookId int64) (Webhook, error) {
+// NewWebhooks creates single and chained webhooks with one request.
+func NewWebhooks(c flespiapi.APIRequester, webhooks []Webhook) (*WebhooksResult, error) {
+	return NewWebhooksWithContext(context.Background(), c, webhooks)
+}
+
+func NewWebhooksWithContext(ctx context.Context, c flespiapi.APIRequester, webhooks []Webhook) (*WebhooksResult, error) {
+	result := &WebhooksResult{}
+
+	if len(webhooks) == 0 {
+		return result, nil
+	}
+
+	// Id is assigned by flespi; clear it on copies so the caller's webhooks are left alone
+	batch := make([]Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		switch w := webhook.(type) {
+		case *SingleWebhook:
+			single := *w
+			single.Id = 0
+			batch = append(batch, &single)
+		case *ChainedWebhook:
+			chained := *w
+			chained.Id = 0
+			batch = append(batch, &chained)
+		default:
+			batch = append(batch, webhook)
+		}
+	}
+
 	response := webhookResponse{}
 
-	err := c.RequestAPI("GET", fmt.Sprintf("platform/webhooks/%d", webhookId), nil, &response)
+	if err := c.RequestAPIWithContext(ctx, "POST", "platform/webhooks", batch, &response); err != nil {
+		return result, err
+	}
+
+	items, err := unmarshalWebhookResponse(response)
+
+	if err != nil {
+		return result, err
+	}
+
+	result.Append(items, response.Errors, Webhook.GetId)
+
+	return result, nil
+}
+
+func GetWebhook(c flespiapi.APIRequester, webhookId int64, options ...flespiapi.RequestOption) (Webhook, error) {
+	return GetWebhookWithContext(context.Background(), c, webhookId, options...)
+}
+
+func GetWebhookWithContext(ctx context.Context, c flespiapi.APIRequester, webhookId int64, options ...flespiapi.RequestOption) (Webhook, error) {
+	response := webhookResponse{}
+
+	err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("platform/webhooks/%d", webhookId), nil, options...), nil, &response)
 
 	if err != nil {
 		return nil, err
@@ -70,18 +133,38 @@ func GetWebhook(c flespiapi.APIRequester, webhookId int64) (Webhook, error) {
 		return nil, err
 	}
 
+	if len(webhooks) == 0 {
+		return nil, flespiapi.EmptyResultError(response.Errors)
+	}
+
 	return webhooks[0], nil
 }
 
-func ListWebhooks(c flespiapi.APIRequester) ([]Webhook, error) {
-	response := webhookResponse{}
+func ListWebhooks(c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Webhook, error) {
+	return ListWebhooksWithContext(context.Background(), c, options...)
+}
 
-	err := c.RequestAPI("GET", "platform/webhooks/all", nil, &response)
+func ListWebhooksWithContext(ctx context.Context, c flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]Webhook, error) {
+	return ListWebhooksBySelectorWithContext(ctx, c, flespiapi.SelectAll(), options...)
+}
+
+// ListWebhooksBySelector returns the webhooks matched by selector.
+func ListWebhooksBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Webhook, error) {
+	return ListWebhooksBySelectorWithContext(context.Background(), c, selector, options...)
+}
 
+func ListWebhooksBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Webhook, error) {
+	path, err := selector.Path()
 	if err != nil {
 		return nil, err
 	}
 
+	response := webhookResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("platform/webhooks/%s", path), nil, options...), nil, &response); err != nil {
+		return nil, err
+	}
+
 	webhooks, err := unmarshalWebhookResponse(response)
 
 	if err != nil {
@@ -91,10 +174,29 @@ func ListWebhooks(c flespiapi.APIRequester) ([]Webhook, error) {
 	return webhooks, nil
 }
 
+// IterateWebhooks walks the webhooks matched by selector; each item is a *SingleWebhook or a *ChainedWebhook.
+// Close the iterator when done.
+func IterateWebhooks(c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *WebhookIterator {
+	return IterateWebhooksWithContext(context.Background(), c, selector, options...)
+}
+
+func IterateWebhooksWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *WebhookIterator {
+	path, err := selector.Path()
+	if err != nil {
+		return flespiapi.FailedIterator[Webhook](err)
+	}
+
+	return flespiapi.NewIterator[Webhook](ctx, c, flespiapi.Endpoint(fmt.Sprintf("platform/webhooks/%s", path), nil, options...), unmarshalWebhook)
+}
+
 func UpdateWebhook(c flespiapi.APIRequester, webhook Webhook) (Webhook, error) {
+	return UpdateWebhookWithContext(context.Background(), c, webhook)
+}
+
+func UpdateWebhookWithContext(ctx context.Context, c flespiapi.APIRequester, webhook Webhook) (Webhook, error) {
 	response := webhookResponse{}
 
-	err := c.RequestAPI("PUT", fmt.Sprintf("platform/webhooks/%d", webhook.GetId()), webhook, &response)
+	err := c.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("platform/webhooks/%d", webhook.GetId()), webhook, &response)
 
 	if err != nil {
 		return nil, err
@@ -106,18 +208,79 @@ func UpdateWebhook(c flespiapi.APIRequester, webhook Webhook) (Webhook, error) {
 		return nil, err
 	}
 
+	if len(webhooks) == 0 {
+		return nil, flespiapi.EmptyResultError(response.Errors)
+	}
+
 	return webhooks[0], nil
 }
 
 func DeleteWebhook(c flespiapi.APIRequester, webhook Webhook) error {
-	return DeleteWebhookById(c, webhook.GetId())
+	return DeleteWebhookWithContext(context.Background(), c, webhook)
+}
+
+func DeleteWebhookWithContext(ctx context.Context, c flespiapi.APIRequester, webhook Webhook) error {
+	return DeleteWebhookByIdWithContext(ctx, c, webhook.GetId())
 }
 
 func DeleteWebhookById(c flespiapi.APIRequester, webhookId int64) error {
-	err := c.RequestAPI("DELETE", fmt.Sprintf("platform/webhooks/%d", webhookId), nil, nil)
+	return DeleteWebhookByIdWithContext(context.Background(), c, webhookId)
+}
+
+func DeleteWebhookByIdWithContext(ctx context.Context, c flespiapi.APIRequester, webhookId int64) error {
+	err := c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("platform/webhooks/%d", webhookId), nil, nil)
 
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// UpdateWebhooksBySelector changes the given fields of all matched webhooks.
+func UpdateWebhooksBySelector(c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*WebhooksResult, error) {
+	return UpdateWebhooksBySelectorWithContext(context.Background(), c, selector, changes)
+}
+
+func UpdateWebhooksBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*WebhooksResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	response := webhookResponse{}
+
+	if err := c.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("platform/webhooks/%s", path), changes, &response); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := unmarshalWebhookResponse(response)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WebhooksResult{}
+	result.Append(webhooks, response.Errors, Webhook.GetId)
+
+	return result, nil
+}
+
+// DeleteWebhooksBySelector deletes every webhook matched by selector.
+func DeleteWebhooksBySelector(c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteWebhooksBySelectorWithContext(context.Background(), c, selector)
+}
+
+func DeleteWebhooksBySelectorWithContext(ctx context.Context, c flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &flespiapi.DeleteResult{}
+
+	if err := c.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("platform/webhooks/%s", path), nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
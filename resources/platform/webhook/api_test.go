@@ -1,10 +1,12 @@
 package flespi_webhook
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/mixser/flespi-client/internal/flespiapi"
 	"github.com/mixser/flespi-client/internal/testhelper"
 )
 
@@ -264,3 +266,87 @@ func TestUpdateWebhook(t *testing.T) {
 		t.Errorf("Expected ID 123, got %d", updated.GetId())
 	}
 }
+
+func TestListWebhooks_WithFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fields := r.URL.Query().Get("fields"); fields != "id,name" {
+			t.Errorf("Expected fields=id,name, got %q", fields)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": [{"id": 1, "name": "first"}, {"id": 2, "name": "second"}]}`))
+	}))
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	webhooks, err := ListWebhooks(client, flespiapi.WithFields("id", "name"))
+	if err != nil {
+		t.Fatalf("ListWebhooks() error = %v", err)
+	}
+
+	if len(webhooks) != 2 || webhooks[1].GetId() != 2 {
+		t.Errorf("Unexpected webhooks %+v", webhooks)
+	}
+}
+
+func TestIterateWebhooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": [
+			{"id": 1, "name": "single", "triggers": [], "configuration": {"type": "custom-server", "uri": "https://example.com", "method": "POST", "body": "", "headers": []}},
+			{"id": 2, "name": "chained", "triggers": [], "configuration": [{"type": "custom-server", "uri": "https://example.com", "method": "POST", "body": "", "headers": []}]}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := testhelper.New(server.URL)
+
+	it := IterateWebhooks(client, flespiapi.SelectAll())
+	defer it.Close()
+
+	var webhooks []Webhook
+	for it.Next() {
+		webhooks = append(webhooks, it.Value())
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatalf("IterateWebhooks() error = %v", err)
+	}
+
+	if len(webhooks) != 2 {
+		t.Fatalf("Expected 2 webhooks, got %d", len(webhooks))
+	}
+
+	if _, ok := webhooks[0].(*SingleWebhook); !ok {
+		t.Errorf("Expected *SingleWebhook, got %T", webhooks[0])
+	}
+
+	if _, ok := webhooks[1].(*ChainedWebhook); !ok {
+		t.Errorf("Expected *ChainedWebhook, got %T", webhooks[1])
+	}
+}
+
+func TestNewWebhooks(t *testing.T) {
+	testhelper.CheckBulkCreate(t, func(c flespiapi.APIRequester, ids []int64) (int, []int64, error) {
+		webhooks := make([]Webhook, len(ids))
+		for i, id := range ids {
+			if i%2 == 0 {
+				webhooks[i] = &SingleWebhook{Id: id, Name: fmt.Sprintf("single-%d", i)}
+			} else {
+				webhooks[i] = &ChainedWebhook{Id: id, Name: fmt.Sprintf("chained-%d", i)}
+			}
+		}
+
+		result, err := NewWebhooks(c, webhooks)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		inputIds := make([]int64, len(webhooks))
+		for i, webhook := range webhooks {
+			inputIds[i] = webhook.GetId()
+		}
+		return len(result.Items), inputIds, nil
+	})
+}
@@ -1,9 +1,14 @@
 package flespi_webhook
 
-import "github.com/mixser/flespi-client/internal/flespiapi"
+import (
+	"context"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
 
 // WebhookClient provides receiver-based methods for managing Flespi webhooks.
 // Access it via Client.Webhooks after creating a flespi.Client.
+// Every method has a ...WithContext variant that takes a context.Context first.
 type WebhookClient struct {
 	c flespiapi.APIRequester
 }
@@ -17,26 +22,94 @@ func (wc *WebhookClient) NewSingle(name string, options ...CreateSingleWebhookOp
 	return NewSingleWebhook(wc.c, name, options...)
 }
 
+func (wc *WebhookClient) NewSingleWithContext(ctx context.Context, name string, options ...CreateSingleWebhookOption) (*SingleWebhook, error) {
+	return NewSingleWebhookWithContext(ctx, wc.c, name, options...)
+}
+
 func (wc *WebhookClient) NewChained(name string, options ...CreateChainedWebhookOption) (*ChainedWebhook, error) {
 	return NewChainedWebhook(wc.c, name, options...)
 }
 
-func (wc *WebhookClient) List() ([]Webhook, error) {
-	return ListWebhooks(wc.c)
+func (wc *WebhookClient) NewChainedWithContext(ctx context.Context, name string, options ...CreateChainedWebhookOption) (*ChainedWebhook, error) {
+	return NewChainedWebhookWithContext(ctx, wc.c, name, options...)
+}
+
+func (wc *WebhookClient) CreateMany(webhooks []Webhook) (*WebhooksResult, error) {
+	return NewWebhooks(wc.c, webhooks)
+}
+
+func (wc *WebhookClient) CreateManyWithContext(ctx context.Context, webhooks []Webhook) (*WebhooksResult, error) {
+	return NewWebhooksWithContext(ctx, wc.c, webhooks)
+}
+
+func (wc *WebhookClient) List(options ...flespiapi.RequestOption) ([]Webhook, error) {
+	return ListWebhooks(wc.c, options...)
 }
 
-func (wc *WebhookClient) Get(webhookId int64) (Webhook, error) {
-	return GetWebhook(wc.c, webhookId)
+func (wc *WebhookClient) ListWithContext(ctx context.Context, options ...flespiapi.RequestOption) ([]Webhook, error) {
+	return ListWebhooksWithContext(ctx, wc.c, options...)
+}
+
+func (wc *WebhookClient) ListBySelector(selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Webhook, error) {
+	return ListWebhooksBySelector(wc.c, selector, options...)
+}
+
+func (wc *WebhookClient) ListBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]Webhook, error) {
+	return ListWebhooksBySelectorWithContext(ctx, wc.c, selector, options...)
+}
+
+func (wc *WebhookClient) Iterate(selector flespiapi.Selector, options ...flespiapi.RequestOption) *WebhookIterator {
+	return IterateWebhooks(wc.c, selector, options...)
+}
+
+func (wc *WebhookClient) IterateWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) *WebhookIterator {
+	return IterateWebhooksWithContext(ctx, wc.c, selector, options...)
+}
+
+func (wc *WebhookClient) Get(webhookId int64, options ...flespiapi.RequestOption) (Webhook, error) {
+	return GetWebhook(wc.c, webhookId, options...)
+}
+
+func (wc *WebhookClient) GetWithContext(ctx context.Context, webhookId int64, options ...flespiapi.RequestOption) (Webhook, error) {
+	return GetWebhookWithContext(ctx, wc.c, webhookId, options...)
 }
 
 func (wc *WebhookClient) Update(webhook Webhook) (Webhook, error) {
 	return UpdateWebhook(wc.c, webhook)
 }
 
+func (wc *WebhookClient) UpdateWithContext(ctx context.Context, webhook Webhook) (Webhook, error) {
+	return UpdateWebhookWithContext(ctx, wc.c, webhook)
+}
+
 func (wc *WebhookClient) Delete(webhook Webhook) error {
 	return DeleteWebhook(wc.c, webhook)
 }
 
+func (wc *WebhookClient) DeleteWithContext(ctx context.Context, webhook Webhook) error {
+	return DeleteWebhookWithContext(ctx, wc.c, webhook)
+}
+
 func (wc *WebhookClient) DeleteById(webhookId int64) error {
 	return DeleteWebhookById(wc.c, webhookId)
 }
+
+func (wc *WebhookClient) DeleteByIdWithContext(ctx context.Context, webhookId int64) error {
+	return DeleteWebhookByIdWithContext(ctx, wc.c, webhookId)
+}
+
+func (wc *WebhookClient) UpdateBySelector(selector flespiapi.Selector, changes map[string]interface{}) (*WebhooksResult, error) {
+	return UpdateWebhooksBySelector(wc.c, selector, changes)
+}
+
+func (wc *WebhookClient) UpdateBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, changes map[string]interface{}) (*WebhooksResult, error) {
+	return UpdateWebhooksBySelectorWithContext(ctx, wc.c, selector, changes)
+}
+
+func (wc *WebhookClient) DeleteBySelector(selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteWebhooksBySelector(wc.c, selector)
+}
+
+func (wc *WebhookClient) DeleteBySelectorWithContext(ctx context.Context, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteWebhooksBySelectorWithContext(ctx, wc.c, selector)
+}
@@ -16,7 +16,11 @@
 //	)
 package flespi_webhook
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
 
 // Webhook validator action constants
 const (
@@ -209,5 +213,12 @@ func CWWithConfigurations(configurations []Configuration) CreateChainedWebhookOp
 type CreateChainedWebhookOption func(*ChainedWebhook)
 
 type webhookResponse struct {
-	RawValue []json.RawMessage `json:"result"`
+	RawValue []json.RawMessage       `json:"result"`
+	Errors   []flespiapi.ErrorDetail `json:"errors"`
 }
+
+// WebhooksResult holds the webhooks returned by a bulk request and the per-item errors reported by flespi.
+type WebhooksResult = flespiapi.MultiResult[Webhook]
+
+// WebhookIterator decodes webhooks from a list response one at a time, see IterateWebhooks.
+type WebhookIterator = flespiapi.Iterator[Webhook]
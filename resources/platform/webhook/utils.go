@@ -34,6 +34,11 @@ func unmarshalWebhook(rawValue json.RawMessage) (Webhook, error) {
 }
 
 func unmarshalConfiguration(rawValue json.RawMessage) (Configuration, error) {
+	// configuration is missing from responses limited with fields=
+	if len(rawValue) == 0 || string(rawValue) == "null" {
+		return nil, nil
+	}
+
 	var configurationType struct {
 		Type string `json:"type"`
 	}
@@ -1,11 +1,16 @@
 package flespi_cdn
 
 import (
+	"context"
 	"fmt"
 	"github.com/mixser/flespi-client/internal/flespiapi"
 )
 
 func NewCDN(client flespiapi.APIRequester, name string, opts ...CreateCDNOption) (*CDN, error) {
+	return NewCDNWithContext(context.Background(), client, name, opts...)
+}
+
+func NewCDNWithContext(ctx context.Context, client flespiapi.APIRequester, name string, opts ...CreateCDNOption) (*CDN, error) {
 	cdn := CDN{Name: name}
 
 	for _, opt := range opts {
@@ -14,60 +19,134 @@ func NewCDN(client flespiapi.APIRequester, name string, opts ...CreateCDNOption)
 
 	response := cdnsResponse{}
 
-	err := client.RequestAPI("POST", "storage/cdns", []CDN{cdn}, &response)
+	err := client.RequestAPIWithContext(ctx, "POST", "storage/cdns", []CDN{cdn}, &response)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.CDNS[0], nil
+	return flespiapi.First(response.CDNS, response.Errors)
 }
 
-func ListCDNs(client flespiapi.APIRequester) ([]CDN, error) {
+// NewCDNs creates several CDNs with a single request and returns every created
+// CDN along with the per-item errors reported by flespi.
+func NewCDNs(client flespiapi.APIRequester, cdns []CDN) (*CDNsResult, error) {
+	return NewCDNsWithContext(context.Background(), client, cdns)
+}
+
+func NewCDNsWithContext(ctx context.Context, client flespiapi.APIRequester, cdns []CDN) (*CDNsResult, error) {
+	result := &CDNsResult{}
+
+	if len(cdns) == 0 {
+		return result, nil
+	}
+
+	// Id is assigned by flespi; clear it on copies so the caller's slice is left alone
+	batch := make([]CDN, len(cdns))
+	copy(batch, cdns)
+	for i := range batch {
+		batch[i].Id = 0
+	}
+
 	response := cdnsResponse{}
 
-	err := client.RequestAPI("GET", "storage/cdns/all", nil, &response)
+	if err := client.RequestAPIWithContext(ctx, "POST", "storage/cdns", batch, &response); err != nil {
+		return result, err
+	}
+
+	result.Append(response.CDNS, response.Errors, func(cdn CDN) int64 { return cdn.Id })
+
+	return result, nil
+}
+
+func ListCDNs(client flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]CDN, error) {
+	return ListCDNsWithContext(context.Background(), client, options...)
+}
+
+func ListCDNsWithContext(ctx context.Context, client flespiapi.APIRequester, options ...flespiapi.RequestOption) ([]CDN, error) {
+	return ListCDNsBySelectorWithContext(ctx, client, flespiapi.SelectAll(), options...)
+}
 
+// ListCDNsBySelector returns the CDNs matched by selector.
+func ListCDNsBySelector(client flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]CDN, error) {
+	return ListCDNsBySelectorWithContext(context.Background(), client, selector, options...)
+}
+
+func ListCDNsBySelectorWithContext(ctx context.Context, client flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]CDN, error) {
+	path, err := selector.Path()
 	if err != nil {
 		return nil, err
 	}
 
+	response := cdnsResponse{}
+
+	if err := client.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("storage/cdns/%s", path), nil, options...), nil, &response); err != nil {
+		return nil, err
+	}
+
 	return response.CDNS, nil
 }
 
-func GetCDN(client flespiapi.APIRequester, cdnId int64) (*CDN, error) {
+// IterateCDNs walks the CDNs matched by selector one at a time.
+// Close the iterator when done.
+func IterateCDNs(client flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *CDNIterator {
+	return IterateCDNsWithContext(context.Background(), client, selector, options...)
+}
+
+func IterateCDNsWithContext(ctx context.Context, client flespiapi.APIRequester, selector flespiapi.Selector, options ...flespiapi.RequestOption) *CDNIterator {
+	path, err := selector.Path()
+	if err != nil {
+		return flespiapi.FailedIterator[CDN](err)
+	}
+
+	return flespiapi.NewIterator[CDN](ctx, client, flespiapi.Endpoint(fmt.Sprintf("storage/cdns/%s", path), nil, options...), nil)
+}
+
+func GetCDN(client flespiapi.APIRequester, cdnId int64, options ...flespiapi.RequestOption) (*CDN, error) {
+	return GetCDNWithContext(context.Background(), client, cdnId, options...)
+}
+
+func GetCDNWithContext(ctx context.Context, client flespiapi.APIRequester, cdnId int64, options ...flespiapi.RequestOption) (*CDN, error) {
 	response := cdnsResponse{}
 
-	err := client.RequestAPI("GET", fmt.Sprintf("storage/cdns/%d", cdnId), nil, &response)
+	err := client.RequestAPIWithContext(ctx, "GET", flespiapi.Endpoint(fmt.Sprintf("storage/cdns/%d", cdnId), nil, options...), nil, &response)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.CDNS[0], nil
+	return flespiapi.First(response.CDNS, response.Errors)
 }
 
 func UpdateCDN(client flespiapi.APIRequester, cdn CDN) (*CDN, error) {
+	return UpdateCDNWithContext(context.Background(), client, cdn)
+}
+
+func UpdateCDNWithContext(ctx context.Context, client flespiapi.APIRequester, cdn CDN) (*CDN, error) {
 	if cdn.Id == 0 {
-		return nil, fmt.Errorf("ID must be provided")
+		return nil, flespiapi.Invalid("ID must be provided")
 	}
 
 	response := cdnsResponse{}
-	err := client.RequestAPI("PUT", fmt.Sprintf("storage/cdns/%d", cdn.Id), cdn, &response)
+	err := client.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("storage/cdns/%d", cdn.Id), cdn, &response)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &response.CDNS[0], nil
+	return flespiapi.First(response.CDNS, response.Errors)
 }
 
 func DeleteCDN(client flespiapi.APIRequester, cdn CDN) error {
+	return DeleteCDNWithContext(context.Background(), client, cdn)
+}
+
+func DeleteCDNWithContext(ctx context.Context, client flespiapi.APIRequester, cdn CDN) error {
 	if cdn.Id == 0 {
-		return fmt.Errorf("ID must be provided")
+		return flespiapi.Invalid("ID must be provided")
 	}
 
-	err := DeleteCDNById(client, cdn.Id)
+	err := DeleteCDNByIdWithContext(ctx, client, cdn.Id)
 
 	if err != nil {
 		return err
@@ -77,7 +156,11 @@ func DeleteCDN(client flespiapi.APIRequester, cdn CDN) error {
 }
 
 func DeleteCDNById(client flespiapi.APIRequester, cdnId int64) error {
-	err := client.RequestAPI("DELETE", fmt.Sprintf("storage/cdns/%d", cdnId), nil, nil)
+	return DeleteCDNByIdWithContext(context.Background(), client, cdnId)
+}
+
+func DeleteCDNByIdWithContext(ctx context.Context, client flespiapi.APIRequester, cdnId int64) error {
+	err := client.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("storage/cdns/%d", cdnId), nil, nil)
 
 	if err != nil {
 		return err
@@ -85,3 +168,46 @@ func DeleteCDNById(client flespiapi.APIRequester, cdnId int64) error {
 
 	return nil
 }
+
+// UpdateCDNsBySelector updates the given fields of every CDN matched by selector.
+func UpdateCDNsBySelector(client flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*CDNsResult, error) {
+	return UpdateCDNsBySelectorWithContext(context.Background(), client, selector, changes)
+}
+
+func UpdateCDNsBySelectorWithContext(ctx context.Context, client flespiapi.APIRequester, selector flespiapi.Selector, changes map[string]interface{}) (*CDNsResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	response := cdnsResponse{}
+
+	if err := client.RequestAPIWithContext(ctx, "PUT", fmt.Sprintf("storage/cdns/%s", path), changes, &response); err != nil {
+		return nil, err
+	}
+
+	result := &CDNsResult{}
+	result.Append(response.CDNS, response.Errors, func(cdn CDN) int64 { return cdn.Id })
+
+	return result, nil
+}
+
+// DeleteCDNsBySelector deletes every CDN matched by selector.
+func DeleteCDNsBySelector(client flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteCDNsBySelectorWithContext(context.Background(), client, selector)
+}
+
+func DeleteCDNsBySelectorWithContext(ctx context.Context, client flespiapi.APIRequester, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	path, err := selector.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &flespiapi.DeleteResult{}
+
+	if err := client.RequestAPIWithContext(ctx, "DELETE", fmt.Sprintf("storage/cdns/%s", path), nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -1,10 +1,12 @@
 package flespi_cdn
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/mixser/flespi-client/internal/flespiapi"
 	"github.com/mixser/flespi-client/internal/testhelper"
 )
 
@@ -127,3 +129,23 @@ func TestDeleteCDN(t *testing.T) {
 		t.Errorf("DeleteCDNById() error = %v", err)
 	}
 }
+
+func TestNewCDNs(t *testing.T) {
+	testhelper.CheckBulkCreate(t, func(c flespiapi.APIRequester, ids []int64) (int, []int64, error) {
+		cdns := make([]CDN, len(ids))
+		for i, id := range ids {
+			cdns[i] = CDN{Id: id, Name: fmt.Sprintf("cdns-%d", i)}
+		}
+
+		result, err := NewCDNs(c, cdns)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		inputIds := make([]int64, len(cdns))
+		for i, item := range cdns {
+			inputIds[i] = item.Id
+		}
+		return len(result.Items), inputIds, nil
+	})
+}
@@ -1,9 +1,14 @@
 package flespi_cdn
 
-import "github.com/mixser/flespi-client/internal/flespiapi"
+import (
+	"context"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
 
 // CDNClient provides receiver-based methods for managing Flespi CDNs.
 // Access it via Client.CDNs after creating a flespi.Client.
+// Every method has a ...WithContext variant that takes a context.Context first.
 type CDNClient struct {
 	c flespiapi.APIRequester
 }
@@ -17,22 +22,86 @@ func (cc *CDNClient) Create(name string, options ...CreateCDNOption) (*CDN, erro
 	return NewCDN(cc.c, name, options...)
 }
 
-func (cc *CDNClient) List() ([]CDN, error) {
-	return ListCDNs(cc.c)
+func (cc *CDNClient) CreateWithContext(ctx context.Context, name string, options ...CreateCDNOption) (*CDN, error) {
+	return NewCDNWithContext(ctx, cc.c, name, options...)
+}
+
+func (cc *CDNClient) CreateMany(cdns []CDN) (*CDNsResult, error) {
+	return NewCDNs(cc.c, cdns)
+}
+
+func (cc *CDNClient) CreateManyWithContext(ctx context.Context, cdns []CDN) (*CDNsResult, error) {
+	return NewCDNsWithContext(ctx, cc.c, cdns)
+}
+
+func (cc *CDNClient) List(options ...flespiapi.RequestOption) ([]CDN, error) {
+	return ListCDNs(cc.c, options...)
+}
+
+func (cc *CDNClient) ListWithContext(ctx context.Context, options ...flespiapi.RequestOption) ([]CDN, error) {
+	return ListCDNsWithContext(ctx, cc.c, options...)
+}
+
+func (cc *CDNClient) ListBySelector(selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]CDN, error) {
+	return ListCDNsBySelector(cc.c, selector, options...)
+}
+
+func (cc *CDNClient) ListBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) ([]CDN, error) {
+	return ListCDNsBySelectorWithContext(ctx, cc.c, selector, options...)
+}
+
+func (cc *CDNClient) Iterate(selector flespiapi.Selector, options ...flespiapi.RequestOption) *CDNIterator {
+	return IterateCDNs(cc.c, selector, options...)
+}
+
+func (cc *CDNClient) IterateWithContext(ctx context.Context, selector flespiapi.Selector, options ...flespiapi.RequestOption) *CDNIterator {
+	return IterateCDNsWithContext(ctx, cc.c, selector, options...)
 }
 
-func (cc *CDNClient) Get(cdnId int64) (*CDN, error) {
-	return GetCDN(cc.c, cdnId)
+func (cc *CDNClient) Get(cdnId int64, options ...flespiapi.RequestOption) (*CDN, error) {
+	return GetCDN(cc.c, cdnId, options...)
+}
+
+func (cc *CDNClient) GetWithContext(ctx context.Context, cdnId int64, options ...flespiapi.RequestOption) (*CDN, error) {
+	return GetCDNWithContext(ctx, cc.c, cdnId, options...)
 }
 
 func (cc *CDNClient) Update(cdn CDN) (*CDN, error) {
 	return UpdateCDN(cc.c, cdn)
 }
 
+func (cc *CDNClient) UpdateWithContext(ctx context.Context, cdn CDN) (*CDN, error) {
+	return UpdateCDNWithContext(ctx, cc.c, cdn)
+}
+
 func (cc *CDNClient) Delete(cdn CDN) error {
 	return DeleteCDN(cc.c, cdn)
 }
 
+func (cc *CDNClient) DeleteWithContext(ctx context.Context, cdn CDN) error {
+	return DeleteCDNWithContext(ctx, cc.c, cdn)
+}
+
 func (cc *CDNClient) DeleteById(cdnId int64) error {
 	return DeleteCDNById(cc.c, cdnId)
 }
+
+func (cc *CDNClient) DeleteByIdWithContext(ctx context.Context, cdnId int64) error {
+	return DeleteCDNByIdWithContext(ctx, cc.c, cdnId)
+}
+
+func (cc *CDNClient) UpdateBySelector(selector flespiapi.Selector, changes map[string]interface{}) (*CDNsResult, error) {
+	return UpdateCDNsBySelector(cc.c, selector, changes)
+}
+
+func (cc *CDNClient) UpdateBySelectorWithContext(ctx context.Context, selector flespiapi.Selector, changes map[string]interface{}) (*CDNsResult, error) {
+	return UpdateCDNsBySelectorWithContext(ctx, cc.c, selector, changes)
+}
+
+func (cc *CDNClient) DeleteBySelector(selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteCDNsBySelector(cc.c, selector)
+}
+
+func (cc *CDNClient) DeleteBySelectorWithContext(ctx context.Context, selector flespiapi.Selector) (*flespiapi.DeleteResult, error) {
+	return DeleteCDNsBySelectorWithContext(ctx, cc.c, selector)
+}
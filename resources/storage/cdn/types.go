@@ -1,5 +1,7 @@
 package flespi_cdn
 
+import "github.com/mixser/flespi-client/internal/flespiapi"
+
 type CDN struct {
 	Id      int64  `json:"id,omitempty"`
 	Name    string `json:"name"`
@@ -8,7 +10,14 @@ type CDN struct {
 }
 
 type cdnsResponse struct {
-	CDNS []CDN `json:"result"`
+	CDNS   []CDN                   `json:"result"`
+	Errors []flespiapi.ErrorDetail `json:"errors"`
 }
 
+// CDNsResult holds the CDNs returned by a bulk request and the per-item errors reported by flespi.
+type CDNsResult = flespiapi.MultiResult[CDN]
+
+// CDNIterator decodes CDNs from a list response one at a time, see IterateCDNs.
+type CDNIterator = flespiapi.Iterator[CDN]
+
 type CreateCDNOption func(*CDN)
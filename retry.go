@@ -2,9 +2,12 @@ package flespi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -25,6 +28,50 @@ type RetryConfig struct {
 	// RetryableStatusCodes are HTTP status codes that should trigger a retry
 	// Default: 429 (Too Many Requests), 500, 502, 503, 504
 	RetryableStatusCodes map[int]bool
+
+	// RetryableMethods are the methods retried on any of RetryableStatusCodes.
+	// nil means the idempotent methods: GET, HEAD, OPTIONS, PUT and DELETE.
+	RetryableMethods map[string]bool
+
+	// NonIdempotentStatusCodes are the statuses on which the remaining methods (POST)
+	// are retried. They must guarantee that flespi did not process the request,
+	// otherwise a retry may create duplicates. nil means 429 only.
+	NonIdempotentStatusCodes map[int]bool
+
+	// Jitter randomises backoff so that clients failing together do not retry together
+	// (default in DefaultRetryConfig: JitterFull)
+	Jitter JitterMode
+
+	// MaxElapsedTime bounds the total time spent on a request including retries;
+	// no retry is started that would end its backoff past it. Zero means no limit.
+	MaxElapsedTime time.Duration
+}
+
+// JitterMode selects how backoff durations are randomised
+type JitterMode int
+
+const (
+	// JitterNone uses the exponential backoff as is
+	JitterNone JitterMode = iota
+	// JitterFull waits a random duration between 0 and the exponential backoff
+	JitterFull
+	// JitterDecorrelated waits a random duration between InitialBackoff and three
+	// times the previous wait, capped at MaxBackoff
+	JitterDecorrelated
+)
+
+// idempotentMethods are retried on every retryable status unless RetryableMethods is set
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// nonIdempotentStatusCodes are safe to retry for any method unless NonIdempotentStatusCodes is set
+var nonIdempotentStatusCodes = map[int]bool{
+	http.StatusTooManyRequests: true,
 }
 
 // DefaultRetryConfig returns a retry configuration with sensible defaults
@@ -41,6 +88,7 @@ func DefaultRetryConfig() *RetryConfig {
 			http.StatusServiceUnavailable:  true, // 503
 			http.StatusGatewayTimeout:      true, // 504
 		},
+		Jitter: JitterFull,
 	}
 }
 
@@ -57,13 +105,37 @@ func (rc *RetryConfig) shouldRetry(err error) bool {
 		return false
 	}
 
-	if apiErr, ok := err.(*APIError); ok {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
 		return rc.RetryableStatusCodes[apiErr.StatusCode]
 	}
 
 	return false
 }
 
+// shouldRetryMethod applies the per-method policy on top of shouldRetry
+func (rc *RetryConfig) shouldRetryMethod(method string, err error) bool {
+	if !rc.shouldRetry(err) {
+		return false
+	}
+
+	methods := rc.RetryableMethods
+	if methods == nil {
+		methods = idempotentMethods
+	}
+	if methods[method] {
+		return true
+	}
+
+	statusCodes := rc.NonIdempotentStatusCodes
+	if statusCodes == nil {
+		statusCodes = nonIdempotentStatusCodes
+	}
+
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && statusCodes[apiErr.StatusCode]
+}
+
 // calculateBackoff calculates the backoff duration for a given attempt
 func (rc *RetryConfig) calculateBackoff(attempt int) time.Duration {
 	if rc == nil {
@@ -78,49 +150,135 @@ func (rc *RetryConfig) calculateBackoff(attempt int) time.Duration {
 	return time.Duration(backoff)
 }
 
-// doRequestWithRetry performs an HTTP request with retry logic
-func (c *Client) doRequestWithRetry(ctx context.Context, req *http.Request, method, endpoint string) ([]byte, error) {
+// nextBackoff applies the jitter mode to the backoff of attempt; previous is the
+// wait before the previous attempt (0 for the first retry)
+func (rc *RetryConfig) nextBackoff(attempt int, previous time.Duration) time.Duration {
+	switch rc.Jitter {
+	case JitterFull:
+		return randomDuration(0, rc.calculateBackoff(attempt))
+	case JitterDecorrelated:
+		upper := previous * 3
+		if upper < rc.InitialBackoff {
+			upper = rc.InitialBackoff
+		}
+		backoff := randomDuration(rc.InitialBackoff, upper)
+		if rc.MaxBackoff > 0 && backoff > rc.MaxBackoff {
+			backoff = rc.MaxBackoff
+		}
+		return backoff
+	default:
+		return rc.calculateBackoff(attempt)
+	}
+}
+
+// randomDuration returns a random duration in [lower, upper]
+func randomDuration(lower, upper time.Duration) time.Duration {
+	if upper <= lower {
+		return lower
+	}
+	return lower + time.Duration(rand.Int63n(int64(upper-lower)+1))
+}
+
+// doWithRetry runs attempt until it succeeds or the retry policy gives up; every
+// attempt gets its own clone of req with a fresh body. Without a RetryConfig a
+// single attempt is made.
+func (c *Client) doWithRetry(ctx context.Context, req *http.Request, method, endpoint string, attempt func(req *http.Request, attempt int) (int, error)) (int, error) {
 	var lastErr error
+	var lastStatus int
+	var backoff time.Duration
 	maxRetries := 0
 
 	if c.RetryConfig != nil {
 		maxRetries = c.RetryConfig.MaxRetries
 	}
 
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		// Clone the request for retry attempts
-		reqClone := req.Clone(ctx)
+	start := time.Now()
+
+	refreshed := false
+
+	// sent numbers the attempts for observers; it runs ahead of n once a refresh repeated one
+	sent := 0
+
+	for n := 0; n <= maxRetries; n++ {
+		reqClone, err := cloneRequest(ctx, req)
+		if err != nil {
+			return 0, err
+		}
+
+		statusCode, err := attempt(reqClone, sent)
+		sent++
+
+		// a rejected token is repeated once if the provider has a new one
+		if err != nil && !refreshed && errors.Is(err, ErrUnauthorized) {
+			rejected := strings.TrimPrefix(reqClone.Header.Get("Authorization"), "FlespiToken ")
+			if c.refreshCredentials(ctx, rejected) {
+				refreshed = true
+				if reqClone, err = cloneRequest(ctx, req); err != nil {
+					return 0, err
+				}
+				statusCode, err = attempt(reqClone, sent)
+				sent++
+			}
+		}
 
-		body, err := c.doRequest(reqClone, method, endpoint)
 		if err == nil {
-			if c.Logger != nil && attempt > 0 {
-				c.Logger.Infof("Request succeeded after %d retries: %s %s", attempt, method, endpoint)
+			if c.Logger != nil && n > 0 {
+				c.Logger.Infof("Request succeeded after %d retries: %s %s", n, method, endpoint)
 			}
-			return body, nil
+			return statusCode, nil
 		}
 
 		lastErr = err
+		lastStatus = statusCode
 
-		// Check if we should retry
-		if attempt < maxRetries && c.RetryConfig != nil && c.RetryConfig.shouldRetry(err) {
-			backoff := c.RetryConfig.calculateBackoff(attempt)
+		if n >= maxRetries || c.RetryConfig == nil || !c.RetryConfig.shouldRetryMethod(method, err) {
+			break
+		}
 
-			if c.Logger != nil {
-				c.Logger.Warnf("Request failed (attempt %d/%d), retrying in %v: %s %s - %v",
-					attempt+1, maxRetries+1, backoff, method, endpoint, err)
-			}
+		backoff = c.RetryConfig.nextBackoff(n, backoff)
 
-			// Wait for backoff duration or until context is cancelled
-			select {
-			case <-ctx.Done():
-				return nil, fmt.Errorf("request cancelled during retry backoff: %w", ctx.Err())
-			case <-time.After(backoff):
-				// Continue to next retry
-			}
-		} else {
+		// never come back earlier than flespi asked us to
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > backoff {
+			backoff = apiErr.RetryAfter
+		}
+
+		if budget := c.RetryConfig.MaxElapsedTime; budget > 0 && time.Since(start)+backoff > budget {
+			c.logWarn("Retry budget of %v exhausted after %d attempt(s): %s %s", budget, n+1, method, endpoint)
 			break
 		}
+
+		if c.Logger != nil {
+			c.Logger.Warnf("Request failed (attempt %d/%d), retrying in %v: %s %s - %v",
+				n+1, maxRetries+1, backoff, method, endpoint, err)
+		}
+
+		// Wait for backoff duration or until context is cancelled
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return lastStatus, fmt.Errorf("request cancelled during retry backoff: %w", ctx.Err())
+		case <-timer.C:
+			// Continue to next retry
+		}
+	}
+
+	return lastStatus, lastErr
+}
+
+// cloneRequest copies req for one attempt; every attempt needs a fresh body,
+// Clone shares the already consumed one
+func cloneRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	clone := req.Clone(ctx)
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
 	}
 
-	return nil, lastErr
+	return clone, nil
 }
@@ -2,6 +2,7 @@ package flespi
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"sync/atomic"
@@ -188,3 +189,141 @@ func TestClient_RetryWithContextCancellation(t *testing.T) {
 		t.Errorf("Expected 1-2 attempts before cancellation, got %d", attempts)
 	}
 }
+
+func TestRetryConfig_ShouldRetryMethod(t *testing.T) {
+	config := DefaultRetryConfig()
+
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		expected bool
+	}{
+		{"GET on 502", "GET", 502, true},
+		{"PUT on 503", "PUT", 503, true},
+		{"DELETE on 500", "DELETE", 500, true},
+		{"POST on 502", "POST", 502, false},
+		{"POST on 429", "POST", 429, true},
+		{"POST on 404", "POST", 404, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := config.shouldRetryMethod(tt.method, &APIError{StatusCode: tt.status})
+			if result != tt.expected {
+				t.Errorf("shouldRetryMethod(%s, %d) = %v, want %v", tt.method, tt.status, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRetryConfig_NextBackoffJitter(t *testing.T) {
+	config := &RetryConfig{
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        time.Second,
+		BackoffMultiplier: 2.0,
+	}
+
+	config.Jitter = JitterFull
+	for i := 0; i < 100; i++ {
+		if backoff := config.nextBackoff(2, 0); backoff < 0 || backoff > 400*time.Millisecond {
+			t.Fatalf("Full jitter backoff %v outside [0, 400ms]", backoff)
+		}
+	}
+
+	config.Jitter = JitterDecorrelated
+	previous := time.Duration(0)
+	for i := 0; i < 100; i++ {
+		backoff := config.nextBackoff(i, previous)
+		if backoff < config.InitialBackoff || backoff > config.MaxBackoff {
+			t.Fatalf("Decorrelated backoff %v outside [%v, %v]", backoff, config.InitialBackoff, config.MaxBackoff)
+		}
+		previous = backoff
+	}
+
+	config.Jitter = JitterNone
+	if backoff := config.nextBackoff(1, 0); backoff != 200*time.Millisecond {
+		t.Errorf("Expected 200ms without jitter, got %v", backoff)
+	}
+}
+
+func TestClient_NoRetryForPostOnServerError(t *testing.T) {
+	attempts := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	retryConfig := DefaultRetryConfig()
+	retryConfig.InitialBackoff = time.Millisecond
+	client, _ := NewClient(server.URL, "test-token", WithRetryConfig(retryConfig))
+
+	client.RequestAPI("POST", "gw/devices", []map[string]string{{"name": "truck"}}, nil)
+
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt for POST on 502, got %d", attempts)
+	}
+}
+
+func TestClient_RetryReplaysBody(t *testing.T) {
+	attempts := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `[{"name":"truck"}]` {
+			t.Errorf("Attempt %d: expected the full body, got %q", atomic.LoadInt32(&attempts)+1, body)
+		}
+
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"result": []}`))
+	}))
+	defer server.Close()
+
+	retryConfig := DefaultRetryConfig()
+	retryConfig.InitialBackoff = time.Millisecond
+	client, _ := NewClient(server.URL, "test-token", WithRetryConfig(retryConfig))
+
+	if err := client.RequestAPI("POST", "gw/devices", []map[string]string{{"name": "truck"}}, nil); err != nil {
+		t.Fatalf("Expected request to succeed after retry, got error: %v", err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestClient_RetryMaxElapsedTime(t *testing.T) {
+	attempts := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	retryConfig := &RetryConfig{
+		MaxRetries:           10,
+		InitialBackoff:       40 * time.Millisecond,
+		MaxBackoff:           40 * time.Millisecond,
+		BackoffMultiplier:    1,
+		RetryableStatusCodes: map[int]bool{http.StatusServiceUnavailable: true},
+		MaxElapsedTime:       100 * time.Millisecond,
+	}
+
+	client, _ := NewClient(server.URL, "test-token", WithRetryConfig(retryConfig))
+
+	start := time.Now()
+	err := client.RequestAPI("GET", "test/endpoint", nil, nil)
+
+	if err == nil {
+		t.Error("Expected error, got nil")
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Expected the retry budget to stop retries, took %v", elapsed)
+	}
+	if attempts < 2 || attempts > 3 {
+		t.Errorf("Expected 2-3 attempts within the budget, got %d", attempts)
+	}
+}
@@ -0,0 +1,50 @@
+package flespi
+
+import "github.com/mixser/flespi-client/internal/flespiapi"
+
+// Selector identifies a set of flespi items, see the List*BySelector functions
+// of the resource packages.
+type Selector = flespiapi.Selector
+
+// Condition is a single field comparison used by SelectWhere.
+type Condition = flespiapi.Condition
+
+// SelectAll selects every item of a collection.
+func SelectAll() Selector {
+	return flespiapi.SelectAll()
+}
+
+// SelectIds selects items by their numeric ids, e.g. gw/devices/1,5,9.
+func SelectIds(ids ...int64) Selector {
+	return flespiapi.SelectIds(ids...)
+}
+
+// SelectExpr selects items with a raw flespi expression, e.g. "name=truck*".
+func SelectExpr(expr string) Selector {
+	return flespiapi.SelectExpr(expr)
+}
+
+// SelectWhere builds an expression selector from conditions; all of them must match.
+//
+// Example:
+//
+//	sel := flespi.SelectWhere(flespi.Like("name", "truck*"), flespi.Eq("metadata.fleet", "north"))
+//	devices, err := client.Devices.ListBySelector(sel)
+func SelectWhere(conditions ...Condition) Selector {
+	return flespiapi.SelectWhere(conditions...)
+}
+
+// Eq matches items whose field equals value.
+func Eq(field string, value interface{}) Condition {
+	return flespiapi.Eq(field, value)
+}
+
+// NotEq matches items whose field differs from value.
+func NotEq(field string, value interface{}) Condition {
+	return flespiapi.NotEq(field, value)
+}
+
+// Like matches items whose field matches a pattern with * wildcards.
+func Like(field string, pattern string) Condition {
+	return flespiapi.Like(field, pattern)
+}
@@ -0,0 +1,105 @@
+package flespi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// SlogLogger adapts a *slog.Logger to the client. It implements Logger, so the
+// client's messages go through slog, and Observer, so every HTTP attempt becomes a
+// structured record with method, endpoint, status, attempt, duration and cid attributes.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// compile-time checks that *SlogLogger is both a Logger and an Observer
+var (
+	_ Logger   = (*SlogLogger)(nil)
+	_ Observer = (*SlogLogger)(nil)
+)
+
+// NewSlogLogger creates an adapter for logger; nil means slog.Default()
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogLogger{logger: logger.With(slog.String("component", "flespi"))}
+}
+
+// WithSlog routes the client's logs and per-attempt request records to logger.
+//
+// Example:
+//
+//	handler := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+//	client, err := flespi.NewClient("https://flespi.io", "your-token",
+//	    flespi.WithSlog(slog.New(handler)),
+//	)
+func WithSlog(logger *slog.Logger) ClientOption {
+	adapter := NewSlogLogger(logger)
+	return func(c *Client) {
+		WithLogger(adapter)(c)
+		WithObserver(adapter)(c)
+	}
+}
+
+func (l *SlogLogger) Debugf(format string, args ...interface{}) {
+	l.log(slog.LevelDebug, format, args...)
+}
+
+func (l *SlogLogger) Infof(format string, args ...interface{}) {
+	l.log(slog.LevelInfo, format, args...)
+}
+
+func (l *SlogLogger) Warnf(format string, args ...interface{}) {
+	l.log(slog.LevelWarn, format, args...)
+}
+
+func (l *SlogLogger) Errorf(format string, args ...interface{}) {
+	l.log(slog.LevelError, format, args...)
+}
+
+func (l *SlogLogger) log(level slog.Level, format string, args ...interface{}) {
+	ctx := context.Background()
+	// skip formatting for disabled levels, payload dumps can be large
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	l.logger.Log(ctx, level, fmt.Sprintf(format, args...))
+}
+
+// ObserveRequest implements Observer; successful attempts are logged at debug
+// level, failed ones at warn level.
+func (l *SlogLogger) ObserveRequest(ctx context.Context, event RequestEvent) {
+	level := slog.LevelDebug
+	if event.Err != nil {
+		level = slog.LevelWarn
+	}
+
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", event.Method),
+		slog.String("endpoint", event.Endpoint),
+		slog.Int("status", event.StatusCode),
+		slog.Int("attempt", event.Attempt),
+		slog.Duration("duration", event.Duration),
+		slog.Int64("request_bytes", event.RequestBytes),
+		slog.Int64("response_bytes", event.ResponseBytes),
+	}
+
+	if event.AccountId != 0 {
+		attrs = append(attrs, slog.Int64("cid", event.AccountId))
+	}
+
+	if event.Err != nil {
+		attrs = append(attrs,
+			slog.String("error_class", string(event.ErrorClass)),
+			slog.String("error", event.Err.Error()),
+		)
+	}
+
+	l.logger.LogAttrs(ctx, level, "flespi request", attrs...)
+}
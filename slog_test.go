@@ -0,0 +1,75 @@
+package flespi
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithSlog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[{"reason":"not found"}]}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client, _ := NewClient(server.URL, "secret-token", WithSlog(logger))
+
+	client.ForAccount(7).RequestAPI("GET", "gw/devices/42", nil, nil)
+
+	if strings.Contains(buf.String(), "secret-token") {
+		t.Errorf("Expected the token to be redacted, got %q", buf.String())
+	}
+
+	var record map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("Expected JSON log lines, got %q", line)
+		}
+		if r["msg"] == "flespi request" {
+			record = r
+		}
+	}
+
+	if record == nil {
+		t.Fatalf("Expected a structured request record, got %q", buf.String())
+	}
+
+	expected := map[string]interface{}{
+		"level":       "WARN",
+		"method":      "GET",
+		"endpoint":    "gw/devices/{id}",
+		"status":      float64(404),
+		"attempt":     float64(0),
+		"cid":         float64(7),
+		"error_class": "client_error",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, record[key])
+		}
+	}
+}
+
+func TestSlogLogger_RespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	logger.Debugf("hidden %d", 1)
+	logger.Infof("shown %d", 2)
+
+	if strings.Contains(buf.String(), "hidden") {
+		t.Error("Expected debug message to be filtered")
+	}
+	if !strings.Contains(buf.String(), "shown 2") {
+		t.Errorf("Expected info message, got %q", buf.String())
+	}
+}
@@ -0,0 +1,120 @@
+package flespi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"runtime"
+	"sync"
+
+	"github.com/mixser/flespi-client/internal/flespiapi"
+)
+
+// compile-time checks that *Client can stream responses to the resource packages
+var (
+	_ flespiapi.StreamRequester  = (*Client)(nil)
+	_ flespiapi.ResponseStreamer = (*Client)(nil)
+)
+
+// RequestAPIStream sends a request and returns the body of a successful response
+// unread; the caller must close it. Retries, rate limiting, the circuit breaker and
+// middlewares apply until the response headers arrive. The observer event of the
+// attempt is emitted when the body has been read to the end or is closed, whichever
+// comes first, so it covers the whole transfer. A body dropped without either is
+// reported and closed when it is garbage collected.
+//
+// Error responses are returned as *APIError like with RequestAPI.
+func (c *Client) RequestAPIStream(ctx context.Context, method, endpoint string, headers map[string]string, payload interface{}) (io.ReadCloser, error) {
+	res, err := c.RequestAPIResponse(ctx, method, endpoint, headers, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
+// RequestAPIResponse is RequestAPIStream that also returns the status code and
+// headers of the response, e.g. to tell 206 Partial Content from 200 OK.
+func (c *Client) RequestAPIResponse(ctx context.Context, method, endpoint string, headers map[string]string, payload interface{}) (*flespiapi.StreamResponse, error) {
+	req, err := c.newRequest(ctx, method, endpoint, headers, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var stream *flespiapi.StreamResponse
+	statusCode, err := c.doWithRetry(ctx, req, method, endpoint, func(req *http.Request, attempt int) (int, error) {
+		res, finish, statusCode, err := c.sendAttempt(req, method, endpoint, attempt)
+		if err != nil {
+			return statusCode, err
+		}
+
+		stream = &flespiapi.StreamResponse{
+			StatusCode: res.StatusCode,
+			Header:     res.Header,
+			Body:       newObservedBody(res.Body, finish),
+		}
+		return statusCode, nil
+	})
+
+	if err != nil {
+		c.logResponse(method, endpoint, statusCode, err)
+		return nil, err
+	}
+
+	c.logResponse(method, endpoint, statusCode, nil)
+
+	return stream, nil
+}
+
+// observedBody counts the bytes read from a streamed response and reports the
+// attempt once the body reaches its end, fails or is closed. Until then a
+// half-open circuit breaker keeps the attempt's probe slot.
+type observedBody struct {
+	body   io.ReadCloser
+	finish func(responseBytes int64, err error)
+	read   int64
+	err    error
+	closed bool
+	once   sync.Once
+}
+
+func newObservedBody(body io.ReadCloser, finish func(responseBytes int64, err error)) *observedBody {
+	b := &observedBody{body: body, finish: finish}
+
+	// an iterator abandoned half-way must not hold the probe forever
+	runtime.SetFinalizer(b, (*observedBody).Close)
+
+	return b
+}
+
+func (b *observedBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.read += int64(n)
+	if err != nil {
+		if err != io.EOF {
+			b.err = err
+		}
+		b.report()
+	}
+	return n, err
+}
+
+func (b *observedBody) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	runtime.SetFinalizer(b, nil)
+
+	err := b.body.Close()
+	b.report()
+
+	return err
+}
+
+// report finishes the attempt, once
+func (b *observedBody) report() {
+	b.once.Do(func() {
+		b.finish(b.read, b.err)
+	})
+}
@@ -0,0 +1,192 @@
+package flespi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_RequestAPIStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "FlespiToken test-token" {
+			t.Errorf("Expected Authorization header, got %q", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"result":[{"id":1}]}`))
+	}))
+	defer server.Close()
+
+	var events []RequestEvent
+	observer := ObserverFunc(func(ctx context.Context, event RequestEvent) {
+		events = append(events, event)
+	})
+
+	client, _ := NewClient(server.URL, "test-token", WithObserver(observer))
+
+	body, err := client.RequestAPIStream(context.Background(), "GET", "gw/devices/all", nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, _ := io.ReadAll(body)
+
+	// reading to the end reports the attempt, closing does not report it again
+	if len(events) != 1 {
+		t.Fatalf("Expected the attempt to be reported at the end of the body, got %d events", len(events))
+	}
+
+	body.Close()
+	body.Close()
+
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if events[0].ResponseBytes != int64(len(data)) || events[0].StatusCode != http.StatusOK {
+		t.Errorf("Unexpected event: %+v", events[0])
+	}
+}
+
+func TestClient_RequestAPIStream_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[{"reason":"not found"}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-token")
+
+	body, err := client.RequestAPIStream(context.Background(), "GET", "gw/devices/1", nil, nil)
+
+	if body != nil {
+		t.Error("Expected no body on error")
+	}
+	if !IsNotFoundError(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
+
+func TestClient_RequestAPIResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "bytes=4-" {
+			t.Errorf("Expected the Range header to be sent, got %q", r.Header.Get("Range"))
+		}
+		w.Header().Set("Content-Range", "bytes 4-9/10")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte("456789"))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-token")
+
+	res, err := client.RequestAPIResponse(context.Background(), "GET", "gw/devices/1/media/clip/data", map[string]string{"Range": "bytes=4-"}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer res.Body.Close()
+
+	data, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusPartialContent || res.Header.Get("Content-Range") != "bytes 4-9/10" || string(data) != "456789" {
+		t.Errorf("Expected the partial response with its headers, got %d %v %q", res.StatusCode, res.Header, data)
+	}
+}
+
+func TestClient_Iterate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":[{"id":1,"name":"a"},{"id":2,"name":"b"}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-token")
+
+	it := client.Devices.Iterate(SelectAll())
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+
+	if it.Err() != nil || count != 2 {
+		t.Errorf("Expected 2 devices and no error, got %d and %v", count, it.Err())
+	}
+}
+
+func TestClient_RequestAPIStream_ReportedOnClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":[{"id":1}]}`))
+	}))
+	defer server.Close()
+
+	var events []RequestEvent
+	client, _ := NewClient(server.URL, "test-token", WithObserver(ObserverFunc(func(ctx context.Context, event RequestEvent) {
+		events = append(events, event)
+	})))
+
+	body, err := client.RequestAPIStream(context.Background(), "GET", "gw/devices/all", nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	body.Read(make([]byte, 4))
+	if len(events) != 0 {
+		t.Error("Expected a partly read body not to be reported before Close")
+	}
+
+	body.Close()
+	if len(events) != 1 {
+		t.Errorf("Expected Close to report the attempt, got %d events", len(events))
+	}
+}
+
+func TestClient_AbandonedIteratorReleasesProbe(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+
+	// enough devices that the iterator stops long before the end of the body
+	page := `{"result":[` + strings.Repeat(`{"id":1,"name":"device"},`, 10000) + `{"id":2}]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL, "test-token",
+		WithRetryConfig(nil),
+		WithCircuitBreaker(&CircuitBreakerConfig{MinRequests: 1, OpenTimeout: 10 * time.Millisecond}),
+	)
+
+	client.RequestAPI("GET", "gw/devices/all", nil, nil)
+	if client.CircuitState() != CircuitOpen {
+		t.Fatalf("Expected the breaker to open, got %s", client.CircuitState())
+	}
+
+	failing.Store(false)
+	time.Sleep(20 * time.Millisecond)
+
+	// the probe: an iterator dropped after its first item, without Close
+	func() {
+		it := client.Devices.Iterate(SelectAll())
+		if !it.Next() {
+			t.Fatalf("Expected a device, got %v", it.Err())
+		}
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for client.CircuitState() != CircuitClosed && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if state := client.CircuitState(); state != CircuitClosed {
+		t.Errorf("Expected the abandoned probe to be reported and close the breaker, got %s", state)
+	}
+}
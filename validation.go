@@ -1,6 +1,7 @@
 package flespi
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -15,6 +16,11 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("validation error: %s - %s", e.Field, e.Message)
 }
 
+// Is lets errors.Is(err, ErrValidation) match a ValidationError
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
 // ValidateID checks if an ID is valid (greater than 0)
 func ValidateID(id int64, fieldName string) error {
 	if id <= 0 {
@@ -63,8 +69,8 @@ func ValidateHost(host string) error {
 	return ValidateURL(host, "host")
 }
 
-// IsValidationError checks if an error is a validation error
+// IsValidationError checks if an error is a validation error: a ValidationError,
+// an argument check of a resource package or a 400 response from flespi
 func IsValidationError(err error) bool {
-	_, ok := err.(*ValidationError)
-	return ok
+	return errors.Is(err, ErrValidation)
 }